@@ -30,6 +30,9 @@ const (
 	// VacuumPermeability is the permeability of vacuum (H/m)
 	VacuumPermeability = 1.25663706212e-6
 
+	// CoulombConstant is the Coulomb constant 1/(4πε0) (N⋅m²/C²)
+	CoulombConstant = 1.0 / (4.0 * Pi * VacuumPermittivity)
+
 	// AvogadroNumber is Avogadro's number (mol⁻¹)
 	AvogadroNumber = 6.02214076e23
 
@@ -45,12 +48,21 @@ const (
 	// SolarMass is the mass of the Sun (kg)
 	SolarMass = 1.989e30
 
+	// SolarLuminosity is the nominal luminosity of the Sun (W)
+	SolarLuminosity = 3.828e26
+
 	// EarthMass is the mass of the Earth (kg)
 	EarthMass = 5.972e24
 
 	// EarthRadius is the average radius of the Earth (m)
 	EarthRadius = 6.371e6
 
+	// EarthAngularVelocity is the sidereal rotation rate of the Earth (rad/s)
+	EarthAngularVelocity = 7.2921159e-5
+
+	// EarthMagneticMoment is the magnetic dipole moment of the Earth (A⋅m²)
+	EarthMagneticMoment = 8.0e22
+
 	// AstronomicalUnit is the astronomical unit (m)
 	AstronomicalUnit = 1.495978707e11
 
@@ -59,6 +71,9 @@ const (
 
 	// Parsec is the parsec (m)
 	Parsec = 3.0856775814671916e16
+
+	// CosmicBackgroundTemperature is the temperature of the cosmic microwave background (K)
+	CosmicBackgroundTemperature = 2.725
 )
 
 // Simulation constants
@@ -75,6 +90,12 @@ const (
 	// DefaultPressure is the default atmospheric pressure at sea level (Pa)
 	DefaultPressure = 101325.0
 
+	// SeaLevelTemperature is the temperature of the standard atmosphere at sea level (K)
+	SeaLevelTemperature = 288.15 // 15°C
+
+	// AirMolarMass is the mean molar mass of dry air (kg/mol)
+	AirMolarMass = 0.0289644
+
 	// Epsilon is a small value used for float equality comparisons
 	Epsilon = 1e-10
 )
@@ -32,6 +32,10 @@ const (
 	Acceleration
 	// Pressure represents a unit of pressure
 	Pressure
+	// Charge represents a unit of electric charge
+	Charge
+	// MagneticField represents a unit of magnetic flux density
+	MagneticField
 )
 
 // Unit represents a measurement unit
@@ -93,8 +97,13 @@ func (u *BaseUnit) ConvertTo(value float64, target Unit) float64 {
 	siValue := (value + u.offset) * u.factor
 
 	// Then convert from SI to target unit
-	targetUnit, ok := target.(*BaseUnit)
-	if !ok {
+	var targetUnit *BaseUnit
+	switch t := target.(type) {
+	case *BaseUnit:
+		targetUnit = t
+	case *DerivedUnit:
+		targetUnit = &t.BaseUnit
+	default:
 		panic("Target unit is not a BaseUnit")
 	}
 
@@ -248,6 +257,21 @@ var (
 		Kilometer: 1,
 		Hour:      -1,
 	})
+	// KilometerPerSecond is the kilometer per second
+	KilometerPerSecond = NewDerivedUnit(Velocity, "kilometer per second", "km/s", map[Unit]int{
+		Kilometer: 1,
+		Second:    -1,
+	})
+	// KilometerPerDay is the kilometer per day
+	KilometerPerDay = NewDerivedUnit(Velocity, "kilometer per day", "km/d", map[Unit]int{
+		Kilometer: 1,
+		Day:       -1,
+	})
+	// AstronomicalUnitPerDay is the astronomical unit per day
+	AstronomicalUnitPerDay = NewDerivedUnit(Velocity, "astronomical unit per day", "AU/d", map[Unit]int{
+		AstronomicalUnit: 1,
+		Day:              -1,
+	})
 )
 
 // Acceleration units
@@ -269,6 +293,24 @@ var (
 	})
 )
 
+// Charge units
+var (
+	// Coulomb is the coulomb (SI unit of electric charge)
+	Coulomb = NewBaseUnit(Charge, "coulomb", "C", 1.0, 0.0)
+	// ElementaryCharge is the charge of the proton
+	ElementaryCharge = NewBaseUnit(Charge, "elementary charge", "e", 1.602176634e-19, 0.0)
+)
+
+// Magnetic field units
+var (
+	// Tesla is the tesla (SI unit of magnetic flux density)
+	Tesla = NewBaseUnit(MagneticField, "tesla", "T", 1.0, 0.0)
+	// Gauss is the gauss
+	Gauss = NewBaseUnit(MagneticField, "gauss", "G", 1e-4, 0.0)
+	// Nanotesla is the nanotesla
+	Nanotesla = NewBaseUnit(MagneticField, "nanotesla", "nT", 1e-9, 0.0)
+)
+
 // Quantity represents a physical quantity with a value and a unit
 type Quantity struct {
 	value float64
@@ -351,6 +393,21 @@ func ConvertToStandardUnit(quantity Quantity) float64 {
 	case Temperature:
 		// Convert all temperatures to kelvin
 		return quantity.ConvertTo(Kelvin).Value()
+	case Charge:
+		// Convert all charges to coulombs
+		return quantity.ConvertTo(Coulomb).Value()
+	case MagneticField:
+		// Convert all magnetic fields to teslas
+		return quantity.ConvertTo(Tesla).Value()
+	case Time:
+		// Convert all times to seconds
+		return quantity.ConvertTo(Second).Value()
+	case Velocity:
+		// Convert all velocities to meters per second
+		return quantity.ConvertTo(MeterPerSecond).Value()
+	case Power:
+		// Convert all powers to watts
+		return quantity.ConvertTo(Watt).Value()
 	default:
 		// For other types, just return the value
 		return quantity.Value()
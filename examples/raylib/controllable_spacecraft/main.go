@@ -215,8 +215,9 @@ func createSpacecraft(w world.World) body.ControllableBody {
 	material := createMaterial("Spacecraft", 0.7, 0.5, [3]float64{1.0, 1.0, 1.0})
 
 	// Create a rigid body for the spacecraft
+	// 100 kg dry mass plus 400 kg of propellant
 	rb := body.NewRigidBody(
-		units.NewQuantity(100, units.Kilogram), // Ridotta la massa da 1000 a 100 kg per renderla più reattiva
+		units.NewQuantity(500, units.Kilogram),
 		units.NewQuantity(1.0, units.Meter),
 		vector.NewVector3(0, 0, 20), // Position near the sun
 		vector.Zero3(),              // Zero initial velocity
@@ -228,6 +229,13 @@ func createSpacecraft(w world.World) body.ControllableBody {
 	// per rendere più evidente il movimento della navicella
 	spacecraft := body.NewControllableRigidBody(rb, 50000.0, 1.0)
 
+	// The thrust of a (very efficient) engine burns the propellant until the tank is empty
+	spacecraft.SetEngine(body.NewEngine(
+		units.NewQuantity(250000, units.Newton),
+		units.NewQuantity(10000, units.Second),
+		units.NewQuantity(400, units.Kilogram),
+	))
+
 	// Add the spacecraft to the world
 	w.AddBody(spacecraft)
 	log.Printf("Spacecraft added: ID=%v, Position=%v", spacecraft.ID(), spacecraft.Position())
@@ -11,6 +11,7 @@ import (
 	"github.com/alexanderi96/go-space-engine/physics/body"
 	"github.com/alexanderi96/go-space-engine/physics/force"
 	physMaterial "github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
 	"github.com/alexanderi96/go-space-engine/render/raylib"
 	"github.com/alexanderi96/go-space-engine/simulation/config"
 	"github.com/alexanderi96/go-space-engine/simulation/world"
@@ -153,7 +154,7 @@ func createSolarSystem(w world.World) {
 			solarMass*massFractions[i], // Mass
 			radii[i],                   // Radius
 			distances[i],               // Distance
-			solarMass,                  // Central object mass (sun)
+			sun,                        // Central object (sun)
 			vector.NewVector3(0, 1, 0), // Orbital plane
 			colors[i],                  // Color
 		)
@@ -165,39 +166,22 @@ func createSolarSystem(w world.World) {
 }
 
 // createPlanet creates a planet
-func createPlanet(w world.World, name string, mass, radius, distance, centralMass float64, orbitPlane vector.Vector3, color [3]float64) body.Body {
-	// Calculate orbital velocity using the agnostic function from the force package
-	orbitSpeed := force.CalculateOrbitalVelocity(centralMass, distance)
-
-	log.Printf("Creating planet %s: distance=%f, radius=%f, calculated orbit speed=%f", name, distance, radius, orbitSpeed)
-
+func createPlanet(w world.World, name string, mass, radius, distance float64, central body.Body, orbitPlane vector.Vector3, color [3]float64) body.Body {
 	// Random angle for the initial position (to distribute planets around the sun)
 	angle := rand.Float64() * 2 * math.Pi
 
-	// Calculate the initial position
-	position := vector.NewVector3(
-		distance*math.Cos(angle),
-		0,
-		distance*math.Sin(angle),
-	)
-
-	// Calculate the orbital velocity (perpendicular to the position)
-	// This is the key for stable orbits: velocity must be perpendicular to the radius
-	velocity := vector.NewVector3(
-		-orbitSpeed*math.Sin(angle), // x component
-		0,                           // y component (xy plane)
-		orbitSpeed*math.Cos(angle),  // z component
-	)
-
-	// Create the planet
-	planet := body.NewRigidBody(
+	// Create the planet on a circular orbit in the XZ plane
+	// (inclined by 90° on the XY reference plane of the orbital elements)
+	planet := orbit.NewBodyOnOrbit(
+		central,
+		orbit.Circular(distance, math.Pi/2, 0, angle),
 		units.NewQuantity(mass, units.Kilogram),
 		units.NewQuantity(radius, units.Meter),
-		position,
-		velocity,
 		createMaterial(name, 0.7, 0.5, color),
 	)
 
+	log.Printf("Creating planet %s: distance=%f, radius=%f, orbit speed=%f", name, distance, radius, planet.Velocity().Length())
+
 	// Add the planet to the world
 	w.AddBody(planet)
 	log.Printf("Planet %s added: ID=%v, Position=%v, Velocity=%v", name, planet.ID(), planet.Position(), planet.Velocity())
@@ -2,6 +2,9 @@
 package body
 
 import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
 	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 	"github.com/google/uuid"
@@ -42,6 +45,18 @@ type Material interface {
 
 	// Elasticity returns the elasticity of the material
 	Elasticity() float64
+
+	// MeltingPoint returns the melting point of the material (zero if it does not melt)
+	MeltingPoint() units.Quantity
+
+	// LatentHeatOfFusion returns the heat per unit mass needed to melt the material
+	LatentHeatOfFusion() units.Quantity
+
+	// BoilingPoint returns the boiling point of the material (zero if it does not vaporize)
+	BoilingPoint() units.Quantity
+
+	// LatentHeatOfVaporization returns the heat per unit mass needed to vaporize the material
+	LatentHeatOfVaporization() units.Quantity
 }
 
 // Body represents a physical body in the engine
@@ -74,6 +89,11 @@ type Body interface {
 	// SetAngularVelocity sets the angular velocity of the body
 	SetAngularVelocity(angVel vector.Vector3)
 
+	// AngularAcceleration returns the angular acceleration of the body
+	AngularAcceleration() vector.Vector3
+	// SetAngularAcceleration sets the angular acceleration of the body
+	SetAngularAcceleration(angAcc vector.Vector3)
+
 	// Mass returns the mass of the body
 	Mass() units.Quantity
 	// SetMass sets the mass of the body
@@ -106,10 +126,35 @@ type Body interface {
 	// AddHeat adds heat to the body
 	AddHeat(heat units.Quantity)
 
+	// Charge returns the electric charge of the body
+	Charge() units.Quantity
+	// SetCharge sets the electric charge of the body
+	SetCharge(charge units.Quantity)
+
 	// IsStatic returns true if the body is static (does not move)
 	IsStatic() bool
 	// SetStatic sets whether the body is static
 	SetStatic(static bool)
+
+	// ProperTime returns the time elapsed on the clock carried by the body
+	ProperTime() units.Quantity
+	// AdvanceProperTime advances the clock of the body by a coordinate time interval,
+	// slowed down by the Lorentz factor of the body velocity
+	AdvanceProperTime(dt float64)
+
+	// Tags returns the tags (groups) of the body, e.g. "planet" or "debris"
+	Tags() []string
+	// HasTag returns true if the body has the tag
+	HasTag(tag string) bool
+	// AddTag adds a tag to the body
+	AddTag(tag string)
+	// RemoveTag removes a tag from the body
+	RemoveTag(tag string)
+
+	// CollisionFilter returns the collision category and mask of the body
+	CollisionFilter() CollisionFilter
+	// SetCollisionFilter sets the collision category and mask of the body
+	SetCollisionFilter(filter CollisionFilter)
 }
 
 // RigidBody implements a rigid body
@@ -125,7 +170,11 @@ type RigidBody struct {
 	radius       units.Quantity
 	material     Material
 	temperature  units.Quantity
+	charge       units.Quantity
 	isStatic     bool
+	properTime   float64
+	tags         []string
+	filter       CollisionFilter
 }
 
 // NewRigidBody creates a new rigid body
@@ -148,7 +197,9 @@ func NewRigidBody(
 		radius:       radius,
 		material:     mat,
 		temperature:  units.NewQuantity(293.15, units.Kelvin), // Room temperature (20°C)
+		charge:       units.NewQuantity(0.0, units.Coulomb),
 		isStatic:     false,
+		filter:       DefaultCollisionFilter(),
 	}
 }
 
@@ -334,6 +385,19 @@ func (rb *RigidBody) AddHeat(heat units.Quantity) {
 	rb.temperature = units.NewQuantity(newTemp, units.Kelvin)
 }
 
+// Charge returns the electric charge of the body
+func (rb *RigidBody) Charge() units.Quantity {
+	return rb.charge
+}
+
+// SetCharge sets the electric charge of the body
+func (rb *RigidBody) SetCharge(charge units.Quantity) {
+	if charge.Unit().Type() != units.Charge {
+		panic("Charge must be a charge quantity")
+	}
+	rb.charge = charge
+}
+
 // IsStatic returns true if the body is static (does not move)
 func (rb *RigidBody) IsStatic() bool {
 	return rb.isStatic
@@ -375,6 +439,21 @@ func (rb *RigidBody) SetAngularVelocity(angVel vector.Vector3) {
 	}
 }
 
+// AngularAcceleration returns the angular acceleration of the body
+func (rb *RigidBody) AngularAcceleration() vector.Vector3 {
+	return rb.angularAcc
+}
+
+// SetAngularAcceleration sets the angular acceleration of the body
+func (rb *RigidBody) SetAngularAcceleration(angAcc vector.Vector3) {
+	rb.angularAcc = angAcc
+
+	// If the body is static, angular acceleration must be zero
+	if rb.isStatic {
+		rb.angularAcc = vector.Zero3()
+	}
+}
+
 // ApplyTorque applies a torque to the body
 func (rb *RigidBody) ApplyTorque(torque vector.Vector3) {
 	if rb.isStatic {
@@ -385,3 +464,130 @@ func (rb *RigidBody) ApplyTorque(torque vector.Vector3) {
 	// In a more complex implementation, this would consider the moment of inertia
 	rb.angularAcc = rb.angularAcc.Add(torque)
 }
+
+// ProperTime returns the time elapsed on the clock carried by the body
+func (rb *RigidBody) ProperTime() units.Quantity {
+	return units.NewQuantity(rb.properTime, units.Second)
+}
+
+// AdvanceProperTime advances the clock of the body by a coordinate time interval,
+// slowed down by the Lorentz factor of the body velocity: dτ = dt / γ
+func (rb *RigidBody) AdvanceProperTime(dt float64) {
+	rb.properTime += dt / LorentzFactor(rb.velocity)
+}
+
+// Tags returns the tags (groups) of the body
+func (rb *RigidBody) Tags() []string {
+	return append([]string(nil), rb.tags...)
+}
+
+// HasTag returns true if the body has the tag
+func (rb *RigidBody) HasTag(tag string) bool {
+	for _, t := range rb.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag adds a tag to the body
+func (rb *RigidBody) AddTag(tag string) {
+	if !rb.HasTag(tag) {
+		rb.tags = append(rb.tags, tag)
+	}
+}
+
+// RemoveTag removes a tag from the body
+func (rb *RigidBody) RemoveTag(tag string) {
+	for i, t := range rb.tags {
+		if t == tag {
+			rb.tags = append(rb.tags[:i], rb.tags[i+1:]...)
+			return
+		}
+	}
+}
+
+// CollisionFilter returns the collision category and mask of the body
+func (rb *RigidBody) CollisionFilter() CollisionFilter {
+	return rb.filter
+}
+
+// SetCollisionFilter sets the collision category and mask of the body
+func (rb *RigidBody) SetCollisionFilter(filter CollisionFilter) {
+	rb.filter = filter
+}
+
+// Rotate rotates a vector by a rotation vector (axis times angle, as stored by Rotation) using Rodrigues' formula
+func Rotate(v, rotation vector.Vector3) vector.Vector3 {
+	angle := rotation.Length()
+	if angle < 1e-12 {
+		return v
+	}
+	axis := rotation.Scale(1.0 / angle)
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return v.Scale(cos).Add(axis.Cross(v).Scale(sin)).Add(axis.Scale(axis.Dot(v) * (1 - cos)))
+}
+
+// ComposeRotations returns the rotation vector of the rotation first followed by the rotation second
+// (both about the world axes). Rotation vectors do not add: the rotations are composed as quaternions.
+func ComposeRotations(first, second vector.Vector3) vector.Vector3 {
+	w1, v1 := quaternion(first)
+	w2, v2 := quaternion(second)
+	w := w2*w1 - v2.Dot(v1)
+	v := v1.Scale(w2).Add(v2.Scale(w1)).Add(v2.Cross(v1))
+
+	// Take the shortest rotation, with an angle between 0 and π
+	if w < 0 {
+		w, v = -w, v.Scale(-1)
+	}
+	sin := v.Length()
+	if sin < 1e-15 {
+		return v.Scale(2)
+	}
+	return v.Scale(2 * math.Atan2(sin, w) / sin)
+}
+
+// quaternion returns the scalar and vector parts of the unit quaternion of a rotation vector
+func quaternion(rotation vector.Vector3) (float64, vector.Vector3) {
+	angle := rotation.Length()
+	if angle < 1e-12 {
+		return 1, rotation.Scale(0.5)
+	}
+	return math.Cos(angle / 2), rotation.Scale(math.Sin(angle/2) / angle)
+}
+
+// LorentzFactor returns the Lorentz factor γ = 1 / sqrt(1 - v²/c²) of a velocity
+// (+Inf if the speed is not lower than the speed of light)
+func LorentzFactor(velocity vector.Vector3) float64 {
+	beta2 := velocity.LengthSquared() / (constants.SpeedOfLight * constants.SpeedOfLight)
+	if beta2 >= 1 {
+		return math.Inf(1)
+	}
+	return 1.0 / math.Sqrt(1-beta2)
+}
+
+// maxSpeedFraction is the highest fraction of the speed of light accepted for a velocity
+const maxSpeedFraction = 1 - 1e-12
+
+// ProperVelocity returns the momentum per unit of rest mass u = γv of a velocity.
+// Velocities not lower than the speed of light are clamped just below it.
+func ProperVelocity(velocity vector.Vector3) vector.Vector3 {
+	speed := velocity.Length()
+	if speed >= maxSpeedFraction*constants.SpeedOfLight {
+		velocity = velocity.Scale(maxSpeedFraction * constants.SpeedOfLight / speed)
+	}
+	return velocity.Scale(LorentzFactor(velocity))
+}
+
+// VelocityFromProperVelocity returns the velocity v = u / sqrt(1 + u²/c²) of a momentum
+// per unit of rest mass; its speed is always lower than the speed of light
+func VelocityFromProperVelocity(properVelocity vector.Vector3) vector.Vector3 {
+	u2 := properVelocity.LengthSquared() / (constants.SpeedOfLight * constants.SpeedOfLight)
+	return properVelocity.Scale(1.0 / math.Sqrt(1+u2))
+}
+
+// RelativisticMomentum returns the momentum p = γmv of a body
+func RelativisticMomentum(b Body) vector.Vector3 {
+	return ProperVelocity(b.Velocity()).Scale(units.ConvertToStandardUnit(b.Mass()))
+}
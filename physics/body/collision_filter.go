@@ -0,0 +1,32 @@
+package body
+
+// Collision categories
+const (
+	// DefaultCollisionCategory is the category of the bodies that were not assigned one
+	DefaultCollisionCategory uint32 = 1
+	// AllCollisionCategories is a mask that collides with every category
+	AllCollisionCategories uint32 = 0xFFFFFFFF
+)
+
+// CollisionFilter selects which bodies can collide with each other.
+// Each body belongs to one or more categories (bits) and collides only with the categories in its mask:
+// two bodies collide if each one's mask contains a category of the other.
+// For example, debris that ignore each other but collide with planets use a debris category
+// and a mask without it.
+type CollisionFilter struct {
+	Category uint32 // Categories the body belongs to
+	Mask     uint32 // Categories the body collides with
+}
+
+// DefaultCollisionFilter returns the filter of a body in the default category that collides with everything
+func DefaultCollisionFilter() CollisionFilter {
+	return CollisionFilter{
+		Category: DefaultCollisionCategory,
+		Mask:     AllCollisionCategories,
+	}
+}
+
+// CanCollide returns true if bodies with the two filters can collide
+func (f CollisionFilter) CanCollide(other CollisionFilter) bool {
+	return f.Mask&other.Category != 0 && other.Mask&f.Category != 0
+}
@@ -0,0 +1,287 @@
+package body
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+// Part is a spherical part of a compound body (e.g. a stage or a tank of a spacecraft)
+type Part struct {
+	Name     string         // Name used to find and detach the part
+	Mass     units.Quantity // Mass of the part
+	Radius   units.Quantity // Radius of the part
+	Offset   vector.Vector3 // Position of the part in the reference frame of the body
+	Material Material       // Material of the part
+}
+
+// Sphere is a sphere in world coordinates
+type Sphere struct {
+	Center vector.Vector3
+	Radius float64
+}
+
+// CompoundShape is implemented by bodies whose collision shape is made of several spheres.
+// Radius returns the radius of a sphere enclosing all of them.
+type CompoundShape interface {
+	Body
+	// CollisionSpheres returns the spheres of the parts in world coordinates
+	CollisionSpheres() []Sphere
+}
+
+// RotationalInertia is implemented by bodies whose rotation responds to torques through an inertia tensor
+type RotationalInertia interface {
+	Body
+	// AngularResponse returns the angular acceleration caused by a torque, or the change of angular velocity
+	// caused by an angular impulse: I⁻¹ τ, with the inertia tensor in world coordinates
+	AngularResponse(torque vector.Vector3) vector.Vector3
+	// ApplyAngularImpulse changes the angular velocity of the body by I⁻¹ L
+	ApplyAngularImpulse(impulse vector.Vector3)
+}
+
+// CompoundBody implements a rigid body assembled from several spherical parts.
+// Its mass, center of mass (the position of the body), bounding radius and inertia tensor are computed
+// from the parts, and are updated when parts are added or detached.
+type CompoundBody struct {
+	*RigidBody
+	parts   []Part         // Parts with their offsets from the center of mass
+	inertia [3][3]float64  // Inertia tensor about the center of mass, in the reference frame of the body (kg⋅m²)
+	torque  vector.Vector3 // Torque applied since the last integration, in world coordinates (N⋅m)
+}
+
+// NewCompoundBody creates a new compound body from its parts.
+// The offsets of the parts are relative to position, the center of mass of the body is computed from them.
+func NewCompoundBody(position, velocity vector.Vector3, parts ...Part) *CompoundBody {
+	if len(parts) == 0 {
+		panic("Compound body requires at least one part")
+	}
+	for _, part := range parts {
+		validatePart(part)
+	}
+
+	cb := &CompoundBody{
+		RigidBody: NewRigidBody(units.NewQuantity(0, units.Kilogram), units.NewQuantity(0, units.Meter), position, velocity, parts[0].Material),
+		parts:     append([]Part(nil), parts...),
+		torque:    vector.Zero3(),
+	}
+	cb.updateMassProperties()
+	return cb
+}
+
+// validatePart checks the units of a part
+func validatePart(part Part) {
+	if part.Mass.Unit().Type() != units.Mass {
+		panic("Part mass must be a mass quantity")
+	}
+	if part.Radius.Unit().Type() != units.Length {
+		panic("Part radius must be a length quantity")
+	}
+}
+
+// Parts returns the parts of the body, with their offsets from the center of mass
+func (cb *CompoundBody) Parts() []Part {
+	return append([]Part(nil), cb.parts...)
+}
+
+// Part returns the part with the given name
+func (cb *CompoundBody) Part(name string) (Part, bool) {
+	for _, part := range cb.parts {
+		if part.Name == name {
+			return part, true
+		}
+	}
+	return Part{}, false
+}
+
+// PartPosition returns the position of a part in world coordinates
+func (cb *CompoundBody) PartPosition(part Part) vector.Vector3 {
+	return cb.position.Add(Rotate(part.Offset, cb.rotation))
+}
+
+// AddPart adds a part to the body. The offset of the part is relative to the current center of mass.
+func (cb *CompoundBody) AddPart(part Part) {
+	validatePart(part)
+	cb.parts = append(cb.parts, part)
+	cb.updateMassProperties()
+}
+
+// Detach removes a part from the body and returns it as an independent body, e.g. for a stage separation.
+// The part keeps the velocity it had as a point of the rotating body, so the total momentum is conserved.
+// It returns nil if there is no part with that name or if it is the last part of the body.
+func (cb *CompoundBody) Detach(name string) *RigidBody {
+	index := -1
+	for i, part := range cb.parts {
+		if part.Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 || len(cb.parts) == 1 {
+		return nil
+	}
+
+	part := cb.parts[index]
+	arm := Rotate(part.Offset, cb.rotation)
+	detached := NewRigidBody(
+		part.Mass,
+		part.Radius,
+		cb.position.Add(arm),
+		cb.velocity.Add(cb.angularVel.Cross(arm)),
+		part.Material,
+	)
+	detached.rotation = cb.rotation
+	detached.angularVel = cb.angularVel
+	detached.temperature = cb.temperature
+	detached.filter = cb.filter
+
+	cb.parts = append(cb.parts[:index], cb.parts[index+1:]...)
+	cb.updateMassProperties()
+	return detached
+}
+
+// InertiaTensor returns the inertia tensor about the center of mass, in the reference frame of the body
+func (cb *CompoundBody) InertiaTensor() [3][3]float64 {
+	return cb.inertia
+}
+
+// CollisionSpheres returns the spheres of the parts in world coordinates
+func (cb *CompoundBody) CollisionSpheres() []Sphere {
+	spheres := make([]Sphere, len(cb.parts))
+	for i, part := range cb.parts {
+		spheres[i] = Sphere{
+			Center: cb.PartPosition(part),
+			Radius: units.ConvertToStandardUnit(part.Radius),
+		}
+	}
+	return spheres
+}
+
+// ApplyTorque applies a torque to the body, accumulated until the next integration of its rotation
+func (cb *CompoundBody) ApplyTorque(torque vector.Vector3) {
+	if cb.isStatic {
+		return
+	}
+	cb.torque = cb.torque.Add(torque)
+}
+
+// AngularAcceleration returns the angular acceleration caused by the accumulated torque: α = I⁻¹ τ,
+// with the inertia tensor in world coordinates
+func (cb *CompoundBody) AngularAcceleration() vector.Vector3 {
+	if cb.isStatic {
+		return vector.Zero3()
+	}
+	return cb.AngularResponse(cb.torque)
+}
+
+// SetAngularAcceleration sets the angular acceleration of the body, replacing the accumulated torque with τ = I α
+// (zero resets it)
+func (cb *CompoundBody) SetAngularAcceleration(angAcc vector.Vector3) {
+	if cb.isStatic {
+		cb.torque = vector.Zero3()
+		return
+	}
+	cb.torque = Rotate(multiply3(cb.inertia, Rotate(angAcc, cb.rotation.Scale(-1))), cb.rotation)
+}
+
+// AngularResponse returns I⁻¹ τ, with the inertia tensor in world coordinates: the angular acceleration caused by
+// a torque, or the change of angular velocity caused by an angular impulse (zero if the tensor is singular)
+func (cb *CompoundBody) AngularResponse(torque vector.Vector3) vector.Vector3 {
+	inverse, ok := invert3(cb.inertia)
+	if !ok {
+		return vector.Zero3()
+	}
+
+	// The inertia tensor is expressed in the reference frame of the body: I⁻¹ = R I_body⁻¹ Rᵀ
+	local := Rotate(torque, cb.rotation.Scale(-1))
+	return Rotate(multiply3(inverse, local), cb.rotation)
+}
+
+// ApplyAngularImpulse changes the angular velocity of the body by I⁻¹ L
+func (cb *CompoundBody) ApplyAngularImpulse(impulse vector.Vector3) {
+	if cb.isStatic {
+		return
+	}
+	cb.angularVel = cb.angularVel.Add(cb.AngularResponse(impulse))
+}
+
+// updateMassProperties recomputes the mass, the center of mass, the bounding radius, the inertia tensor
+// and the material (that of the heaviest part) of the body from its parts
+func (cb *CompoundBody) updateMassProperties() {
+	totalMass := 0.0
+	weightedOffset := vector.Zero3()
+	heaviest := 0.0
+	for _, part := range cb.parts {
+		mass := units.ConvertToStandardUnit(part.Mass)
+		totalMass += mass
+		weightedOffset = weightedOffset.Add(part.Offset.Scale(mass))
+		if mass > heaviest {
+			heaviest = mass
+			cb.material = part.Material
+		}
+	}
+
+	// Move the origin of the body to the center of mass
+	centerOfMass := vector.Zero3()
+	if totalMass > 0 {
+		centerOfMass = weightedOffset.Scale(1.0 / totalMass)
+	}
+	cb.position = cb.position.Add(Rotate(centerOfMass, cb.rotation))
+	cb.velocity = cb.velocity.Add(cb.angularVel.Cross(Rotate(centerOfMass, cb.rotation)))
+
+	radius := 0.0
+	var inertia [3][3]float64
+	for i := range cb.parts {
+		cb.parts[i].Offset = cb.parts[i].Offset.Sub(centerOfMass)
+		offset := cb.parts[i].Offset
+		mass := units.ConvertToStandardUnit(cb.parts[i].Mass)
+		partRadius := units.ConvertToStandardUnit(cb.parts[i].Radius)
+		radius = math.Max(radius, offset.Length()+partRadius)
+
+		// Solid sphere about its center plus the parallel axis theorem: I = 2/5 m r² E + m (|d|² E - d dᵀ)
+		d := offset.ToArray()
+		sphere := 0.4 * mass * partRadius * partRadius
+		distanceSquared := offset.LengthSquared()
+		for row := 0; row < 3; row++ {
+			for column := 0; column < 3; column++ {
+				inertia[row][column] -= mass * d[row] * d[column]
+			}
+			inertia[row][row] += sphere + mass*distanceSquared
+		}
+	}
+
+	cb.mass = units.NewQuantity(totalMass, units.Kilogram)
+	cb.radius = units.NewQuantity(radius, units.Meter)
+	cb.inertia = inertia
+}
+
+// multiply3 returns the product of a 3x3 matrix and a vector
+func multiply3(m [3][3]float64, v vector.Vector3) vector.Vector3 {
+	return vector.NewVector3(
+		m[0][0]*v.X()+m[0][1]*v.Y()+m[0][2]*v.Z(),
+		m[1][0]*v.X()+m[1][1]*v.Y()+m[1][2]*v.Z(),
+		m[2][0]*v.X()+m[2][1]*v.Y()+m[2][2]*v.Z(),
+	)
+}
+
+// invert3 returns the inverse of a 3x3 matrix (false if it is singular)
+func invert3(m [3][3]float64) ([3][3]float64, bool) {
+	var inverse [3][3]float64
+	determinant := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if math.Abs(determinant) < 1e-300 {
+		return inverse, false
+	}
+
+	inverse[0][0] = (m[1][1]*m[2][2] - m[1][2]*m[2][1]) / determinant
+	inverse[0][1] = (m[0][2]*m[2][1] - m[0][1]*m[2][2]) / determinant
+	inverse[0][2] = (m[0][1]*m[1][2] - m[0][2]*m[1][1]) / determinant
+	inverse[1][0] = (m[1][2]*m[2][0] - m[1][0]*m[2][2]) / determinant
+	inverse[1][1] = (m[0][0]*m[2][2] - m[0][2]*m[2][0]) / determinant
+	inverse[1][2] = (m[0][2]*m[1][0] - m[0][0]*m[1][2]) / determinant
+	inverse[2][0] = (m[1][0]*m[2][1] - m[1][1]*m[2][0]) / determinant
+	inverse[2][1] = (m[0][1]*m[2][0] - m[0][0]*m[2][1]) / determinant
+	inverse[2][2] = (m[0][0]*m[1][1] - m[0][1]*m[1][0]) / determinant
+	return inverse, true
+}
@@ -4,6 +4,7 @@ package body
 import (
 	"math"
 
+	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 )
 
@@ -22,6 +23,22 @@ type ControllableBody interface {
 	// moveForward, moveBackward, moveLeft, moveRight, moveUp, moveDown sono i comandi di movimento
 	// rotateLeft, rotateRight, rotateUp, rotateDown sono i comandi di rotazione
 	HandleInput(deltaTime float64, moveForward, moveBackward, moveLeft, moveRight, moveUp, moveDown, rotateLeft, rotateRight, rotateUp, rotateDown bool)
+
+	// Fuel restituisce il propellente rimasto (zero senza motore)
+	Fuel() units.Quantity
+
+	// DeltaV restituisce il delta-v che il propellente rimasto può ancora dare al corpo (zero senza motore)
+	DeltaV() units.Quantity
+
+	// Thrust applica la spinta lungo una direzione (in coordinate del mondo) per un passo di durata dt,
+	// con una manetta tra 0 e 1, e restituisce la forza applicata
+	Thrust(direction vector.Vector3, throttle, dt float64) vector.Vector3
+
+	// MaxThrust restituisce la spinta a piena manetta
+	MaxThrust() units.Quantity
+
+	// Forward restituisce la direzione verso cui punta il corpo (in coordinate del mondo)
+	Forward() vector.Vector3
 }
 
 // ControllableRigidBody implementa un corpo rigido controllabile
@@ -31,6 +48,7 @@ type ControllableRigidBody struct {
 	isControllable bool
 	moveSpeed      float64 // Velocità di movimento (forza di propulsione)
 	rotateSpeed    float64 // Velocità di rotazione (radianti/secondo)
+	engine         *Engine // Motore a razzo (nil per una spinta fissa senza consumo di propellente)
 }
 
 // NewControllableRigidBody crea un nuovo corpo rigido controllabile
@@ -53,35 +71,80 @@ func (crb *ControllableRigidBody) SetControllable(controllable bool) {
 	crb.isControllable = controllable
 }
 
-// HandleInput gestisce l'input dell'utente per controllare il corpo
-func (crb *ControllableRigidBody) HandleInput(deltaTime float64, moveForward, moveBackward, moveLeft, moveRight, moveUp, moveDown, rotateLeft, rotateRight, rotateUp, rotateDown bool) {
-	if !crb.isControllable {
-		return
+// SetEngine imposta il motore a razzo del corpo.
+// La massa del propellente deve essere già inclusa nella massa del corpo.
+func (crb *ControllableRigidBody) SetEngine(engine *Engine) {
+	crb.engine = engine
+}
+
+// GetEngine restituisce il motore a razzo del corpo (nil se la spinta è fissa)
+func (crb *ControllableRigidBody) GetEngine() *Engine {
+	return crb.engine
+}
+
+// Fuel restituisce il propellente rimasto (zero senza motore)
+func (crb *ControllableRigidBody) Fuel() units.Quantity {
+	if crb.engine == nil {
+		return units.NewQuantity(0, units.Kilogram)
+	}
+	return crb.engine.GetFuel()
+}
+
+// DeltaV restituisce il delta-v che il propellente rimasto può ancora dare al corpo (zero senza motore)
+func (crb *ControllableRigidBody) DeltaV() units.Quantity {
+	if crb.engine == nil {
+		return units.NewQuantity(0, units.MeterPerSecond)
+	}
+	return crb.engine.DeltaV(crb.Mass())
+}
+
+// Thrust applica la spinta lungo una direzione (in coordinate del mondo) per un passo di durata dt,
+// con una manetta tra 0 e 1, e restituisce la forza applicata.
+// Con un motore la spinta consuma propellente e si ferma quando il serbatoio è vuoto.
+func (crb *ControllableRigidBody) Thrust(direction vector.Vector3, throttle, dt float64) vector.Vector3 {
+	throttle = math.Max(0, math.Min(1, throttle))
+	if direction.Length() == 0 || throttle == 0 {
+		return vector.Zero3()
+	}
+	direction = direction.Normalize()
+
+	if crb.engine != nil {
+		// F = ve ṁ
+		burned := crb.engine.Fire(crb, direction, throttle, dt)
+		if burned <= 0 || dt <= 0 {
+			return vector.Zero3()
+		}
+		return direction.Scale(crb.engine.ExhaustVelocity() * burned / dt)
 	}
 
-	// Calcola la direzione di movimento basata sulla rotazione del corpo
-	forward := vector.NewVector3(0, 0, -1) // Direzione iniziale (verso lo schermo)
+	thrust := direction.Scale(crb.moveSpeed * throttle)
+	crb.ApplyForce(thrust)
+	return thrust
+}
 
-	// Applica la rotazione del corpo alla direzione forward
-	rotation := crb.Rotation()
+// MaxThrust restituisce la spinta a piena manetta
+func (crb *ControllableRigidBody) MaxThrust() units.Quantity {
+	if crb.engine != nil {
+		return crb.engine.GetThrust()
+	}
+	return units.NewQuantity(crb.moveSpeed, units.Newton)
+}
 
-	// Calcola la matrice di rotazione (semplificata per questo esempio)
-	// In una implementazione completa, dovresti usare quaternioni o matrici di rotazione
-	cosY := float64(0.0)
-	sinY := float64(0.0)
+// Forward restituisce la direzione verso cui punta il corpo (in coordinate del mondo):
+// l'asse -Z del corpo ruotato secondo la sua rotazione
+func (crb *ControllableRigidBody) Forward() vector.Vector3 {
+	return Rotate(vector.NewVector3(0, 0, -1), crb.Rotation())
+}
 
-	// Evita calcoli NaN
-	if !math.IsNaN(rotation.Y()) {
-		cosY = math.Cos(rotation.Y())
-		sinY = math.Sin(rotation.Y())
+// HandleInput gestisce l'input dell'utente per controllare il corpo
+func (crb *ControllableRigidBody) HandleInput(deltaTime float64, moveForward, moveBackward, moveLeft, moveRight, moveUp, moveDown, rotateLeft, rotateRight, rotateUp, rotateDown bool) {
+	if !crb.isControllable {
+		return
 	}
 
-	// Ruota il vettore forward attorno all'asse Y
-	rotatedForward := vector.NewVector3(
-		forward.X()*cosY-forward.Z()*sinY,
-		forward.Y(),
-		forward.X()*sinY+forward.Z()*cosY,
-	)
+	// Calcola la direzione di movimento basata sulla rotazione del corpo,
+	// la stessa usata dall'autopilota e dalla camera
+	rotatedForward := crb.Forward()
 
 	// Calcola il vettore right (perpendicolare a forward)
 	right := vector.NewVector3(
@@ -116,16 +179,8 @@ func (crb *ControllableRigidBody) HandleInput(deltaTime float64, moveForward, mo
 		thrustForce = thrustForce.Add(up.Scale(-1))
 	}
 
-	// Normalizza la forza di propulsione se non è zero
-	if thrustForce.Length() > 0 {
-		thrustForce = thrustForce.Normalize()
-
-		// Applica la forza di propulsione
-		thrustForce = thrustForce.Scale(crb.moveSpeed)
-
-		// Applica la forza al corpo
-		crb.ApplyForce(thrustForce)
-	}
+	// Applica la spinta a piena manetta
+	crb.Thrust(thrustForce, 1, deltaTime)
 
 	// TODO: Implementare la stabilizzazione
 	// Quando non ci sono input di movimento, applicare una forza contraria alla velocità
@@ -0,0 +1,117 @@
+package body
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+// Engine is a rocket engine with its propellant.
+// The propellant is part of the mass of the body the engine is mounted on: firing the engine burns it at the
+// rate F / (Isp g0) and removes it from the body, so the burns follow the Tsiolkovsky rocket equation.
+type Engine struct {
+	thrust          float64 // Thrust at full throttle (N)
+	specificImpulse float64 // Specific impulse (s)
+	fuel            float64 // Remaining propellant (kg)
+}
+
+// NewEngine creates a new engine with the given thrust, specific impulse and propellant mass
+func NewEngine(thrust, specificImpulse, fuel units.Quantity) *Engine {
+	if thrust.Unit().Type() != units.Force {
+		panic("Thrust must be a force quantity")
+	}
+	if specificImpulse.Unit().Type() != units.Time {
+		panic("Specific impulse must be a time quantity")
+	}
+	if fuel.Unit().Type() != units.Mass {
+		panic("Fuel must be a mass quantity")
+	}
+
+	return &Engine{
+		thrust:          units.ConvertToStandardUnit(thrust),
+		specificImpulse: units.ConvertToStandardUnit(specificImpulse),
+		fuel:            units.ConvertToStandardUnit(fuel),
+	}
+}
+
+// GetThrust returns the thrust of the engine at full throttle
+func (e *Engine) GetThrust() units.Quantity {
+	return units.NewQuantity(e.thrust, units.Newton)
+}
+
+// GetSpecificImpulse returns the specific impulse of the engine
+func (e *Engine) GetSpecificImpulse() units.Quantity {
+	return units.NewQuantity(e.specificImpulse, units.Second)
+}
+
+// GetFuel returns the remaining propellant
+func (e *Engine) GetFuel() units.Quantity {
+	return units.NewQuantity(e.fuel, units.Kilogram)
+}
+
+// SetFuel sets the remaining propellant (e.g. when refueling; the mass of the body is not changed)
+func (e *Engine) SetFuel(fuel units.Quantity) {
+	if fuel.Unit().Type() != units.Mass {
+		panic("Fuel must be a mass quantity")
+	}
+	e.fuel = units.ConvertToStandardUnit(fuel)
+}
+
+// IsEmpty returns true if the engine has no propellant left
+func (e *Engine) IsEmpty() bool {
+	return e.fuel <= 0
+}
+
+// ExhaustVelocity returns the effective exhaust velocity of the engine: ve = Isp g0 (m/s)
+func (e *Engine) ExhaustVelocity() float64 {
+	return e.specificImpulse * constants.DefaultGravity
+}
+
+// MassFlowRate returns the propellant burned per second at full throttle: F / ve (kg/s)
+func (e *Engine) MassFlowRate() float64 {
+	exhaustVelocity := e.ExhaustVelocity()
+	if exhaustVelocity <= 0 {
+		return 0
+	}
+	return e.thrust / exhaustVelocity
+}
+
+// DeltaV returns the velocity change the remaining propellant can give to a body of the given total mass:
+// Δv = ve ln(m / (m - fuel))
+func (e *Engine) DeltaV(mass units.Quantity) units.Quantity {
+	total := units.ConvertToStandardUnit(mass)
+	dry := total - e.fuel
+	if e.fuel <= 0 || dry <= 0 {
+		return units.NewQuantity(0, units.MeterPerSecond)
+	}
+	return units.NewQuantity(e.ExhaustVelocity()*math.Log(total/dry), units.MeterPerSecond)
+}
+
+// Fire fires the engine on a body for a time step of duration dt, with a throttle between 0 and 1 and the thrust
+// along direction. It applies the thrust, removes the burned propellant from the mass of the body and returns
+// the burned mass (kg). When the propellant runs out during the step, the thrust is reduced accordingly.
+func (e *Engine) Fire(b Body, direction vector.Vector3, throttle, dt float64) float64 {
+	throttle = math.Max(0, math.Min(1, throttle))
+	if e.fuel <= 0 || throttle == 0 || dt <= 0 || direction.Length() == 0 {
+		return 0
+	}
+
+	burned := math.Min(e.fuel, e.MassFlowRate()*throttle*dt)
+	if burned <= 0 {
+		return 0
+	}
+
+	// Average thrust over the step, limited by the propellant left
+	thrust := e.thrust * burned / (e.MassFlowRate() * dt)
+	b.ApplyForce(direction.Normalize().Scale(thrust))
+
+	e.fuel -= burned
+	if e.fuel < 1e-12 {
+		e.fuel = 0
+	}
+	mass := units.ConvertToStandardUnit(b.Mass())
+	b.SetMass(units.NewQuantity(math.Max(mass-burned, 0), units.Kilogram))
+	return burned
+}
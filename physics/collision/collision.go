@@ -4,6 +4,7 @@ package collision
 import (
 	"math"
 
+	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 	"github.com/alexanderi96/go-space-engine/physics/body"
 )
@@ -13,6 +14,8 @@ type CollisionInfo struct {
 	BodyA       body.Body      // First body involved in the collision
 	BodyB       body.Body      // Second body involved in the collision
 	Point       vector.Vector3 // Contact point
+	ArmA        vector.Vector3 // Contact point relative to the center of A
+	ArmB        vector.Vector3 // Contact point relative to the center of B (across periodic boundaries if any)
 	Normal      vector.Vector3 // Collision normal (from A to B)
 	Depth       float64        // Penetration depth
 	HasCollided bool           // Indicates if a collision occurred
@@ -30,18 +33,47 @@ type CollisionResolver interface {
 	ResolveCollision(info CollisionInfo)
 }
 
+// SeparationFunc returns the displacement vector from one point to another
+type SeparationFunc func(from, to vector.Vector3) vector.Vector3
+
+// PeriodicCollider is a collider that can measure distances across periodic boundaries
+type PeriodicCollider interface {
+	Collider
+	// SetSeparation sets the function used to compute the displacement between bodies (nil for Euclidean)
+	SetSeparation(separation SeparationFunc)
+}
+
 // SphereCollider implements a collision detector for spherical bodies
-type SphereCollider struct{}
+type SphereCollider struct {
+	separation SeparationFunc // Displacement between bodies (nil for Euclidean)
+}
 
 // NewSphereCollider creates a new collision detector for spherical bodies
 func NewSphereCollider() *SphereCollider {
 	return &SphereCollider{}
 }
 
+// SetSeparation sets the function used to compute the displacement between bodies (nil for Euclidean)
+func (sc *SphereCollider) SetSeparation(separation SeparationFunc) {
+	sc.separation = separation
+}
+
 // CheckCollision checks if two spherical bodies collide
 func (sc *SphereCollider) CheckCollision(a, b body.Body) CollisionInfo {
 	// Calculate the direction vector from a to b
-	direction := b.Position().Sub(a.Position())
+	var direction vector.Vector3
+	if sc.separation != nil {
+		direction = sc.separation(a.Position(), b.Position())
+	} else {
+		direction = b.Position().Sub(a.Position())
+	}
+
+	// Compound bodies collide with their parts
+	_, compoundA := a.(body.CompoundShape)
+	_, compoundB := b.(body.CompoundShape)
+	if compoundA || compoundB {
+		return checkParts(a, b, direction)
+	}
 
 	// Calculate the squared distance
 	distanceSquared := direction.LengthSquared()
@@ -85,12 +117,62 @@ func (sc *SphereCollider) CheckCollision(a, b body.Body) CollisionInfo {
 		BodyA:       a,
 		BodyB:       b,
 		Point:       point,
+		ArmA:        normal.Scale(radiusA),
+		ArmB:        normal.Scale(radiusA).Sub(direction),
 		Normal:      normal,
 		Depth:       depth,
 		HasCollided: true,
 	}
 }
 
+// checkParts checks the collision between the parts of two bodies, given the displacement between their centers,
+// and returns the deepest contact
+func checkParts(a, b body.Body, direction vector.Vector3) CollisionInfo {
+	info := CollisionInfo{
+		BodyA:       a,
+		BodyB:       b,
+		HasCollided: false,
+	}
+
+	for _, sphereA := range partSpheres(a) {
+		for _, sphereB := range partSpheres(b) {
+			// Displacement between the parts, relative to the centers of the bodies
+			separation := direction.Add(sphereB.Center).Sub(sphereA.Center)
+			sumRadii := sphereA.Radius + sphereB.Radius
+			distance := separation.Length()
+			if distance >= sumRadii || sumRadii-distance <= info.Depth {
+				continue
+			}
+
+			normal := vector.NewVector3(1, 0, 0)
+			if distance > 1e-10 {
+				normal = separation.Scale(1.0 / distance)
+			}
+			info.ArmA = sphereA.Center.Add(normal.Scale(sphereA.Radius))
+			info.ArmB = info.ArmA.Sub(direction)
+			info.Point = a.Position().Add(info.ArmA)
+			info.Normal = normal
+			info.Depth = sumRadii - distance
+			info.HasCollided = true
+		}
+	}
+	return info
+}
+
+// partSpheres returns the collision spheres of a body, centered relative to its position
+func partSpheres(b body.Body) []body.Sphere {
+	compound, ok := b.(body.CompoundShape)
+	if !ok {
+		return []body.Sphere{{Center: vector.Zero3(), Radius: units.ConvertToStandardUnit(b.Radius())}}
+	}
+
+	spheres := compound.CollisionSpheres()
+	for i := range spheres {
+		spheres[i].Center = spheres[i].Center.Sub(b.Position())
+	}
+	return spheres
+}
+
 // ImpulseResolver implements an impulse-based collision resolver
 type ImpulseResolver struct {
 	restitution float64 // Coefficient of restitution (elasticity)
@@ -118,8 +200,21 @@ func (ir *ImpulseResolver) ResolveCollision(info CollisionInfo) {
 		return
 	}
 
+	// Bodies with an inertia tensor (compound bodies) also rotate: the velocities are those of the contact point
+	rotationalA, rotatingA := a.(body.RotationalInertia)
+	rotationalB, rotatingB := b.(body.RotationalInertia)
+	rotatingA = rotatingA && !a.IsStatic()
+	rotatingB = rotatingB && !b.IsStatic()
+	velocityA, velocityB := a.Velocity(), b.Velocity()
+	if rotatingA {
+		velocityA = velocityA.Add(a.AngularVelocity().Cross(info.ArmA))
+	}
+	if rotatingB {
+		velocityB = velocityB.Add(b.AngularVelocity().Cross(info.ArmB))
+	}
+
 	// Calculate the relative velocity
-	relativeVelocity := b.Velocity().Sub(a.Velocity())
+	relativeVelocity := velocityB.Sub(velocityA)
 
 	// Calculate the relative velocity along the normal
 	velocityAlongNormal := relativeVelocity.Dot(info.Normal)
@@ -154,25 +249,69 @@ func (ir *ImpulseResolver) ResolveCollision(info CollisionInfo) {
 		inverseMassB = 1.0 / massB
 	}
 
+	// The rotation of the bodies adds to the inverse effective mass along the normal:
+	// n · ((I⁻¹ (r × n)) × r) for each rotating body
+	inverseEffectiveMass := inverseMassA + inverseMassB
+	if rotatingA {
+		inverseEffectiveMass += info.Normal.Dot(rotationalA.AngularResponse(info.ArmA.Cross(info.Normal)).Cross(info.ArmA))
+	}
+	if rotatingB {
+		inverseEffectiveMass += info.Normal.Dot(rotationalB.AngularResponse(info.ArmB.Cross(info.Normal)).Cross(info.ArmB))
+	}
+
 	// Calculate the scalar impulse
 	j := -(1.0 + restitution) * velocityAlongNormal
-	j /= inverseMassA + inverseMassB
+	j /= inverseEffectiveMass
 
-	// Apply the impulse
+	// Apply the impulse, and its angular impulse r × J to the rotating bodies
 	impulse := info.Normal.Scale(j)
 
 	if !a.IsStatic() {
 		a.SetVelocity(a.Velocity().Sub(impulse.Scale(inverseMassA)))
 	}
+	if rotatingA {
+		rotationalA.ApplyAngularImpulse(impulse.Cross(info.ArmA))
+	}
 
 	if !b.IsStatic() {
 		b.SetVelocity(b.Velocity().Add(impulse.Scale(inverseMassB)))
 	}
+	if rotatingB {
+		rotationalB.ApplyAngularImpulse(info.ArmB.Cross(impulse))
+	}
+
+	// Convert the kinetic energy lost in the inelastic collision into heat, split between the bodies:
+	// ΔE = 1/2 * m * (1 - e²) * vn², with m the effective mass along the normal (the reduced mass without rotation)
+	dissipated := 0.5 * (1.0 - restitution*restitution) * velocityAlongNormal * velocityAlongNormal /
+		inverseEffectiveMass
+	if dissipated > 0 {
+		shareA := heatShare(a, b)
+		a.AddHeat(units.NewQuantity(shareA*dissipated, units.Joule))
+		b.AddHeat(units.NewQuantity((1-shareA)*dissipated, units.Joule))
+	}
 
 	// Correct the penetration (position resolution)
 	ir.resolvePosition(info)
 }
 
+// heatShare returns the fraction of the heat of a collision that goes to body a.
+// A static body takes no heat; otherwise the heat is split in proportion to the heat capacities,
+// so that both bodies warm up by the same amount.
+func heatShare(a, b body.Body) float64 {
+	if a.IsStatic() {
+		return 0
+	}
+	if b.IsStatic() {
+		return 1
+	}
+	capacityA := units.ConvertToStandardUnit(a.Mass()) * a.Material().SpecificHeat().Value()
+	capacityB := units.ConvertToStandardUnit(b.Mass()) * b.Material().SpecificHeat().Value()
+	if capacityA+capacityB <= 0 {
+		return 0.5
+	}
+	return capacityA / (capacityA + capacityB)
+}
+
 // resolvePosition corrects the penetration between bodies
 func (ir *ImpulseResolver) resolvePosition(info CollisionInfo) {
 	a := info.BodyA
@@ -0,0 +1,275 @@
+// Package constraint provides joints between bodies and a position-based solver to enforce them
+package constraint
+
+import (
+	"sync"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/google/uuid"
+)
+
+// Constraint represents a joint between two bodies
+type Constraint interface {
+	// BodyA returns the first body of the joint
+	BodyA() body.Body
+	// BodyB returns the second body of the joint
+	BodyB() body.Body
+
+	// Project moves and rotates the bodies to satisfy the joint, according to their inverse mass and inertia
+	Project()
+}
+
+// Solver enforces a set of constraints with position-based dynamics.
+// The solver integrates the joined bodies itself (semi-implicit Euler), then projects the joints one after
+// the other (Gauss-Seidel) for a number of iterations, so joints sharing bodies (e.g. the segments of a tether)
+// converge with the iterations. The velocities of the joined bodies are finally derived from their
+// displacement over the step.
+type Solver struct {
+	constraints []Constraint
+	iterations  int // Number of projection iterations per step
+	mutex       sync.RWMutex
+}
+
+// NewSolver creates a new constraint solver with 10 iterations per step
+func NewSolver() *Solver {
+	return &Solver{
+		constraints: make([]Constraint, 0),
+		iterations:  10,
+	}
+}
+
+// SetIterations sets the number of projection iterations per step
+func (s *Solver) SetIterations(iterations int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.iterations = iterations
+}
+
+// GetIterations returns the number of projection iterations per step
+func (s *Solver) GetIterations() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.iterations
+}
+
+// Add adds a constraint to the solver
+func (s *Solver) Add(c Constraint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.constraints = append(s.constraints, c)
+}
+
+// Remove removes a constraint from the solver
+func (s *Solver) Remove(c Constraint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, existing := range s.constraints {
+		if existing == c {
+			s.constraints = append(s.constraints[:i], s.constraints[i+1:]...)
+			return
+		}
+	}
+}
+
+// RemoveBody removes all the constraints involving a body
+func (s *Solver) RemoveBody(id uuid.UUID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	kept := s.constraints[:0]
+	for _, c := range s.constraints {
+		if c.BodyA().ID() != id && c.BodyB().ID() != id {
+			kept = append(kept, c)
+		}
+	}
+	s.constraints = kept
+}
+
+// GetConstraints returns all constraints of the solver
+func (s *Solver) GetConstraints() []Constraint {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]Constraint(nil), s.constraints...)
+}
+
+// Clear removes all constraints
+func (s *Solver) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.constraints = make([]Constraint, 0)
+}
+
+// Connected returns true if two bodies are joined by a constraint
+func (s *Solver) Connected(a, b uuid.UUID) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, c := range s.constraints {
+		idA, idB := c.BodyA().ID(), c.BodyB().ID()
+		if (idA == a && idB == b) || (idA == b && idB == a) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsJoined returns true if a body is joined to another one by a constraint
+func (s *Solver) IsJoined(id uuid.UUID) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, c := range s.constraints {
+		if c.BodyA().ID() == id || c.BodyB().ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Step advances the joined bodies by a time step of duration dt and enforces the joints.
+// The forces must already have been applied to the bodies.
+func (s *Solver) Step(dt float64) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if len(s.constraints) == 0 || dt <= 0 {
+		return
+	}
+
+	// Collect the joined bodies that can move
+	bodies := make([]body.Body, 0, 2*len(s.constraints))
+	seen := make(map[uuid.UUID]bool)
+	for _, c := range s.constraints {
+		for _, b := range []body.Body{c.BodyA(), c.BodyB()} {
+			if !seen[b.ID()] && !b.IsStatic() {
+				seen[b.ID()] = true
+				bodies = append(bodies, b)
+			}
+		}
+	}
+
+	// Predict the positions and orientations without the joints:
+	// v += a dt, x += v dt, ω += α dt, and the rotation by ω dt composed with the orientation
+	positions := make([]vector.Vector3, len(bodies))
+	rotations := make([]vector.Vector3, len(bodies))
+	for i, b := range bodies {
+		positions[i] = b.Position()
+		rotations[i] = b.Rotation()
+		velocity := b.Velocity().Add(b.Acceleration().Scale(dt))
+		angularVelocity := b.AngularVelocity().Add(b.AngularAcceleration().Scale(dt))
+		b.SetPosition(positions[i].Add(velocity.Scale(dt)))
+		b.SetRotation(body.ComposeRotations(rotations[i], angularVelocity.Scale(dt)))
+		b.SetAcceleration(vector.Zero3())
+		b.SetAngularAcceleration(vector.Zero3())
+	}
+
+	// Project the joints
+	for i := 0; i < s.iterations; i++ {
+		for _, c := range s.constraints {
+			c.Project()
+		}
+	}
+
+	// v = Δx / dt, ω = Δθ / dt, with Δθ the rotation from the old to the new orientation (R_new R_old⁻¹)
+	for i, b := range bodies {
+		b.SetVelocity(b.Position().Sub(positions[i]).Scale(1.0 / dt))
+		b.SetAngularVelocity(body.ComposeRotations(rotations[i].Scale(-1), b.Rotation()).Scale(1.0 / dt))
+	}
+}
+
+// inverseMass returns the inverse mass of a body (zero for static bodies)
+func inverseMass(b body.Body) float64 {
+	if b.IsStatic() {
+		return 0
+	}
+	return 1.0 / units.ConvertToStandardUnit(b.Mass())
+}
+
+// angularResponse returns the rotation of a body caused by an angular impulse: I⁻¹ L, with the inertia tensor
+// of the bodies that have one, and otherwise the body modeled as a solid sphere, I = 2/5 m r² (zero for static bodies)
+func angularResponse(b body.Body, impulse vector.Vector3) vector.Vector3 {
+	if b.IsStatic() {
+		return vector.Zero3()
+	}
+	if rotational, ok := b.(body.RotationalInertia); ok {
+		return rotational.AngularResponse(impulse)
+	}
+	radius := units.ConvertToStandardUnit(b.Radius())
+	inertia := 0.4 * units.ConvertToStandardUnit(b.Mass()) * radius * radius
+	if inertia <= 0 {
+		return vector.Zero3()
+	}
+	return impulse.Scale(1.0 / inertia)
+}
+
+// inverseInertia returns the inverse moment of inertia of a body about an axis: n · I⁻¹ n
+func inverseInertia(b body.Body, axis vector.Vector3) float64 {
+	return axis.Dot(angularResponse(b, axis))
+}
+
+// toLocal converts a point in world coordinates to the reference frame of a body
+func toLocal(b body.Body, point vector.Vector3) vector.Vector3 {
+	return body.Rotate(point.Sub(b.Position()), b.Rotation().Scale(-1))
+}
+
+// toLocalDirection converts a direction in world coordinates to the reference frame of a body
+func toLocalDirection(b body.Body, direction vector.Vector3) vector.Vector3 {
+	return body.Rotate(direction, b.Rotation().Scale(-1))
+}
+
+// arm returns the offset from the center of a body to a point given in its reference frame
+func arm(b body.Body, local vector.Vector3) vector.Vector3 {
+	return body.Rotate(local, b.Rotation())
+}
+
+// generalizedInverseMass returns the inverse mass of a body seen by a correction along a direction
+// applied at an offset from its center: 1/m + (r × n) · I⁻¹ (r × n)
+func generalizedInverseMass(b body.Body, r, direction vector.Vector3) float64 {
+	return inverseMass(b) + inverseInertia(b, r.Cross(direction))
+}
+
+// applyCorrection moves and rotates a body by a positional impulse applied at an offset from its center
+func applyCorrection(b body.Body, impulse, r vector.Vector3) {
+	if b.IsStatic() {
+		return
+	}
+	b.SetPosition(b.Position().Add(impulse.Scale(inverseMass(b))))
+	b.SetRotation(body.ComposeRotations(b.Rotation(), angularResponse(b, r.Cross(impulse))))
+}
+
+// projectPoints moves two anchor points (at offsets rA and rB from the centers of the bodies)
+// to remove their separation (from anchor a to anchor b)
+func projectPoints(a, b body.Body, rA, rB, separation vector.Vector3) {
+	distance := separation.Length()
+	if distance < 1e-12 {
+		return
+	}
+	direction := separation.Scale(1.0 / distance)
+	weight := generalizedInverseMass(a, rA, direction) + generalizedInverseMass(b, rB, direction)
+	if weight <= 0 {
+		return
+	}
+	impulse := direction.Scale(distance / weight)
+	applyCorrection(a, impulse, rA)
+	applyCorrection(b, impulse.Scale(-1), rB)
+}
+
+// projectRotation rotates two bodies to remove a relative rotation drift (the rotation vector, about the world
+// axes, of b relative to a in excess of the rest one), with an angular impulse about the drift axis shared
+// according to their inverse moments of inertia about it
+func projectRotation(a, b body.Body, drift vector.Vector3) {
+	angle := drift.Length()
+	if angle < 1e-12 {
+		return
+	}
+	axis := drift.Scale(1.0 / angle)
+	total := inverseInertia(a, axis) + inverseInertia(b, axis)
+	if total <= 0 {
+		return
+	}
+	impulse := axis.Scale(angle / total)
+	if !a.IsStatic() {
+		a.SetRotation(body.ComposeRotations(a.Rotation(), angularResponse(a, impulse)))
+	}
+	if !b.IsStatic() {
+		b.SetRotation(body.ComposeRotations(b.Rotation(), angularResponse(b, impulse.Scale(-1))))
+	}
+}
@@ -0,0 +1,190 @@
+package constraint
+
+import (
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// DistanceJoint keeps two anchor points at a fixed distance, like a rigid massless rod
+type DistanceJoint struct {
+	a, b         body.Body
+	localAnchorA vector.Vector3 // Anchor on a, in its reference frame
+	localAnchorB vector.Vector3 // Anchor on b, in its reference frame
+	length       float64        // Distance between the anchors (m)
+	onlyWhenTaut bool           // Only pull the anchors together, like a rope
+}
+
+// NewDistanceJoint creates a rigid rod between two anchor points given in world coordinates.
+// The length of the rod is the current distance between the anchors.
+func NewDistanceJoint(a, b body.Body, anchorA, anchorB vector.Vector3) *DistanceJoint {
+	return &DistanceJoint{
+		a:            a,
+		b:            b,
+		localAnchorA: toLocal(a, anchorA),
+		localAnchorB: toLocal(b, anchorB),
+		length:       anchorB.Sub(anchorA).Length(),
+	}
+}
+
+// BodyA returns the first body of the joint
+func (dj *DistanceJoint) BodyA() body.Body {
+	return dj.a
+}
+
+// BodyB returns the second body of the joint
+func (dj *DistanceJoint) BodyB() body.Body {
+	return dj.b
+}
+
+// SetLength sets the distance between the anchors
+func (dj *DistanceJoint) SetLength(length float64) {
+	dj.length = length
+}
+
+// GetLength returns the distance between the anchors
+func (dj *DistanceJoint) GetLength() float64 {
+	return dj.length
+}
+
+// Distance returns the current distance between the anchors
+func (dj *DistanceJoint) Distance() float64 {
+	_, _, direction := dj.anchors()
+	return direction.Length()
+}
+
+// anchors returns the offsets of the anchors from the centers of the bodies and the vector from anchor a to anchor b
+func (dj *DistanceJoint) anchors() (vector.Vector3, vector.Vector3, vector.Vector3) {
+	rA := arm(dj.a, dj.localAnchorA)
+	rB := arm(dj.b, dj.localAnchorB)
+	return rA, rB, dj.b.Position().Add(rB).Sub(dj.a.Position().Add(rA))
+}
+
+// Project moves the anchors along the rod to restore its length
+func (dj *DistanceJoint) Project() {
+	rA, rB, direction := dj.anchors()
+	distance := direction.Length()
+	if distance < 1e-10 || (dj.onlyWhenTaut && distance <= dj.length) {
+		return
+	}
+	projectPoints(dj.a, dj.b, rA, rB, direction.Scale((distance-dj.length)/distance))
+}
+
+// RopeJoint limits the distance between two anchor points: the rope goes slack when they get closer
+// and only pulls them together when it is taut (e.g. a tether between two satellites)
+type RopeJoint struct {
+	DistanceJoint
+}
+
+// NewRopeJoint creates a rope of the given maximum length between two anchor points given in world coordinates
+func NewRopeJoint(a, b body.Body, anchorA, anchorB vector.Vector3, maxLength float64) *RopeJoint {
+	joint := NewDistanceJoint(a, b, anchorA, anchorB)
+	joint.length = maxLength
+	joint.onlyWhenTaut = true
+	return &RopeJoint{DistanceJoint: *joint}
+}
+
+// IsTaut returns true if the rope is stretched to its maximum length
+func (rj *RopeJoint) IsTaut() bool {
+	return rj.Distance() >= rj.length*(1-1e-9)
+}
+
+// BallJoint (ball-and-socket) keeps an anchor point of two bodies together, leaving them free to rotate around it
+type BallJoint struct {
+	a, b         body.Body
+	localAnchorA vector.Vector3 // Pivot on a, in its reference frame
+	localAnchorB vector.Vector3 // Pivot on b, in its reference frame
+}
+
+// NewBallJoint creates a ball-and-socket joint at a pivot given in world coordinates
+func NewBallJoint(a, b body.Body, pivot vector.Vector3) *BallJoint {
+	return &BallJoint{
+		a:            a,
+		b:            b,
+		localAnchorA: toLocal(a, pivot),
+		localAnchorB: toLocal(b, pivot),
+	}
+}
+
+// BodyA returns the first body of the joint
+func (bj *BallJoint) BodyA() body.Body {
+	return bj.a
+}
+
+// BodyB returns the second body of the joint
+func (bj *BallJoint) BodyB() body.Body {
+	return bj.b
+}
+
+// Separation returns the vector between the pivots of the two bodies (zero when the joint is satisfied)
+func (bj *BallJoint) Separation() vector.Vector3 {
+	_, _, separation := bj.anchors()
+	return separation
+}
+
+// anchors returns the offsets of the pivots from the centers of the bodies and the vector from pivot a to pivot b
+func (bj *BallJoint) anchors() (vector.Vector3, vector.Vector3, vector.Vector3) {
+	rA := arm(bj.a, bj.localAnchorA)
+	rB := arm(bj.b, bj.localAnchorB)
+	return rA, rB, bj.b.Position().Add(rB).Sub(bj.a.Position().Add(rA))
+}
+
+// Project brings the pivots of the two bodies back together
+func (bj *BallJoint) Project() {
+	rA, rB, separation := bj.anchors()
+	projectPoints(bj.a, bj.b, rA, rB, separation)
+}
+
+// HingeJoint keeps an anchor point of two bodies together and only lets them rotate relative to each other
+// around an axis (e.g. a solar panel on its drive)
+type HingeJoint struct {
+	BallJoint
+	localAxisA vector.Vector3 // Hinge axis in the reference frame of a
+	localAxisB vector.Vector3 // Hinge axis in the reference frame of b
+}
+
+// NewHingeJoint creates a hinge at a pivot and around an axis given in world coordinates
+func NewHingeJoint(a, b body.Body, pivot, axis vector.Vector3) *HingeJoint {
+	axis = axis.Normalize()
+	return &HingeJoint{
+		BallJoint:  *NewBallJoint(a, b, pivot),
+		localAxisA: toLocalDirection(a, axis),
+		localAxisB: toLocalDirection(b, axis),
+	}
+}
+
+// Axis returns the hinge axis in world coordinates (as seen by the first body)
+func (hj *HingeJoint) Axis() vector.Vector3 {
+	return arm(hj.a, hj.localAxisA)
+}
+
+// Project realigns the hinge axes of the two bodies and brings their pivots back together
+func (hj *HingeJoint) Project() {
+	// b is rotated by (about) axisA × axisB off the hinge axis
+	projectRotation(hj.a, hj.b, hj.Axis().Cross(arm(hj.b, hj.localAxisB)))
+	hj.BallJoint.Project()
+}
+
+// FixedJoint welds two bodies together: they keep their relative position and orientation
+// (e.g. the parts of a spacecraft)
+type FixedJoint struct {
+	BallJoint
+	restRotation vector.Vector3 // Orientation of b relative to a when the joint was created (R_a⁻¹ R_b)
+}
+
+// NewFixedJoint creates a fixed joint between two bodies in their current configuration.
+// The pivot is the middle point between their centers.
+func NewFixedJoint(a, b body.Body) *FixedJoint {
+	pivot := a.Position().Add(b.Position()).Scale(0.5)
+	return &FixedJoint{
+		BallJoint:    *NewBallJoint(a, b, pivot),
+		restRotation: body.ComposeRotations(b.Rotation(), a.Rotation().Scale(-1)),
+	}
+}
+
+// Project restores the relative orientation of the bodies and brings their pivots back together
+func (fj *FixedJoint) Project() {
+	// b is rotated off its rest orientation R_a R_rest by R_b (R_a R_rest)⁻¹, about the world axes
+	rest := body.ComposeRotations(fj.restRotation, fj.a.Rotation())
+	projectRotation(fj.a, fj.b, body.ComposeRotations(rest.Scale(-1), fj.b.Rotation()))
+	fj.BallJoint.Project()
+}
@@ -0,0 +1,156 @@
+// Package cr3bp provides tools for the circular restricted three-body problem: Lagrange points, Jacobi constant
+// and periodic orbits around the collinear points.
+//
+// The functions of the package work in the normalized rotating frame: the unit of length is the distance between
+// the primary and the secondary, the unit of time is the inverse of their mean motion and the unit of mass is their
+// total mass. The origin is their center of mass, the primary is at (-μ, 0, 0) and the secondary at (1 - μ, 0, 0),
+// with μ the mass ratio of the secondary.
+package cr3bp
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+// Lagrange points
+const (
+	L1 = iota + 1
+	L2
+	L3
+	L4
+	L5
+)
+
+// integrationStep is the step of the numerical integration in normalized time units
+const integrationStep = 1e-3
+
+// LagrangePoints returns the positions of the five Lagrange points for a mass ratio
+func LagrangePoints(mu float64) [5]vector.Vector3 {
+	hill := math.Cbrt(mu / 3)
+	return [5]vector.Vector3{
+		vector.NewVector3(collinearPoint(mu, 1-mu-hill), 0, 0),
+		vector.NewVector3(collinearPoint(mu, 1-mu+hill), 0, 0),
+		vector.NewVector3(collinearPoint(mu, -1-5*mu/12), 0, 0),
+		vector.NewVector3(0.5-mu, math.Sqrt(3)/2, 0),
+		vector.NewVector3(0.5-mu, -math.Sqrt(3)/2, 0),
+	}
+}
+
+// collinearPoint finds the equilibrium point on the X axis closest to an initial guess with Newton's method
+func collinearPoint(mu, x float64) float64 {
+	for i := 0; i < 50; i++ {
+		d1, d2 := x+mu, x-1+mu
+		r1, r2 := math.Abs(d1), math.Abs(d2)
+		f := x - (1-mu)*d1/(r1*r1*r1) - mu*d2/(r2*r2*r2)
+		df := 1 + 2*(1-mu)/(r1*r1*r1) + 2*mu/(r2*r2*r2)
+		step := f / df
+		x -= step
+		if math.Abs(step) < 1e-15 {
+			break
+		}
+	}
+	return x
+}
+
+// Jacobi returns the Jacobi constant C = 2 Ω - v² of a state, with the pseudo-potential
+// Ω = (x² + y²) / 2 + (1 - μ) / r1 + μ / r2
+func Jacobi(mu float64, position, velocity vector.Vector3) float64 {
+	x, y, z := position.X(), position.Y(), position.Z()
+	r1 := math.Sqrt((x+mu)*(x+mu) + y*y + z*z)
+	r2 := math.Sqrt((x-1+mu)*(x-1+mu) + y*y + z*z)
+	omega := (x*x+y*y)/2 + (1-mu)/r1 + mu/r2
+	return 2*omega - velocity.LengthSquared()
+}
+
+// Propagate integrates a state for a time t (negative to go back in time)
+func Propagate(mu float64, position, velocity vector.Vector3, t float64) (vector.Vector3, vector.Vector3) {
+	state := toState(position, velocity)
+	steps := int(math.Ceil(math.Abs(t) / integrationStep))
+	if steps == 0 {
+		return position, velocity
+	}
+	h := t / float64(steps)
+	for i := 0; i < steps; i++ {
+		state = rk4(mu, state, h, false)
+	}
+	return fromState(state)
+}
+
+// toState packs a position and a velocity into a state
+func toState(position, velocity vector.Vector3) []float64 {
+	return []float64{position.X(), position.Y(), position.Z(), velocity.X(), velocity.Y(), velocity.Z()}
+}
+
+// fromState unpacks the position and the velocity of a state
+func fromState(state []float64) (vector.Vector3, vector.Vector3) {
+	return vector.NewVector3(state[0], state[1], state[2]), vector.NewVector3(state[3], state[4], state[5])
+}
+
+// derivatives returns the time derivative of a state, followed by the one of the state transition matrix
+// (row-major, 6×6) if it is included
+func derivatives(mu float64, state []float64) []float64 {
+	x, y, z := state[0], state[1], state[2]
+	vx, vy, vz := state[3], state[4], state[5]
+	d1, d2 := x+mu, x-1+mu
+	r1 := math.Sqrt(d1*d1 + y*y + z*z)
+	r2 := math.Sqrt(d2*d2 + y*y + z*z)
+	r13, r23 := r1*r1*r1, r2*r2*r2
+
+	derivative := make([]float64, len(state))
+	derivative[0], derivative[1], derivative[2] = vx, vy, vz
+	derivative[3] = 2*vy + x - (1-mu)*d1/r13 - mu*d2/r23
+	derivative[4] = -2*vx + y - (1-mu)*y/r13 - mu*y/r23
+	derivative[5] = -(1-mu)*z/r13 - mu*z/r23
+	if len(state) == 6 {
+		return derivative
+	}
+
+	// Hessian of the pseudo-potential
+	r15, r25 := r13*r1*r1, r23*r2*r2
+	uxx := 1 - (1-mu)/r13 - mu/r23 + 3*(1-mu)*d1*d1/r15 + 3*mu*d2*d2/r25
+	uyy := 1 - (1-mu)/r13 - mu/r23 + 3*(1-mu)*y*y/r15 + 3*mu*y*y/r25
+	uzz := -(1-mu)/r13 - mu/r23 + 3*(1-mu)*z*z/r15 + 3*mu*z*z/r25
+	uxy := 3*(1-mu)*d1*y/r15 + 3*mu*d2*y/r25
+	uxz := 3*(1-mu)*d1*z/r15 + 3*mu*d2*z/r25
+	uyz := 3*(1-mu)*y*z/r15 + 3*mu*y*z/r25
+
+	// Φ' = A Φ with A = [[0, I], [U, 2 J]]
+	phi := state[6:]
+	dPhi := derivative[6:]
+	for j := 0; j < 6; j++ {
+		p0, p1, p2, p3, p4, p5 := phi[j], phi[6+j], phi[12+j], phi[18+j], phi[24+j], phi[30+j]
+		dPhi[j], dPhi[6+j], dPhi[12+j] = p3, p4, p5
+		dPhi[18+j] = uxx*p0 + uxy*p1 + uxz*p2 + 2*p4
+		dPhi[24+j] = uxy*p0 + uyy*p1 + uyz*p2 - 2*p3
+		dPhi[30+j] = uxz*p0 + uyz*p1 + uzz*p2
+	}
+	return derivative
+}
+
+// rk4 advances a state by a step h with the fourth-order Runge-Kutta method,
+// together with its state transition matrix if withSTM is true
+func rk4(mu float64, state []float64, h float64, withSTM bool) []float64 {
+	if !withSTM {
+		state = state[:6]
+	}
+	n := len(state)
+	shifted := func(k []float64, scale float64) []float64 {
+		result := make([]float64, n)
+		for i := range result {
+			result[i] = state[i] + scale*k[i]
+		}
+		return result
+	}
+
+	k1 := derivatives(mu, state)
+	k2 := derivatives(mu, shifted(k1, h/2))
+	k3 := derivatives(mu, shifted(k2, h/2))
+	k4 := derivatives(mu, shifted(k3, h))
+
+	next := make([]float64, n)
+	for i := range next {
+		next[i] = state[i] + h/6*(k1[i]+2*k2[i]+2*k3[i]+k4[i])
+	}
+	return next
+}
@@ -0,0 +1,201 @@
+package cr3bp
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+const (
+	// maxCorrections is the maximum number of iterations of the differential correction
+	maxCorrections = 50
+	// correctionTolerance is the velocity error accepted at the half-period crossing
+	correctionTolerance = 1e-11
+	// maxHalfPeriod is the longest half period searched for the crossing of the XZ plane
+	maxHalfPeriod = 10.0
+)
+
+// PeriodicOrbit contains the initial conditions of a periodic orbit, starting on the XZ plane
+type PeriodicOrbit struct {
+	Position vector.Vector3
+	Velocity vector.Vector3
+	Period   float64
+}
+
+// Jacobi returns the Jacobi constant of the orbit
+func (o PeriodicOrbit) Jacobi(mu float64) float64 {
+	return Jacobi(mu, o.Position, o.Velocity)
+}
+
+// Lyapunov returns a planar Lyapunov orbit around a collinear Lagrange point (L1, L2 or L3), starting on the X
+// axis at a distance amplitude (normalized length) from the point, towards negative X. The initial conditions of the linearized
+// motion are refined with differential correction so that the orbit crosses the X axis perpendicularly.
+func Lyapunov(mu float64, point int, amplitude float64) (PeriodicOrbit, error) {
+	if point < L1 || point > L3 {
+		return PeriodicOrbit{}, fmt.Errorf("Lyapunov orbits exist around L1, L2 and L3, not L%d", point)
+	}
+	xL := LagrangePoints(mu)[point-1].X()
+	c2 := (1-mu)/math.Pow(math.Abs(xL+mu), 3) + mu/math.Pow(math.Abs(xL-1+mu), 3)
+	lambda := math.Sqrt((2 - c2 + math.Sqrt(9*c2*c2-8*c2)) / 2)
+	k := 2 * lambda / (lambda*lambda + 1 - c2)
+
+	state := []float64{xL - amplitude, 0, 0, 0, k * amplitude * lambda, 0}
+	for i := 0; i < maxCorrections; i++ {
+		final, halfPeriod, err := crossXZPlane(mu, state)
+		if err != nil {
+			return PeriodicOrbit{}, err
+		}
+		phi := final[6:]
+		vx, vy := final[3], final[4]
+		if math.Abs(vx) < correctionTolerance {
+			position, velocity := fromState(state)
+			return PeriodicOrbit{Position: position, Velocity: velocity, Period: 2 * halfPeriod}, nil
+		}
+
+		// δvx = (Φ34 - Φ14 ẍ / ẏ) δvy0
+		ax := derivatives(mu, final[:6])[3]
+		state[4] -= vx / (phi[3*6+4] - phi[1*6+4]*ax/vy)
+	}
+	return PeriodicOrbit{}, fmt.Errorf("the differential correction of the Lyapunov orbit did not converge")
+}
+
+// Halo returns a halo orbit around L1 or L2 with an out-of-plane amplitude (normalized length), northern (z > 0 at
+// the start) or southern. The initial conditions of Richardson's third-order approximation are refined with
+// differential correction, keeping the initial height, so that the orbit crosses the XZ plane perpendicularly.
+func Halo(mu float64, point int, amplitude float64, north bool) (PeriodicOrbit, error) {
+	if point != L1 && point != L2 {
+		return PeriodicOrbit{}, fmt.Errorf("halo orbits are computed around L1 and L2, not L%d", point)
+	}
+	state, err := richardson(mu, point, amplitude, north)
+	if err != nil {
+		return PeriodicOrbit{}, err
+	}
+
+	for i := 0; i < maxCorrections; i++ {
+		final, halfPeriod, err := crossXZPlane(mu, state)
+		if err != nil {
+			return PeriodicOrbit{}, err
+		}
+		phi := final[6:]
+		vx, vy, vz := final[3], final[4], final[5]
+		if math.Abs(vx) < correctionTolerance && math.Abs(vz) < correctionTolerance {
+			position, velocity := fromState(state)
+			return PeriodicOrbit{Position: position, Velocity: velocity, Period: 2 * halfPeriod}, nil
+		}
+
+		// [δvx, δvz] = (Φ - [ẍ, z̈] Φ1 / ẏ) [δx0, δvy0]
+		acceleration := derivatives(mu, final[:6])
+		ax, az := acceleration[3], acceleration[5]
+		m00 := phi[3*6+0] - ax/vy*phi[1*6+0]
+		m01 := phi[3*6+4] - ax/vy*phi[1*6+4]
+		m10 := phi[5*6+0] - az/vy*phi[1*6+0]
+		m11 := phi[5*6+4] - az/vy*phi[1*6+4]
+		determinant := m00*m11 - m01*m10
+		if determinant == 0 {
+			return PeriodicOrbit{}, fmt.Errorf("singular differential correction of the halo orbit")
+		}
+		state[0] -= (m11*vx - m01*vz) / determinant
+		state[4] -= (-m10*vx + m00*vz) / determinant
+	}
+	return PeriodicOrbit{}, fmt.Errorf("the differential correction of the halo orbit did not converge")
+}
+
+// crossXZPlane integrates a state with its state transition matrix until it crosses the XZ plane (y = 0) and
+// returns the state at the crossing and the elapsed time
+func crossXZPlane(mu float64, initial []float64) ([]float64, float64, error) {
+	state := make([]float64, 42)
+	copy(state, initial[:6])
+	for i := 0; i < 6; i++ {
+		state[6+7*i] = 1
+	}
+
+	t := 0.0
+	for t < maxHalfPeriod {
+		next := rk4(mu, state, integrationStep, true)
+		if t > 0 && (next[1] > 0) != (state[1] > 0) {
+			// Refine the crossing with Newton's method on the length of the last step
+			dt := -state[1] / state[4]
+			for i := 0; i < 10; i++ {
+				next = rk4(mu, state, dt, true)
+				correction := next[1] / next[4]
+				dt -= correction
+				if math.Abs(correction) < 1e-15 {
+					break
+				}
+			}
+			next = rk4(mu, state, dt, true)
+			return next, t + dt, nil
+		}
+		state = next
+		t += integrationStep
+	}
+	return nil, 0, fmt.Errorf("no crossing of the XZ plane within %v time units", maxHalfPeriod)
+}
+
+// richardson returns the initial state of a halo orbit from Richardson's third-order approximation
+func richardson(mu float64, point int, amplitude float64, north bool) ([]float64, error) {
+	xL := LagrangePoints(mu)[point-1].X()
+	gamma := math.Abs(xL - (1 - mu))
+
+	// Coefficients of the expansion of the potential around the point
+	c := func(n int) float64 {
+		sign := math.Pow(-1, float64(n))
+		if point == L1 {
+			return (mu + sign*(1-mu)*math.Pow(gamma, float64(n+1))/math.Pow(1-gamma, float64(n+1))) / (gamma * gamma * gamma)
+		}
+		return (sign*mu + sign*(1-mu)*math.Pow(gamma, float64(n+1))/math.Pow(1+gamma, float64(n+1))) / (gamma * gamma * gamma)
+	}
+	c2, c3, c4 := c(2), c(3), c(4)
+
+	lambda := math.Sqrt((2 - c2 + math.Sqrt(9*c2*c2-8*c2)) / 2)
+	l2 := lambda * lambda
+	k := 2 * lambda / (l2 + 1 - c2)
+	k2 := k * k
+	d1 := 3 * l2 / k * (k*(6*l2-1) - 2*lambda)
+	d2 := 8 * l2 / k * (k*(11*l2-1) - 2*lambda)
+
+	a21 := 3 * c3 * (k2 - 2) / (4 * (1 + 2*c2))
+	a22 := 3 * c3 / (4 * (1 + 2*c2))
+	a23 := -3 * c3 * lambda / (4 * k * d1) * (3*k2*k*lambda - 6*k*(k-lambda) + 4)
+	a24 := -3 * c3 * lambda / (4 * k * d1) * (2 + 3*k*lambda)
+	b21 := -3 * c3 * lambda / (2 * d1) * (3*k*lambda - 4)
+	b22 := 3 * c3 * lambda / d1
+	d21 := -c3 / (2 * l2)
+
+	a31 := -9*lambda/(4*d2)*(4*c3*(k*a23-b21)+k*c4*(4+k2)) + (9*l2+1-c2)/(2*d2)*(3*c3*(2*a23-k*b21)+c4*(2+3*k2))
+	a32 := -1 / d2 * (9*lambda/4*(4*c3*(k*a24-b22)+k*c4) + 1.5*(9*l2+1-c2)*(c3*(k*b22+d21-2*a24)-c4))
+	b31 := 3 / (8 * d2) * (8*lambda*(3*c3*(k*b21-2*a23)-c4*(2+3*k2)) + (9*l2+1+2*c2)*(4*c3*(k*a23-b21)+k*c4*(4+k2)))
+	b32 := 1 / d2 * (9*lambda*(c3*(k*b22+d21-2*a24)-c4) + 3.0/8*(9*l2+1+2*c2)*(4*c3*(k*a24-b22)+k*c4))
+	d31 := 3 / (64 * l2) * (4*c3*a24 + c4)
+	d32 := 3 / (64 * l2) * (4*c3*(a23-d21) + c4*(4+k2))
+
+	s1 := 1 / (2 * lambda * (lambda*(1+k2) - 2*k)) * (1.5*c3*(2*a21*(k2-2)-a23*(k2+2)-2*k*b21) - 3.0/8*c4*(3*k2*k2-8*k2+8))
+	s2 := 1 / (2 * lambda * (lambda*(1+k2) - 2*k)) * (1.5*c3*(2*a22*(k2-2)+a24*(k2+2)+2*k*b22+5*d21) + 3.0/8*c4*(12-k2))
+	a1 := -1.5*c3*(2*a21+a23+5*d21) - 3.0/8*c4*(12-k2)
+	a2 := 1.5*c3*(a24-2*a22) + 9.0/8*c4
+	ll1 := a1 + 2*l2*s1
+	ll2 := a2 + 2*l2*s2
+	delta := l2 - c2
+
+	// Amplitudes in units of the distance of the point from the secondary
+	az := amplitude / gamma
+	ax2 := (-ll2*az*az - delta) / ll1
+	if ax2 <= 0 {
+		return nil, fmt.Errorf("no halo orbit with an amplitude of %v", amplitude)
+	}
+	ax := math.Sqrt(ax2)
+	omega := 1 + s1*ax2 + s2*az*az
+	branch := 1.0
+	if !north {
+		branch = -1
+	}
+
+	// State at τ = 0 in the frame of the point
+	x := a21*ax2 + a22*az*az - ax + (a23*ax2 - a24*az*az) + (a31*ax2*ax - a32*ax*az*az)
+	z := branch*az + branch*d21*ax*az*(1-3) + branch*(d32*az*ax2-d31*az*az*az)
+	vy := lambda * omega * (k*ax + 2*(b21*ax2-b22*az*az) + 3*(b31*ax2*ax-b32*ax*az*az))
+
+	// Back to the rotating frame, scaling by the distance of the point from the secondary
+	return []float64{xL + gamma*x, 0, gamma * z, 0, gamma * vy, 0}, nil
+}
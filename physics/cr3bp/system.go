@@ -0,0 +1,98 @@
+package cr3bp
+
+import (
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/frame"
+)
+
+// System is a primary and a secondary body of a world, seen as a circular restricted three-body problem.
+// The normalized units follow their current distance and angular velocity.
+type System struct {
+	primary   body.Body
+	secondary body.Body
+	frame     *frame.RotatingFrame
+}
+
+// NewSystem creates the restricted three-body system of a primary and a secondary body
+func NewSystem(primary, secondary body.Body) *System {
+	return &System{
+		primary:   primary,
+		secondary: secondary,
+		frame:     frame.NewRotatingFrame(primary, secondary),
+	}
+}
+
+// GetPrimary returns the primary body
+func (s *System) GetPrimary() body.Body {
+	return s.primary
+}
+
+// GetSecondary returns the secondary body
+func (s *System) GetSecondary() body.Body {
+	return s.secondary
+}
+
+// GetFrame returns the frame co-rotating with the secondary around the primary
+func (s *System) GetFrame() *frame.RotatingFrame {
+	return s.frame
+}
+
+// MassRatio returns the mass ratio μ = m2 / (m1 + m2)
+func (s *System) MassRatio() float64 {
+	m1 := units.ConvertToStandardUnit(s.primary.Mass())
+	m2 := units.ConvertToStandardUnit(s.secondary.Mass())
+	return m2 / (m1 + m2)
+}
+
+// Distance returns the distance between the primary and the secondary (m), the unit of length
+func (s *System) Distance() float64 {
+	return s.secondary.Position().Sub(s.primary.Position()).Length()
+}
+
+// MeanMotion returns the angular velocity of the secondary around the primary (rad/s), the inverse of the unit
+// of time
+func (s *System) MeanMotion() float64 {
+	return s.frame.AngularVelocity().Length()
+}
+
+// LagrangePoints returns the current positions of the five Lagrange points in the world
+func (s *System) LagrangePoints() [5]vector.Vector3 {
+	var points [5]vector.Vector3
+	for i, point := range LagrangePoints(s.MassRatio()) {
+		points[i], _ = s.ToWorld(point, vector.Zero3())
+	}
+	return points
+}
+
+// ToWorld converts a normalized position and velocity of the rotating frame to the world
+func (s *System) ToWorld(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3) {
+	distance := s.Distance()
+	return s.frame.FromFrame(position.Scale(distance), velocity.Scale(distance*s.MeanMotion()))
+}
+
+// FromWorld converts a position and a velocity of the world to the normalized rotating frame
+func (s *System) FromWorld(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3) {
+	distance := s.Distance()
+	position, velocity = s.frame.ToFrame(position, velocity)
+	return position.Scale(1 / distance), velocity.Scale(1 / (distance * s.MeanMotion()))
+}
+
+// JacobiConstant returns the Jacobi constant of a test particle of the world
+func (s *System) JacobiConstant(b body.Body) float64 {
+	position, velocity := s.FromWorld(b.Position(), b.Velocity())
+	return Jacobi(s.MassRatio(), position, velocity)
+}
+
+// Place moves a body to the start of a periodic orbit of the system
+func (s *System) Place(b body.Body, orbit PeriodicOrbit) {
+	position, velocity := s.ToWorld(orbit.Position, orbit.Velocity)
+	b.SetPosition(position)
+	b.SetVelocity(velocity)
+}
+
+// Period returns the duration of a periodic orbit of the system (s)
+func (s *System) Period(orbit PeriodicOrbit) float64 {
+	return orbit.Period / s.MeanMotion()
+}
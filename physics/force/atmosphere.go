@@ -0,0 +1,257 @@
+package force
+
+import (
+	"math"
+	"sort"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// DensityProfile represents the density of an atmosphere as a function of the altitude above the surface
+type DensityProfile interface {
+	// Density returns the density (kg/m³) at an altitude (m) above the surface
+	Density(altitude float64) float64
+}
+
+// IdealGasDensity returns the density of an ideal gas: ρ = P M / (R T)
+func IdealGasDensity(pressure, temperature units.Quantity, molarMass float64) float64 {
+	if pressure.Unit().Type() != units.Pressure {
+		panic("Pressure must be a pressure quantity")
+	}
+	if temperature.Unit().Type() != units.Temperature {
+		panic("Temperature must be a temperature quantity")
+	}
+	return units.ConvertToStandardUnit(pressure) * molarMass /
+		(constants.GasConstant * units.ConvertToStandardUnit(temperature))
+}
+
+// ScaleHeight returns the scale height of an isothermal atmosphere: H = R T / (M g)
+func ScaleHeight(temperature units.Quantity, molarMass, gravity float64) float64 {
+	if temperature.Unit().Type() != units.Temperature {
+		panic("Temperature must be a temperature quantity")
+	}
+	return constants.GasConstant * units.ConvertToStandardUnit(temperature) / (molarMass * gravity)
+}
+
+// ExponentialProfile implements an isothermal atmosphere: ρ(h) = ρ0 exp(-h / H)
+type ExponentialProfile struct {
+	SurfaceDensity float64 // Density at the surface (kg/m³)
+	ScaleHeight    float64 // Altitude over which the density decreases by a factor e (m)
+	MaxAltitude    float64 // Altitude above which the density is zero (m), zero for no limit
+}
+
+// NewExponentialProfile creates a new exponential density profile
+func NewExponentialProfile(surfaceDensity, scaleHeight float64) *ExponentialProfile {
+	if scaleHeight <= 0 {
+		panic("Scale height must be positive")
+	}
+	return &ExponentialProfile{
+		SurfaceDensity: surfaceDensity,
+		ScaleHeight:    scaleHeight,
+	}
+}
+
+// NewEarthExponentialProfile creates an isothermal Earth atmosphere from the standard sea level
+// pressure and temperature (ρ0 ≈ 1.225 kg/m³, H ≈ 8.4 km)
+func NewEarthExponentialProfile() *ExponentialProfile {
+	temperature := units.NewQuantity(constants.SeaLevelTemperature, units.Kelvin)
+	return NewExponentialProfile(
+		IdealGasDensity(units.NewQuantity(constants.DefaultPressure, units.Pascal), temperature, constants.AirMolarMass),
+		ScaleHeight(temperature, constants.AirMolarMass, constants.DefaultGravity),
+	)
+}
+
+// Density returns the density at an altitude
+func (p *ExponentialProfile) Density(altitude float64) float64 {
+	if p.MaxAltitude > 0 && altitude > p.MaxAltitude {
+		return 0
+	}
+	if altitude < 0 {
+		altitude = 0
+	}
+	return p.SurfaceDensity * math.Exp(-altitude/p.ScaleHeight)
+}
+
+// TabulatedProfile implements a density profile interpolated from a table.
+// The density is interpolated log-linearly (exponentially) between the altitudes of the table,
+// it is constant below the first altitude and zero above the last one.
+type TabulatedProfile struct {
+	altitudes    []float64 // Altitudes of the table (m), strictly increasing
+	logDensities []float64 // Natural logarithm of the densities of the table
+}
+
+// NewTabulatedProfile creates a new tabulated density profile from altitudes (m) and densities (kg/m³)
+func NewTabulatedProfile(altitudes, densities []float64) *TabulatedProfile {
+	if len(altitudes) != len(densities) || len(altitudes) < 2 {
+		panic("Tabulated profile requires at least two altitudes with a density each")
+	}
+
+	logDensities := make([]float64, len(densities))
+	for i, density := range densities {
+		if density <= 0 {
+			panic("Tabulated densities must be positive")
+		}
+		if i > 0 && altitudes[i] <= altitudes[i-1] {
+			panic("Tabulated altitudes must be strictly increasing")
+		}
+		logDensities[i] = math.Log(density)
+	}
+
+	return &TabulatedProfile{
+		altitudes:    append([]float64(nil), altitudes...),
+		logDensities: logDensities,
+	}
+}
+
+// NewEarthTabulatedProfile creates an Earth atmosphere from 0 to 1000 km using the reference densities
+// of the piecewise exponential model of Vallado (based on the U.S. Standard Atmosphere 1976 and CIRA-72).
+// The sea level density is derived from the standard sea level pressure and temperature.
+func NewEarthTabulatedProfile() *TabulatedProfile {
+	seaLevel := IdealGasDensity(
+		units.NewQuantity(constants.DefaultPressure, units.Pascal),
+		units.NewQuantity(constants.SeaLevelTemperature, units.Kelvin),
+		constants.AirMolarMass,
+	)
+
+	altitudes := []float64{
+		0, 25, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140, 150,
+		180, 200, 250, 300, 350, 400, 450, 500, 600, 700, 800, 900, 1000,
+	}
+	densities := []float64{
+		seaLevel, 3.899e-2, 1.774e-2, 3.972e-3, 1.057e-3, 3.206e-4, 8.770e-5, 1.905e-5, 3.396e-6,
+		5.297e-7, 9.661e-8, 2.438e-8, 8.484e-9, 3.845e-9, 2.070e-9, 5.464e-10, 2.789e-10, 7.248e-11,
+		2.418e-11, 9.518e-12, 3.725e-12, 1.585e-12, 6.967e-13, 1.454e-13, 3.614e-14, 1.170e-14,
+		5.245e-15, 3.019e-15,
+	}
+	for i := range altitudes {
+		altitudes[i] *= 1000 // km -> m
+	}
+
+	return NewTabulatedProfile(altitudes, densities)
+}
+
+// Density returns the density at an altitude
+func (p *TabulatedProfile) Density(altitude float64) float64 {
+	last := len(p.altitudes) - 1
+	if altitude > p.altitudes[last] {
+		return 0
+	}
+	if altitude <= p.altitudes[0] {
+		return math.Exp(p.logDensities[0])
+	}
+
+	// Find the interval containing the altitude
+	i := sort.SearchFloat64s(p.altitudes, altitude)
+	if p.altitudes[i] == altitude {
+		return math.Exp(p.logDensities[i])
+	}
+	t := (altitude - p.altitudes[i-1]) / (p.altitudes[i] - p.altitudes[i-1])
+	return math.Exp(p.logDensities[i-1] + t*(p.logDensities[i]-p.logDensities[i-1]))
+}
+
+// AtmosphericDrag implements the drag of the atmosphere of a planet on the bodies flying through it:
+//
+//	F = -1/2 ρ Cd A |v_rel| v_rel
+//
+// where ρ is the density at the altitude of the body above the planet radius, A = π r² is the cross section
+// of the body and v_rel is the velocity of the body relative to the atmosphere, which co-rotates with the planet.
+type AtmosphericDrag struct {
+	planet          body.Body      // Body the atmosphere belongs to
+	profile         DensityProfile // Density as a function of the altitude
+	dragCoefficient float64        // Drag coefficient of the bodies (dimensionless)
+	rotation        vector.Vector3 // Angular velocity of the atmosphere, nil to follow the planet
+}
+
+// NewAtmosphericDrag creates a new atmosphere around a planet.
+// The drag coefficient defaults to 2.2, a typical value for satellites in free molecular flow.
+func NewAtmosphericDrag(planet body.Body, profile DensityProfile) *AtmosphericDrag {
+	return &AtmosphericDrag{
+		planet:          planet,
+		profile:         profile,
+		dragCoefficient: 2.2,
+	}
+}
+
+// SetDragCoefficient sets the drag coefficient of the bodies
+func (ad *AtmosphericDrag) SetDragCoefficient(coefficient float64) {
+	ad.dragCoefficient = coefficient
+}
+
+// GetDragCoefficient returns the drag coefficient of the bodies
+func (ad *AtmosphericDrag) GetDragCoefficient() float64 {
+	return ad.dragCoefficient
+}
+
+// SetRotation sets the angular velocity (rad/s) of the atmosphere, e.g. for a static planet
+// whose angular velocity is kept at zero. With nil the atmosphere follows the angular velocity of the planet.
+func (ad *AtmosphericDrag) SetRotation(angularVelocity vector.Vector3) {
+	ad.rotation = angularVelocity
+}
+
+// GetRotation returns the angular velocity of the atmosphere
+func (ad *AtmosphericDrag) GetRotation() vector.Vector3 {
+	if ad.rotation == nil {
+		return ad.planet.AngularVelocity()
+	}
+	return ad.rotation
+}
+
+// GetPlanet returns the body the atmosphere belongs to
+func (ad *AtmosphericDrag) GetPlanet() body.Body {
+	return ad.planet
+}
+
+// GetProfile returns the density profile of the atmosphere
+func (ad *AtmosphericDrag) GetProfile() DensityProfile {
+	return ad.profile
+}
+
+// Altitude returns the altitude of a body above the surface of the planet
+func (ad *AtmosphericDrag) Altitude(b body.Body) float64 {
+	distance := b.Position().Sub(ad.planet.Position()).Length()
+	return distance - units.ConvertToStandardUnit(ad.planet.Radius())
+}
+
+// RelativeVelocity returns the velocity of a body relative to the co-rotating atmosphere:
+// v_rel = v - (v_planet + ω × r)
+func (ad *AtmosphericDrag) RelativeVelocity(b body.Body) vector.Vector3 {
+	offset := b.Position().Sub(ad.planet.Position())
+	atmosphereVelocity := ad.planet.Velocity().Add(ad.GetRotation().Cross(offset))
+	return b.Velocity().Sub(atmosphereVelocity)
+}
+
+// Apply applies the atmospheric drag to a body
+func (ad *AtmosphericDrag) Apply(b body.Body) vector.Vector3 {
+	// The planet does not drag itself
+	if b.ID() == ad.planet.ID() {
+		return vector.Zero3()
+	}
+
+	density := ad.profile.Density(ad.Altitude(b))
+	if density <= 0 {
+		return vector.Zero3()
+	}
+
+	relativeVelocity := ad.RelativeVelocity(b)
+	speed := relativeVelocity.Length()
+	if speed < 1e-10 {
+		return vector.Zero3()
+	}
+
+	radius := units.ConvertToStandardUnit(b.Radius())
+	area := math.Pi * radius * radius
+	return relativeVelocity.Scale(-0.5 * density * ad.dragCoefficient * area * speed)
+}
+
+// ApplyBetween applies the atmospheric drag between two bodies (applies to both separately)
+func (ad *AtmosphericDrag) ApplyBetween(a, b body.Body) (vector.Vector3, vector.Vector3) {
+	return ad.Apply(a), ad.Apply(b)
+}
+
+// IsGlobal returns true because the atmosphere acts on every body flying through it
+func (ad *AtmosphericDrag) IsGlobal() bool {
+	return true
+}
@@ -0,0 +1,139 @@
+package force
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// kahanVector accumulates a vector sum using compensated (Kahan-Babuška) summation
+type kahanVector struct {
+	sum          [3]float64 // Running sum
+	compensation [3]float64 // Accumulated low-order bits lost by the running sum
+}
+
+// add adds a vector to the accumulator
+func (k *kahanVector) add(x, y, z float64) {
+	k.addComponent(0, x)
+	k.addComponent(1, y)
+	k.addComponent(2, z)
+}
+
+// addComponent adds a value to a single component using Neumaier's variant of Kahan summation
+func (k *kahanVector) addComponent(i int, value float64) {
+	t := k.sum[i] + value
+	if math.Abs(k.sum[i]) >= math.Abs(value) {
+		k.compensation[i] += (k.sum[i] - t) + value
+	} else {
+		k.compensation[i] += (value - t) + k.sum[i]
+	}
+	k.sum[i] = t
+}
+
+// vector returns the compensated sum
+func (k *kahanVector) vector() vector.Vector3 {
+	return vector.NewVector3(
+		k.sum[0]+k.compensation[0],
+		k.sum[1]+k.compensation[1],
+		k.sum[2]+k.compensation[2],
+	)
+}
+
+// SeparationFunc returns the displacement vector from one point to another
+type SeparationFunc func(from, to vector.Vector3) vector.Vector3
+
+// separate returns the displacement between two points, using the separation function if set
+func separate(separation SeparationFunc, from, to [3]float64) (float64, float64, float64) {
+	if separation == nil {
+		return to[0] - from[0], to[1] - from[1], to[2] - from[2]
+	}
+	d := separation(
+		vector.NewVector3(from[0], from[1], from[2]),
+		vector.NewVector3(to[0], to[1], to[2]),
+	)
+	return d.X(), d.Y(), d.Z()
+}
+
+// DirectGravity calculates the exact gravitational force on each body by summing over all pairs.
+// Each pair is evaluated once and applied to both bodies with opposite signs, so the total
+// momentum is conserved to rounding error. If compensated is true, the per-body sums use
+// Kahan summation to make the result (almost) independent of the summation order.
+// If separation is not nil it is used to compute the displacement between bodies
+// (e.g. the minimum image convention for periodic boundaries).
+// The returned slice is indexed like bodies.
+func DirectGravity(bodies []body.Body, g float64, compensated bool, separation SeparationFunc) []vector.Vector3 {
+	n := len(bodies)
+
+	// Cache positions and masses (in standard units) to avoid repeated method calls
+	positions := make([][3]float64, n)
+	masses := make([]float64, n)
+	for i, b := range bodies {
+		positions[i] = b.Position().ToArray()
+		masses[i] = units.ConvertToStandardUnit(b.Mass())
+	}
+
+	sums := make([]kahanVector, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dx, dy, dz := separate(separation, positions[i], positions[j])
+			distanceSquared := dx*dx + dy*dy + dz*dz
+
+			// Avoid division by zero or too large forces
+			if distanceSquared < 1e-10 {
+				continue
+			}
+
+			// F = G * m1 * m2 / r^2 along the unit vector r/|r|
+			distance := math.Sqrt(distanceSquared)
+			scale := g * masses[i] * masses[j] / (distanceSquared * distance)
+			fx, fy, fz := dx*scale, dy*scale, dz*scale
+
+			if compensated {
+				sums[i].add(fx, fy, fz)
+				sums[j].add(-fx, -fy, -fz)
+			} else {
+				sums[i].sum[0] += fx
+				sums[i].sum[1] += fy
+				sums[i].sum[2] += fz
+				sums[j].sum[0] -= fx
+				sums[j].sum[1] -= fy
+				sums[j].sum[2] -= fz
+			}
+		}
+	}
+
+	forces := make([]vector.Vector3, n)
+	for i := range sums {
+		forces[i] = sums[i].vector()
+	}
+	return forces
+}
+
+// DirectPotentialEnergy calculates the exact gravitational potential energy of some bodies (J) by summing
+// -G m1 m2 / r over all pairs. If separation is not nil it is used to compute the displacement between bodies.
+func DirectPotentialEnergy(bodies []body.Body, g float64, separation SeparationFunc) float64 {
+	n := len(bodies)
+	positions := make([][3]float64, n)
+	masses := make([]float64, n)
+	for i, b := range bodies {
+		positions[i] = b.Position().ToArray()
+		masses[i] = units.ConvertToStandardUnit(b.Mass())
+	}
+
+	energy := 0.0
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dx, dy, dz := separate(separation, positions[i], positions[j])
+			distanceSquared := dx*dx + dy*dy + dz*dz
+
+			// Skip the pairs the force skips
+			if distanceSquared < 1e-10 {
+				continue
+			}
+			energy -= g * masses[i] * masses[j] / math.Sqrt(distanceSquared)
+		}
+	}
+	return energy
+}
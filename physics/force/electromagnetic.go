@@ -0,0 +1,243 @@
+package force
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// DirectCoulomb calculates the exact electrostatic force on each body by summing over all pairs of charged bodies.
+// Each pair is evaluated once and applied to both bodies with opposite signs.
+// If separation is not nil it is used to compute the displacement between bodies.
+// The returned slice is indexed like bodies.
+func DirectCoulomb(bodies []body.Body, k float64, separation SeparationFunc) []vector.Vector3 {
+	n := len(bodies)
+
+	// Cache positions and charges (in standard units) to avoid repeated method calls
+	positions := make([][3]float64, n)
+	charges := make([]float64, n)
+	for i, b := range bodies {
+		positions[i] = b.Position().ToArray()
+		charges[i] = units.ConvertToStandardUnit(b.Charge())
+	}
+
+	sums := make([]kahanVector, n)
+	for i := 0; i < n; i++ {
+		if charges[i] == 0 {
+			continue
+		}
+		for j := i + 1; j < n; j++ {
+			if charges[j] == 0 {
+				continue
+			}
+			dx, dy, dz := separate(separation, positions[i], positions[j])
+			distanceSquared := dx*dx + dy*dy + dz*dz
+
+			// Avoid division by zero or too large forces
+			if distanceSquared < 1e-10 {
+				continue
+			}
+
+			// F = k * q1 * q2 / r^2, repulsive for charges of the same sign
+			distance := math.Sqrt(distanceSquared)
+			scale := -k * charges[i] * charges[j] / (distanceSquared * distance)
+			fx, fy, fz := dx*scale, dy*scale, dz*scale
+
+			sums[i].add(fx, fy, fz)
+			sums[j].add(-fx, -fy, -fz)
+		}
+	}
+
+	forces := make([]vector.Vector3, n)
+	for i := range sums {
+		forces[i] = sums[i].vector()
+	}
+	return forces
+}
+
+// CoulombForce implements the electrostatic force between charged bodies.
+// Like gravity, the world evaluates it on all bodies at once, using the Barnes-Hut algorithm on the octree
+// (with the total charge and charge center of each node) or the exact pair sum.
+type CoulombForce struct {
+	K     float64 // Coulomb constant
+	Theta float64 // Approximation parameter for the Barnes-Hut algorithm
+}
+
+// NewCoulombForce creates a new Coulomb force
+func NewCoulombForce() *CoulombForce {
+	return &CoulombForce{
+		K:     constants.CoulombConstant,
+		Theta: 0.5,
+	}
+}
+
+// Apply applies the Coulomb force to a body (does nothing for a single body)
+func (cf *CoulombForce) Apply(b body.Body) vector.Vector3 {
+	// The Coulomb force requires two bodies to be applied
+	return vector.Zero3()
+}
+
+// ApplyBetween applies the Coulomb force between two bodies
+func (cf *CoulombForce) ApplyBetween(a, b body.Body) (vector.Vector3, vector.Vector3) {
+	forces := DirectCoulomb([]body.Body{a, b}, cf.K, nil)
+	return forces[0], forces[1]
+}
+
+// IsGlobal returns true because the Coulomb force acts between all charged bodies
+func (cf *CoulombForce) IsGlobal() bool {
+	return true
+}
+
+// AppliesTo returns true if the body is charged
+func (cf *CoulombForce) AppliesTo(b body.Body) bool {
+	return b.Charge().Value() != 0
+}
+
+// SetTheta sets the approximation parameter for the Barnes-Hut algorithm
+func (cf *CoulombForce) SetTheta(theta float64) {
+	cf.Theta = theta
+}
+
+// GetTheta returns the approximation parameter for the Barnes-Hut algorithm
+func (cf *CoulombForce) GetTheta() float64 {
+	return cf.Theta
+}
+
+// MagneticField represents a magnetic field
+type MagneticField interface {
+	// FieldAt returns the magnetic field (T) at a position
+	FieldAt(position vector.Vector3) vector.Vector3
+}
+
+// UniformField implements a magnetic field that is the same everywhere
+type UniformField struct {
+	field vector.Vector3
+}
+
+// NewUniformField creates a new uniform magnetic field
+func NewUniformField(field vector.Vector3) *UniformField {
+	return &UniformField{
+		field: field,
+	}
+}
+
+// FieldAt returns the magnetic field at a position
+func (uf *UniformField) FieldAt(position vector.Vector3) vector.Vector3 {
+	return uf.field
+}
+
+// DipoleField implements the field of a magnetic dipole centered on a body (e.g. a magnetized planet):
+//
+//	B = μ0 / (4π) * (3 r̂ (m · r̂) - m) / r³
+type DipoleField struct {
+	center body.Body      // Body the dipole is centered on
+	moment vector.Vector3 // Magnetic dipole moment (A⋅m²)
+}
+
+// NewDipoleField creates a new dipole field centered on a body
+func NewDipoleField(center body.Body, moment vector.Vector3) *DipoleField {
+	return &DipoleField{
+		center: center,
+		moment: moment,
+	}
+}
+
+// GetCenter returns the body the dipole is centered on
+func (df *DipoleField) GetCenter() body.Body {
+	return df.center
+}
+
+// GetMoment returns the magnetic dipole moment
+func (df *DipoleField) GetMoment() vector.Vector3 {
+	return df.moment
+}
+
+// FieldAt returns the magnetic field at a position
+func (df *DipoleField) FieldAt(position vector.Vector3) vector.Vector3 {
+	offset := position.Sub(df.center.Position())
+	distance := offset.Length()
+
+	// The field is singular at the center of the dipole
+	if distance < 1e-10 {
+		return vector.Zero3()
+	}
+
+	direction := offset.Scale(1.0 / distance)
+	scale := constants.VacuumPermeability / (4.0 * math.Pi * distance * distance * distance)
+	return direction.Scale(3.0 * df.moment.Dot(direction)).Sub(df.moment).Scale(scale)
+}
+
+// LorentzForce implements the force of an electromagnetic field on charged bodies: F = q (E + v × B)
+type LorentzForce struct {
+	magnetic MagneticField  // Magnetic field, nil for none
+	electric vector.Vector3 // Uniform electric field (V/m)
+}
+
+// NewLorentzForce creates a new Lorentz force from a magnetic field
+func NewLorentzForce(magnetic MagneticField) *LorentzForce {
+	return &LorentzForce{
+		magnetic: magnetic,
+		electric: vector.Zero3(),
+	}
+}
+
+// SetMagneticField sets the magnetic field
+func (lf *LorentzForce) SetMagneticField(magnetic MagneticField) {
+	lf.magnetic = magnetic
+}
+
+// GetMagneticField returns the magnetic field
+func (lf *LorentzForce) GetMagneticField() MagneticField {
+	return lf.magnetic
+}
+
+// SetElectricField sets the uniform electric field
+func (lf *LorentzForce) SetElectricField(electric vector.Vector3) {
+	lf.electric = electric
+}
+
+// GetElectricField returns the uniform electric field
+func (lf *LorentzForce) GetElectricField() vector.Vector3 {
+	return lf.electric
+}
+
+// Apply applies the Lorentz force to a body
+func (lf *LorentzForce) Apply(b body.Body) vector.Vector3 {
+	charge := units.ConvertToStandardUnit(b.Charge())
+	if charge == 0 {
+		return vector.Zero3()
+	}
+
+	field := lf.electric
+	if lf.magnetic != nil {
+		velocity := b.Velocity()
+		if dipole, ok := lf.magnetic.(*DipoleField); ok {
+			// The dipole does not act on the body it is centered on, and moves with it:
+			// the magnetic force depends on the velocity relative to the center
+			if dipole.center.ID() == b.ID() {
+				return field.Scale(charge)
+			}
+			velocity = velocity.Sub(dipole.center.Velocity())
+		}
+		field = field.Add(velocity.Cross(lf.magnetic.FieldAt(b.Position())))
+	}
+	return field.Scale(charge)
+}
+
+// ApplyBetween applies the Lorentz force between two bodies (applies to both separately)
+func (lf *LorentzForce) ApplyBetween(a, b body.Body) (vector.Vector3, vector.Vector3) {
+	return lf.Apply(a), lf.Apply(b)
+}
+
+// IsGlobal returns true because the field acts on every charged body
+func (lf *LorentzForce) IsGlobal() bool {
+	return true
+}
+
+// AppliesTo returns true if the body is charged
+func (lf *LorentzForce) AppliesTo(b body.Body) bool {
+	return b.Charge().Value() != 0
+}
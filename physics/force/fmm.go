@@ -0,0 +1,339 @@
+package force
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+const (
+	// fmmLeafSize is the maximum number of bodies in a leaf cell
+	fmmLeafSize = 8
+	// fmmMaxDepth limits the depth of the tree (protects against coincident bodies)
+	fmmMaxDepth = 20
+)
+
+// fmmCell is a cell of the tree used by the fast multipole method
+type fmmCell struct {
+	center   [3]float64    // Geometric center of the cell
+	halfSize float64       // Half of the cell edge length
+	indices  []int         // Indices of the bodies contained in the cell
+	children []*fmmCell    // Non-empty children (nil for leaves)
+	mass     float64       // Total mass of the cell (monopole moment)
+	com      [3]float64    // Center of mass of the cell (expansion center)
+	radius   float64       // Distance from the center of mass to the farthest body
+	field    [3]float64    // Local expansion: gravitational field at the center of mass
+	gradient [3][3]float64 // Local expansion: field gradient at the center of mass
+}
+
+// fmmSolver holds the state of a single FMM evaluation
+type fmmSolver struct {
+	g             float64
+	theta         float64
+	separation    SeparationFunc
+	positions     [][3]float64
+	masses        []float64
+	accelerations [][3]float64
+}
+
+// FMMGravity calculates the gravitational force on each body using a fast multipole method.
+// Multipole expansions are truncated at the monopole and local expansions at the first order
+// (field and field gradient), and cells interact with a dual tree traversal when
+// (radiusA + radiusB) < theta * distance. Every interaction is applied symmetrically.
+// If separation is not nil it is used to compute the displacement between bodies and cells.
+// The returned slice is indexed like bodies.
+func FMMGravity(bodies []body.Body, g, theta float64, separation SeparationFunc) []vector.Vector3 {
+	n := len(bodies)
+	forces := make([]vector.Vector3, n)
+	if n == 0 {
+		return forces
+	}
+
+	solver := &fmmSolver{
+		g:             g,
+		theta:         theta,
+		separation:    separation,
+		positions:     make([][3]float64, n),
+		masses:        make([]float64, n),
+		accelerations: make([][3]float64, n),
+	}
+	for i, b := range bodies {
+		solver.positions[i] = b.Position().ToArray()
+		solver.masses[i] = units.ConvertToStandardUnit(b.Mass())
+	}
+
+	root := solver.buildRoot()
+	solver.upwardPass(root)
+	solver.interact(root, root)
+	solver.downwardPass(root)
+
+	for i := range forces {
+		a := solver.accelerations[i]
+		forces[i] = vector.NewVector3(a[0], a[1], a[2]).Scale(solver.masses[i])
+	}
+	return forces
+}
+
+// buildRoot creates the root cell as the smallest cube enclosing all bodies and subdivides it
+func (s *fmmSolver) buildRoot() *fmmCell {
+	min := s.positions[0]
+	max := s.positions[0]
+	for _, p := range s.positions {
+		for k := 0; k < 3; k++ {
+			min[k] = math.Min(min[k], p[k])
+			max[k] = math.Max(max[k], p[k])
+		}
+	}
+
+	root := &fmmCell{indices: make([]int, len(s.positions))}
+	for k := 0; k < 3; k++ {
+		root.center[k] = 0.5 * (min[k] + max[k])
+		root.halfSize = math.Max(root.halfSize, 0.5*(max[k]-min[k]))
+	}
+	for i := range root.indices {
+		root.indices[i] = i
+	}
+
+	s.subdivide(root, 0)
+	return root
+}
+
+// subdivide recursively splits a cell into octants until it holds few enough bodies
+func (s *fmmSolver) subdivide(cell *fmmCell, depth int) {
+	if len(cell.indices) <= fmmLeafSize || depth >= fmmMaxDepth {
+		return
+	}
+
+	var octants [8][]int
+	for _, i := range cell.indices {
+		octant := 0
+		for k := 0; k < 3; k++ {
+			if s.positions[i][k] >= cell.center[k] {
+				octant |= 1 << k
+			}
+		}
+		octants[octant] = append(octants[octant], i)
+	}
+
+	quarter := 0.5 * cell.halfSize
+	for octant, indices := range octants {
+		if len(indices) == 0 {
+			continue
+		}
+		child := &fmmCell{halfSize: quarter, indices: indices}
+		for k := 0; k < 3; k++ {
+			if octant&(1<<k) != 0 {
+				child.center[k] = cell.center[k] + quarter
+			} else {
+				child.center[k] = cell.center[k] - quarter
+			}
+		}
+		s.subdivide(child, depth+1)
+		cell.children = append(cell.children, child)
+	}
+}
+
+// upwardPass computes the multipole moments (mass and center of mass) of every cell
+func (s *fmmSolver) upwardPass(cell *fmmCell) {
+	for _, child := range cell.children {
+		s.upwardPass(child)
+	}
+
+	weighted := [3]float64{}
+	for _, i := range cell.indices {
+		cell.mass += s.masses[i]
+		for k := 0; k < 3; k++ {
+			weighted[k] += s.masses[i] * s.positions[i][k]
+		}
+	}
+
+	if cell.mass > 0 {
+		for k := 0; k < 3; k++ {
+			cell.com[k] = weighted[k] / cell.mass
+		}
+	} else {
+		cell.com = cell.center
+	}
+
+	for _, i := range cell.indices {
+		cell.radius = math.Max(cell.radius, distance3(cell.com, s.positions[i]))
+	}
+}
+
+// interact performs the dual tree traversal between two cells
+func (s *fmmSolver) interact(a, b *fmmCell) {
+	if a.mass == 0 || b.mass == 0 {
+		return
+	}
+
+	// Self interaction: recurse on all pairs of children
+	if a == b {
+		if a.children == nil {
+			s.directWithin(a)
+			return
+		}
+		for i, ci := range a.children {
+			for _, cj := range a.children[i:] {
+				s.interact(ci, cj)
+			}
+		}
+		return
+	}
+
+	// Well separated cells interact through their expansions
+	dx, dy, dz := separate(s.separation, a.com, b.com)
+	distance := math.Sqrt(dx*dx + dy*dy + dz*dz)
+	if distance > 0 && a.radius+b.radius < s.theta*distance && s.consistentImage(a, b, [3]float64{dx, dy, dz}) {
+		s.cellToCell(a, b)
+		s.cellToCell(b, a)
+		return
+	}
+
+	// Two leaves that are too close interact directly
+	if a.children == nil && b.children == nil {
+		s.directBetween(a, b)
+		return
+	}
+
+	// Otherwise split the larger cell (or the only one that can be split)
+	if b.children == nil || (a.children != nil && a.radius >= b.radius) {
+		for _, child := range a.children {
+			s.interact(child, b)
+		}
+	} else {
+		for _, child := range b.children {
+			s.interact(a, child)
+		}
+	}
+}
+
+// consistentImage checks that all pairs of bodies of two cells see each other through the same
+// periodic image, i.e. that the separation does not jump within the extent of the cells.
+// Cells that straddle the jump are not approximated and are split further.
+func (s *fmmSolver) consistentImage(a, b *fmmCell, delta [3]float64) bool {
+	if s.separation == nil {
+		return true
+	}
+
+	extent := a.radius + b.radius
+	tolerance := 1e-9 * (math.Abs(delta[0]) + math.Abs(delta[1]) + math.Abs(delta[2]) + extent)
+	for k := 0; k < 3; k++ {
+		for _, sign := range [2]float64{-1, 1} {
+			probe := b.com
+			probe[k] += sign * extent
+			d := [3]float64{}
+			d[0], d[1], d[2] = separate(s.separation, a.com, probe)
+			if math.Abs(d[k]-(delta[k]+sign*extent)) > tolerance {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cellToCell adds the contribution of the source cell to the local expansion of the target cell
+func (s *fmmSolver) cellToCell(target, source *fmmCell) {
+	var r [3]float64
+	r[0], r[1], r[2] = separate(s.separation, target.com, source.com)
+	distanceSquared := r[0]*r[0] + r[1]*r[1] + r[2]*r[2]
+	distance := math.Sqrt(distanceSquared)
+
+	// Field: G * M * r / |r|^3
+	// Gradient: G * M * (3 * r r^T / |r|^5 - I / |r|^3)
+	gm := s.g * source.mass
+	inv3 := gm / (distanceSquared * distance)
+	inv5 := 3.0 * inv3 / distanceSquared
+	for i := 0; i < 3; i++ {
+		target.field[i] += r[i] * inv3
+		for j := 0; j < 3; j++ {
+			target.gradient[i][j] += r[i] * r[j] * inv5
+		}
+		target.gradient[i][i] -= inv3
+	}
+}
+
+// downwardPass translates the local expansions to the children and evaluates them at the bodies
+func (s *fmmSolver) downwardPass(cell *fmmCell) {
+	if cell.children == nil {
+		for _, i := range cell.indices {
+			acc := s.evaluateLocal(cell, s.positions[i])
+			for k := 0; k < 3; k++ {
+				s.accelerations[i][k] += acc[k]
+			}
+		}
+		return
+	}
+
+	for _, child := range cell.children {
+		shifted := s.evaluateLocal(cell, child.com)
+		for k := 0; k < 3; k++ {
+			child.field[k] += shifted[k]
+			for j := 0; j < 3; j++ {
+				child.gradient[k][j] += cell.gradient[k][j]
+			}
+		}
+		s.downwardPass(child)
+	}
+}
+
+// evaluateLocal evaluates the local expansion of a cell at a point
+func (s *fmmSolver) evaluateLocal(cell *fmmCell, point [3]float64) [3]float64 {
+	var offset [3]float64
+	for k := 0; k < 3; k++ {
+		offset[k] = point[k] - cell.com[k]
+	}
+
+	result := cell.field
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			result[i] += cell.gradient[i][j] * offset[j]
+		}
+	}
+	return result
+}
+
+// directWithin sums the interactions between all pairs of bodies in a leaf
+func (s *fmmSolver) directWithin(cell *fmmCell) {
+	for x, i := range cell.indices {
+		for _, j := range cell.indices[x+1:] {
+			s.directPair(i, j)
+		}
+	}
+}
+
+// directBetween sums the interactions between the bodies of two leaves
+func (s *fmmSolver) directBetween(a, b *fmmCell) {
+	for _, i := range a.indices {
+		for _, j := range b.indices {
+			s.directPair(i, j)
+		}
+	}
+}
+
+// directPair adds the mutual gravitational acceleration of two bodies
+func (s *fmmSolver) directPair(i, j int) {
+	var r [3]float64
+	r[0], r[1], r[2] = separate(s.separation, s.positions[i], s.positions[j])
+	distanceSquared := r[0]*r[0] + r[1]*r[1] + r[2]*r[2]
+
+	// Avoid division by zero or too large forces
+	if distanceSquared < 1e-10 {
+		return
+	}
+
+	inv3 := s.g / (distanceSquared * math.Sqrt(distanceSquared))
+	for k := 0; k < 3; k++ {
+		s.accelerations[i][k] += r[k] * inv3 * s.masses[j]
+		s.accelerations[j][k] -= r[k] * inv3 * s.masses[i]
+	}
+}
+
+// distance3 returns the distance between two points
+func distance3(a, b [3]float64) float64 {
+	dx := a[0] - b[0]
+	dy := a[1] - b[1]
+	dz := a[2] - b[2]
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
@@ -21,17 +21,68 @@ type Force interface {
 	IsGlobal() bool
 }
 
+// BodyFilter is implemented by forces that only act on some bodies (e.g. a field that only affects charged bodies).
+// The world skips the bodies for which AppliesTo returns false, both for global and pair forces.
+type BodyFilter interface {
+	// AppliesTo returns true if the force acts on the body
+	AppliesTo(b body.Body) bool
+}
+
+// GravitySolver selects the algorithm used to evaluate gravitational forces
+type GravitySolver int
+
+const (
+	// BarnesHutSolver approximates distant groups of bodies with their center of mass using the octree (O(n log n))
+	BarnesHutSolver GravitySolver = iota
+	// DirectSolver sums the exact contribution of every pair of bodies (O(n²))
+	DirectSolver
+	// FMMSolver uses the fast multipole method with cell-cell interactions (O(n))
+	FMMSolver
+	// PMSolver solves the Poisson equation with FFTs on a periodic grid covering the world bounds (O(n + N³ log N))
+	PMSolver
+	// TreePMSolver combines the PM long-range force with a short-range Barnes-Hut correction on the octree
+	TreePMSolver
+)
+
+// String returns the name of the solver
+func (s GravitySolver) String() string {
+	switch s {
+	case BarnesHutSolver:
+		return "barnes-hut"
+	case DirectSolver:
+		return "direct"
+	case FMMSolver:
+		return "fmm"
+	case PMSolver:
+		return "pm"
+	case TreePMSolver:
+		return "treepm"
+	default:
+		return "unknown"
+	}
+}
+
 // GravitationalForce implements gravitational force
 type GravitationalForce struct {
-	G     float64 // Gravitational constant
-	Theta float64 // Approximation parameter for the Barnes-Hut algorithm
+	G           float64        // Gravitational constant
+	Theta       float64        // Approximation parameter for the Barnes-Hut and FMM solvers
+	Solver      GravitySolver  // Algorithm used to evaluate the force on all bodies
+	Compensated bool           // Use compensated (Kahan) summation in the direct solver
+	GridSize    int            // Number of PM grid cells per axis (power of two)
+	Assignment  MassAssignment // Mass assignment scheme of the PM solver
+	SplitScale  float64        // TreePM force split scale, in PM grid cells
 }
 
 // NewGravitationalForce creates a new gravitational force
 func NewGravitationalForce() *GravitationalForce {
 	return &GravitationalForce{
-		G:     constants.G,
-		Theta: 0.5, // Default value that balances precision and efficiency
+		G:           constants.G,
+		Theta:       0.5, // Default value that balances precision and efficiency
+		Solver:      BarnesHutSolver,
+		Compensated: true,
+		GridSize:    64,
+		Assignment:  CloudInCell,
+		SplitScale:  1.25, // Value used by GADGET-2
 	}
 }
 
@@ -86,6 +137,57 @@ func (gf *GravitationalForce) GetTheta() float64 {
 	return gf.Theta
 }
 
+// SetSolver sets the algorithm used to evaluate the gravitational forces
+func (gf *GravitationalForce) SetSolver(solver GravitySolver) {
+	gf.Solver = solver
+}
+
+// GetSolver returns the algorithm used to evaluate the gravitational forces
+func (gf *GravitationalForce) GetSolver() GravitySolver {
+	return gf.Solver
+}
+
+// SetCompensated sets whether the direct solver uses compensated summation
+func (gf *GravitationalForce) SetCompensated(compensated bool) {
+	gf.Compensated = compensated
+}
+
+// IsCompensated returns true if the direct solver uses compensated summation
+func (gf *GravitationalForce) IsCompensated() bool {
+	return gf.Compensated
+}
+
+// SetGridSize sets the number of PM grid cells per axis, rounded up to a power of two (at least 2)
+func (gf *GravitationalForce) SetGridSize(gridSize int) {
+	gf.GridSize = PMGridSize(gridSize)
+}
+
+// GetGridSize returns the number of PM grid cells per axis, rounded up to a power of two
+// if the GridSize field was set directly to another value
+func (gf *GravitationalForce) GetGridSize() int {
+	return PMGridSize(gf.GridSize)
+}
+
+// SetMassAssignment sets the mass assignment scheme of the PM solver
+func (gf *GravitationalForce) SetMassAssignment(assignment MassAssignment) {
+	gf.Assignment = assignment
+}
+
+// GetMassAssignment returns the mass assignment scheme of the PM solver
+func (gf *GravitationalForce) GetMassAssignment() MassAssignment {
+	return gf.Assignment
+}
+
+// SetSplitScale sets the TreePM force split scale, in PM grid cells
+func (gf *GravitationalForce) SetSplitScale(splitScale float64) {
+	gf.SplitScale = splitScale
+}
+
+// GetSplitScale returns the TreePM force split scale, in PM grid cells
+func (gf *GravitationalForce) GetSplitScale() float64 {
+	return gf.SplitScale
+}
+
 // ConstantForce implements a constant force
 type ConstantForce struct {
 	force vector.Vector3
@@ -103,14 +205,14 @@ func (cf *ConstantForce) Apply(b body.Body) vector.Vector3 {
 	return cf.force
 }
 
-// ApplyBetween applies the constant force between two bodies (applies only to the first one)
+// ApplyBetween applies the constant force between two bodies (applies to both separately)
 func (cf *ConstantForce) ApplyBetween(a, b body.Body) (vector.Vector3, vector.Vector3) {
-	return cf.force, vector.Zero3()
+	return cf.force, cf.force
 }
 
-// IsGlobal returns false because constant force is not global
+// IsGlobal returns true because the constant force acts on each body independently
 func (cf *ConstantForce) IsGlobal() bool {
-	return false
+	return true
 }
 
 // SpringForce implements a spring force
@@ -0,0 +1,349 @@
+package force
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+)
+
+// MassAssignment selects how the PM solver spreads the mass of a body over the grid
+type MassAssignment int
+
+const (
+	// CloudInCell spreads the mass over the 2x2x2 nearest grid points (linear weights)
+	CloudInCell MassAssignment = iota
+	// TriangularShapedCloud spreads the mass over the 3x3x3 nearest grid points (quadratic weights)
+	TriangularShapedCloud
+)
+
+// String returns the name of the mass assignment scheme
+func (m MassAssignment) String() string {
+	switch m {
+	case CloudInCell:
+		return "cic"
+	case TriangularShapedCloud:
+		return "tsc"
+	default:
+		return "unknown"
+	}
+}
+
+// shortRangeCutoff is the distance, in units of the split radius, beyond which
+// the short-range TreePM force is neglected (erfc(2.25) ≈ 0.0015)
+const shortRangeCutoff = 4.5
+
+// PMSplitRadius returns the TreePM force split radius for a grid covering the bounds,
+// given the split scale in grid cells
+func PMSplitRadius(bounds *space.AABB, gridSize int, splitScale float64) float64 {
+	size := bounds.Size()
+	cell := math.Max(size.X(), math.Max(size.Y(), size.Z())) / float64(gridSize)
+	return splitScale * cell
+}
+
+// ShortRangeFactor returns the fraction of the Newtonian force between two bodies at the given
+// distance that is not accounted for by a PM solver filtered with the split radius
+func ShortRangeFactor(distance, splitRadius float64) float64 {
+	x := distance / (2 * splitRadius)
+	return math.Erfc(x) + 2*x/math.Sqrt(math.Pi)*math.Exp(-x*x)
+}
+
+// ShortRangeCutoff returns the distance beyond which the short-range TreePM force can be neglected
+func ShortRangeCutoff(splitRadius float64) float64 {
+	return shortRangeCutoff * splitRadius
+}
+
+// pmGrid describes the periodic mesh used by the PM solver
+type pmGrid struct {
+	n          int            // Number of cells per axis
+	min        [3]float64     // Origin of the grid
+	size       [3]float64     // Size of the periodic box
+	cell       [3]float64     // Size of a cell
+	assignment MassAssignment // Mass assignment scheme
+}
+
+// PMGridSize returns the smallest valid number of PM grid cells per axis not less than gridSize:
+// a power of two, at least 2
+func PMGridSize(gridSize int) int {
+	size := 2
+	for size < gridSize {
+		size *= 2
+	}
+	return size
+}
+
+// PMGravity calculates the gravitational force on each body with the particle-mesh method.
+// The masses are assigned to a gridSize³ mesh covering the bounds, which are treated as a periodic box,
+// the Poisson equation is solved with FFTs and the forces are interpolated back to the bodies with
+// the same assignment scheme (so that the self-force vanishes and momentum is conserved).
+// If splitRadius is positive, only the long-range part of the force is returned
+// (the Green's function is filtered with exp(-k²rs²)), see ShortRangeFactor for the complement.
+// gridSize must be a power of two, see PMGridSize. The returned slice is indexed like bodies.
+func PMGravity(bodies []body.Body, g float64, bounds *space.AABB, gridSize int, assignment MassAssignment, splitRadius float64) []vector.Vector3 {
+	if gridSize < 2 || gridSize&(gridSize-1) != 0 {
+		panic("PM grid size must be a power of two")
+	}
+
+	grid := &pmGrid{
+		n:          gridSize,
+		min:        bounds.Min.ToArray(),
+		size:       bounds.Size().ToArray(),
+		assignment: assignment,
+	}
+	for k := 0; k < 3; k++ {
+		grid.cell[k] = grid.size[k] / float64(gridSize)
+	}
+
+	// Deposit the mass density on the grid
+	positions := make([][3]float64, len(bodies))
+	masses := make([]float64, len(bodies))
+	for i, b := range bodies {
+		positions[i] = b.Position().ToArray()
+		masses[i] = units.ConvertToStandardUnit(b.Mass())
+	}
+	density := grid.deposit(positions, masses)
+
+	// Solve the Poisson equation in Fourier space
+	fft3(density, gridSize, false)
+	grid.applyGreensFunction(density, g, splitRadius)
+	fft3(density, gridSize, true)
+
+	potential := make([]float64, len(density))
+	for i, value := range density {
+		potential[i] = real(value)
+	}
+
+	// Differentiate the potential and interpolate the accelerations back to the bodies
+	var accelerations [3][]float64
+	for axis := 0; axis < 3; axis++ {
+		accelerations[axis] = grid.gradient(potential, axis)
+	}
+
+	forces := make([]vector.Vector3, len(bodies))
+	for i := range bodies {
+		a := grid.interpolate(accelerations, positions[i])
+		forces[i] = vector.NewVector3(a[0], a[1], a[2]).Scale(masses[i])
+	}
+	return forces
+}
+
+// index returns the position of a grid point in the flattened grid, wrapping periodically
+func (pg *pmGrid) index(i, j, k int) int {
+	n := pg.n
+	i = ((i % n) + n) % n
+	j = ((j % n) + n) % n
+	k = ((k % n) + n) % n
+	return (i*n+j)*n + k
+}
+
+// stencil returns the grid points (along one axis) that receive a share of a body and their weights
+func (pg *pmGrid) stencil(x float64, axis int) ([3]int, [3]float64, int) {
+	u := (x - pg.min[axis]) / pg.cell[axis]
+
+	switch pg.assignment {
+	case TriangularShapedCloud:
+		i := math.Round(u)
+		d := u - i
+		return [3]int{int(i) - 1, int(i), int(i) + 1},
+			[3]float64{0.5 * (0.5 - d) * (0.5 - d), 0.75 - d*d, 0.5 * (0.5 + d) * (0.5 + d)}, 3
+	default:
+		i := math.Floor(u)
+		d := u - i
+		return [3]int{int(i), int(i) + 1}, [3]float64{1 - d, d}, 2
+	}
+}
+
+// deposit assigns the masses to the grid and returns the mass density
+func (pg *pmGrid) deposit(positions [][3]float64, masses []float64) []complex128 {
+	density := make([]complex128, pg.n*pg.n*pg.n)
+	cellVolume := pg.cell[0] * pg.cell[1] * pg.cell[2]
+
+	for p, position := range positions {
+		ix, wx, nx := pg.stencil(position[0], 0)
+		iy, wy, ny := pg.stencil(position[1], 1)
+		iz, wz, nz := pg.stencil(position[2], 2)
+		rho := masses[p] / cellVolume
+
+		for a := 0; a < nx; a++ {
+			for b := 0; b < ny; b++ {
+				for c := 0; c < nz; c++ {
+					density[pg.index(ix[a], iy[b], iz[c])] += complex(rho*wx[a]*wy[b]*wz[c], 0)
+				}
+			}
+		}
+	}
+	return density
+}
+
+// interpolate returns the value of the acceleration grids at a point using the assignment weights
+func (pg *pmGrid) interpolate(accelerations [3][]float64, position [3]float64) [3]float64 {
+	ix, wx, nx := pg.stencil(position[0], 0)
+	iy, wy, ny := pg.stencil(position[1], 1)
+	iz, wz, nz := pg.stencil(position[2], 2)
+
+	var result [3]float64
+	for a := 0; a < nx; a++ {
+		for b := 0; b < ny; b++ {
+			for c := 0; c < nz; c++ {
+				weight := wx[a] * wy[b] * wz[c]
+				index := pg.index(ix[a], iy[b], iz[c])
+				for axis := 0; axis < 3; axis++ {
+					result[axis] += weight * accelerations[axis][index]
+				}
+			}
+		}
+	}
+	return result
+}
+
+// applyGreensFunction turns the Fourier transform of the density into the Fourier transform of the potential.
+// φ(k) = -4πG ρ(k) / k². For TreePM the Green's function is filtered with exp(-k²rs²) and deconvolved
+// with the assignment window (once for the deposit and once for the interpolation); without the filter
+// the deconvolution would amplify the modes close to the Nyquist frequency.
+func (pg *pmGrid) applyGreensFunction(grid []complex128, g, splitRadius float64) {
+	n := pg.n
+	// Order of the assignment window: W(k) = Π sinc(k h / 2)^order
+	order := 2.0
+	if pg.assignment == TriangularShapedCloud {
+		order = 3.0
+	}
+
+	for i := 0; i < n; i++ {
+		kx := pg.wavenumber(i, 0)
+		for j := 0; j < n; j++ {
+			ky := pg.wavenumber(j, 1)
+			for l := 0; l < n; l++ {
+				kz := pg.wavenumber(l, 2)
+				index := (i*n+j)*n + l
+
+				// The mean density does not produce any force in a periodic box
+				k2 := kx*kx + ky*ky + kz*kz
+				if k2 == 0 {
+					grid[index] = 0
+					continue
+				}
+
+				greens := -4 * math.Pi * g / k2
+				if splitRadius > 0 {
+					window := math.Pow(sinc(kx*pg.cell[0]/2)*sinc(ky*pg.cell[1]/2)*sinc(kz*pg.cell[2]/2), order)
+					greens *= math.Exp(-k2*splitRadius*splitRadius) / (window * window)
+				}
+
+				grid[index] *= complex(greens, 0)
+			}
+		}
+	}
+}
+
+// wavenumber returns the wavenumber of the i-th Fourier mode along an axis
+func (pg *pmGrid) wavenumber(i, axis int) float64 {
+	if i > pg.n/2 {
+		i -= pg.n
+	}
+	return 2 * math.Pi * float64(i) / pg.size[axis]
+}
+
+// gradient returns the acceleration -∂φ/∂axis on the grid using a four-point finite difference
+func (pg *pmGrid) gradient(potential []float64, axis int) []float64 {
+	n := pg.n
+	h := pg.cell[axis]
+	result := make([]float64, len(potential))
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			for k := 0; k < n; k++ {
+				at := func(shift int) float64 {
+					point := [3]int{i, j, k}
+					point[axis] += shift
+					return potential[pg.index(point[0], point[1], point[2])]
+				}
+
+				// ∂φ/∂x ≈ 4/3 (φ(x+h) - φ(x-h)) / 2h - 1/3 (φ(x+2h) - φ(x-2h)) / 4h
+				derivative := (4.0/3.0)*(at(1)-at(-1))/(2*h) - (1.0/3.0)*(at(2)-at(-2))/(4*h)
+				result[(i*n+j)*n+k] = -derivative
+			}
+		}
+	}
+	return result
+}
+
+// sinc returns sin(x)/x
+func sinc(x float64) float64 {
+	if math.Abs(x) < 1e-8 {
+		return 1
+	}
+	return math.Sin(x) / x
+}
+
+// fft3 computes the in-place 3D discrete Fourier transform of an n³ grid (n must be a power of two).
+// The inverse transform is normalized by 1/n³.
+func fft3(grid []complex128, n int, inverse bool) {
+	strides := [3]int{n * n, n, 1}
+	line := make([]complex128, n)
+
+	for axis := 0; axis < 3; axis++ {
+		stride := strides[axis]
+		other1 := strides[(axis+1)%3]
+		other2 := strides[(axis+2)%3]
+
+		for a := 0; a < n; a++ {
+			for b := 0; b < n; b++ {
+				base := a*other1 + b*other2
+				for i := 0; i < n; i++ {
+					line[i] = grid[base+i*stride]
+				}
+				fft(line, inverse)
+				for i := 0; i < n; i++ {
+					grid[base+i*stride] = line[i]
+				}
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1.0/float64(n*n*n), 0)
+		for i := range grid {
+			grid[i] *= scale
+		}
+	}
+}
+
+// fft computes the in-place unnormalized discrete Fourier transform of data (iterative radix-2 Cooley-Tukey)
+func fft(data []complex128, inverse bool) {
+	n := len(data)
+
+	// Bit reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+
+	// Butterflies
+	for length := 2; length <= n; length <<= 1 {
+		half := length / 2
+		angle := sign * 2 * math.Pi / float64(length)
+		for k := 0; k < half; k++ {
+			twiddle := cmplx.Rect(1, angle*float64(k))
+			for start := 0; start < n; start += length {
+				u := data[start+k]
+				v := data[start+k+half] * twiddle
+				data[start+k] = u + v
+				data[start+k+half] = u - v
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package force
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// PostNewtonianForce implements the first post-Newtonian (1PN) correction to gravity,
+// given by the Einstein-Infeld-Hoffmann equations in harmonic coordinates.
+// It only returns the correction: it must be added to the world alongside a GravitationalForce,
+// which provides the Newtonian part. The EIH equations are truncated to the two-body terms of
+// each pair (the cross terms involving a third body are neglected), which reproduces the
+// perihelion precession of a planet around a star.
+type PostNewtonianForce struct {
+	G float64 // Gravitational constant
+	C float64 // Speed of light
+}
+
+// NewPostNewtonianForce creates a new 1PN gravitational correction
+func NewPostNewtonianForce() *PostNewtonianForce {
+	return &PostNewtonianForce{
+		G: constants.G,
+		C: constants.SpeedOfLight,
+	}
+}
+
+// Apply applies the post-Newtonian correction to a body (does nothing for a single body)
+func (pn *PostNewtonianForce) Apply(b body.Body) vector.Vector3 {
+	// The correction requires two bodies to be applied
+	return vector.Zero3()
+}
+
+// ApplyBetween applies the post-Newtonian correction between two bodies
+func (pn *PostNewtonianForce) ApplyBetween(a, b body.Body) (vector.Vector3, vector.Vector3) {
+	massA := units.ConvertToStandardUnit(a.Mass())
+	massB := units.ConvertToStandardUnit(b.Mass())
+
+	forceOnA := pn.acceleration(a.Position(), a.Velocity(), massA, b.Position(), b.Velocity(), massB).Scale(massA)
+	forceOnB := pn.acceleration(b.Position(), b.Velocity(), massB, a.Position(), a.Velocity(), massA).Scale(massB)
+
+	return forceOnA, forceOnB
+}
+
+// acceleration returns the 1PN correction to the acceleration of body 1 due to body 2:
+//
+//	a1 = G m2 / (c² r²) * { n [-v1² - 2 v2² + 4 v1·v2 + 3/2 (n·v2)² + 5 G m1 / r + 4 G m2 / r]
+//	                        + (v1 - v2) [4 n·v1 - 3 n·v2] }
+//
+// where n is the unit vector from body 2 to body 1
+func (pn *PostNewtonianForce) acceleration(x1, v1 vector.Vector3, m1 float64, x2, v2 vector.Vector3, m2 float64) vector.Vector3 {
+	// Calculate the direction vector from body 2 to body 1
+	delta := x1.Sub(x2)
+	distanceSquared := delta.LengthSquared()
+
+	// Avoid division by zero or too large forces
+	if distanceSquared < 1e-10 {
+		return vector.Zero3()
+	}
+
+	distance := math.Sqrt(distanceSquared)
+	n := delta.Scale(1.0 / distance)
+
+	nv2 := n.Dot(v2)
+	radial := -v1.LengthSquared() - 2*v2.LengthSquared() + 4*v1.Dot(v2) + 1.5*nv2*nv2 +
+		5*pn.G*m1/distance + 4*pn.G*m2/distance
+	tangential := 4*n.Dot(v1) - 3*nv2
+
+	factor := pn.G * m2 / (pn.C * pn.C * distanceSquared)
+	return n.Scale(radial).Add(v1.Sub(v2).Scale(tangential)).Scale(factor)
+}
+
+// IsGlobal returns false because the correction is applied between pairs of bodies
+func (pn *PostNewtonianForce) IsGlobal() bool {
+	return false
+}
@@ -0,0 +1,71 @@
+package force
+
+import (
+	"math"
+	"sync"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// ThrusterForce implements a thruster that pushes the bodies it is attached to.
+// It is meant to be attached to a single spacecraft with World.AttachForce: added with
+// World.AddForce it pushes every body in the world.
+type ThrusterForce struct {
+	thrust   vector.Vector3 // Maximum thrust (N), in world coordinates
+	throttle float64        // Fraction of the maximum thrust (0-1)
+	mutex    sync.RWMutex
+}
+
+// NewThrusterForce creates a new thruster with the given maximum thrust and full throttle
+func NewThrusterForce(thrust vector.Vector3) *ThrusterForce {
+	return &ThrusterForce{
+		thrust:   thrust,
+		throttle: 1.0,
+	}
+}
+
+// SetThrust sets the maximum thrust of the thruster
+func (tf *ThrusterForce) SetThrust(thrust vector.Vector3) {
+	tf.mutex.Lock()
+	defer tf.mutex.Unlock()
+	tf.thrust = thrust
+}
+
+// GetThrust returns the maximum thrust of the thruster
+func (tf *ThrusterForce) GetThrust() vector.Vector3 {
+	tf.mutex.RLock()
+	defer tf.mutex.RUnlock()
+	return tf.thrust
+}
+
+// SetThrottle sets the fraction of the maximum thrust, clamped between 0 and 1
+func (tf *ThrusterForce) SetThrottle(throttle float64) {
+	tf.mutex.Lock()
+	defer tf.mutex.Unlock()
+	tf.throttle = math.Max(0, math.Min(1, throttle))
+}
+
+// GetThrottle returns the fraction of the maximum thrust
+func (tf *ThrusterForce) GetThrottle() float64 {
+	tf.mutex.RLock()
+	defer tf.mutex.RUnlock()
+	return tf.throttle
+}
+
+// Apply applies the thrust to a body
+func (tf *ThrusterForce) Apply(b body.Body) vector.Vector3 {
+	tf.mutex.RLock()
+	defer tf.mutex.RUnlock()
+	return tf.thrust.Scale(tf.throttle)
+}
+
+// ApplyBetween applies the thrust between two bodies (applies to both separately)
+func (tf *ThrusterForce) ApplyBetween(a, b body.Body) (vector.Vector3, vector.Vector3) {
+	return tf.Apply(a), tf.Apply(b)
+}
+
+// IsGlobal returns true because the thrust acts on each target body independently
+func (tf *ThrusterForce) IsGlobal() bool {
+	return true
+}
@@ -0,0 +1,147 @@
+// Package frame provides reference frames defined by the state of the bodies of a world
+package frame
+
+import (
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// Frame is a reference frame defined by the current state of some bodies.
+// The positions and velocities of the world are expressed in a single inertial frame.
+type Frame interface {
+	// ToFrame converts a position and a velocity from the inertial frame of the world to this frame
+	ToFrame(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3)
+	// FromFrame converts a position and a velocity from this frame to the inertial frame of the world
+	FromFrame(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3)
+}
+
+// StateIn returns the position and the velocity of a body in a frame
+func StateIn(f Frame, b body.Body) (vector.Vector3, vector.Vector3) {
+	return f.ToFrame(b.Position(), b.Velocity())
+}
+
+// Barycenter returns the position and the velocity of the center of mass of some bodies
+func Barycenter(bodies []body.Body) (vector.Vector3, vector.Vector3) {
+	position, velocity := vector.Zero3(), vector.Zero3()
+	totalMass := 0.0
+	for _, b := range bodies {
+		mass := units.ConvertToStandardUnit(b.Mass())
+		position = position.Add(b.Position().Scale(mass))
+		velocity = velocity.Add(b.Velocity().Scale(mass))
+		totalMass += mass
+	}
+	if totalMass == 0 {
+		return vector.Zero3(), vector.Zero3()
+	}
+	return position.Scale(1 / totalMass), velocity.Scale(1 / totalMass)
+}
+
+// ShiftToBarycenter moves some bodies so that their center of mass is at rest at the origin
+func ShiftToBarycenter(bodies []body.Body) {
+	position, velocity := Barycenter(bodies)
+	for _, b := range bodies {
+		b.SetPosition(b.Position().Sub(position))
+		b.SetVelocity(b.Velocity().Sub(velocity))
+	}
+}
+
+// translation is a non-rotating frame with a moving origin
+type translation struct {
+	origin func() (vector.Vector3, vector.Vector3)
+}
+
+// ToFrame converts a position and a velocity from the inertial frame of the world to this frame
+func (t translation) ToFrame(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3) {
+	originPosition, originVelocity := t.origin()
+	return position.Sub(originPosition), velocity.Sub(originVelocity)
+}
+
+// FromFrame converts a position and a velocity from this frame to the inertial frame of the world
+func (t translation) FromFrame(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3) {
+	originPosition, originVelocity := t.origin()
+	return position.Add(originPosition), velocity.Add(originVelocity)
+}
+
+// NewBodyFrame returns the non-rotating frame centered on a body
+func NewBodyFrame(b body.Body) Frame {
+	return translation{origin: func() (vector.Vector3, vector.Vector3) {
+		return b.Position(), b.Velocity()
+	}}
+}
+
+// NewBarycentricFrame returns the non-rotating frame centered on the center of mass of some bodies
+func NewBarycentricFrame(bodies ...body.Body) Frame {
+	return translation{origin: func() (vector.Vector3, vector.Vector3) {
+		return Barycenter(bodies)
+	}}
+}
+
+// RotatingFrame is the frame co-rotating with a secondary body around a primary body (e.g. the Sun-Earth
+// synodic frame). The origin is their center of mass, the X axis points from the primary to the secondary
+// and the Z axis along their orbital angular momentum.
+type RotatingFrame struct {
+	primary   body.Body
+	secondary body.Body
+}
+
+// NewRotatingFrame creates the frame co-rotating with a secondary body around a primary body
+func NewRotatingFrame(primary, secondary body.Body) *RotatingFrame {
+	return &RotatingFrame{primary: primary, secondary: secondary}
+}
+
+// GetPrimary returns the primary body
+func (rf *RotatingFrame) GetPrimary() body.Body {
+	return rf.primary
+}
+
+// GetSecondary returns the secondary body
+func (rf *RotatingFrame) GetSecondary() body.Body {
+	return rf.secondary
+}
+
+// Axes returns the axes of the frame in the inertial frame of the world
+func (rf *RotatingFrame) Axes() (vector.Vector3, vector.Vector3, vector.Vector3) {
+	r := rf.secondary.Position().Sub(rf.primary.Position())
+	v := rf.secondary.Velocity().Sub(rf.primary.Velocity())
+	x := r.Normalize()
+	h := r.Cross(v)
+	if h.Length() == 0 {
+		// Radial motion: any axis perpendicular to X
+		h = x.Cross(vector.NewVector3(0, 0, 1))
+		if h.Length() == 0 {
+			h = x.Cross(vector.NewVector3(0, 1, 0))
+		}
+	}
+	z := h.Normalize()
+	return x, z.Cross(x), z
+}
+
+// AngularVelocity returns the angular velocity of the frame (rad/s) in the inertial frame of the world
+func (rf *RotatingFrame) AngularVelocity() vector.Vector3 {
+	r := rf.secondary.Position().Sub(rf.primary.Position())
+	v := rf.secondary.Velocity().Sub(rf.primary.Velocity())
+	distanceSquared := r.LengthSquared()
+	if distanceSquared == 0 {
+		return vector.Zero3()
+	}
+	return r.Cross(v).Scale(1 / distanceSquared)
+}
+
+// ToFrame converts a position and a velocity from the inertial frame of the world to this frame
+func (rf *RotatingFrame) ToFrame(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3) {
+	originPosition, originVelocity := Barycenter([]body.Body{rf.primary, rf.secondary})
+	x, y, z := rf.Axes()
+	d := position.Sub(originPosition)
+	v := velocity.Sub(originVelocity).Sub(rf.AngularVelocity().Cross(d))
+	return vector.NewVector3(d.Dot(x), d.Dot(y), d.Dot(z)), vector.NewVector3(v.Dot(x), v.Dot(y), v.Dot(z))
+}
+
+// FromFrame converts a position and a velocity from this frame to the inertial frame of the world
+func (rf *RotatingFrame) FromFrame(position, velocity vector.Vector3) (vector.Vector3, vector.Vector3) {
+	originPosition, originVelocity := Barycenter([]body.Body{rf.primary, rf.secondary})
+	x, y, z := rf.Axes()
+	d := x.Scale(position.X()).Add(y.Scale(position.Y())).Add(z.Scale(position.Z()))
+	v := x.Scale(velocity.X()).Add(y.Scale(velocity.Y())).Add(z.Scale(velocity.Z()))
+	return originPosition.Add(d), originVelocity.Add(v).Add(rf.AngularVelocity().Cross(d))
+}
@@ -0,0 +1,193 @@
+// Package guidance provides an autopilot that flies controllable bodies without user input
+package guidance
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// Command is the output of a guidance mode for one step
+type Command struct {
+	// Attitude is the direction the body should point to (nil or zero to leave the attitude free)
+	Attitude vector.Vector3
+	// Thrust is the direction of the thrust (nil or zero for no thrust).
+	// When an attitude is also given, the thrust is only applied once the body points along it (main engine),
+	// otherwise it is applied in any direction (translation thrusters).
+	Thrust vector.Vector3
+	// Throttle is the fraction of the maximum thrust, between 0 and 1
+	Throttle float64
+}
+
+// Mode is a guidance mode of the autopilot
+type Mode interface {
+	// Command returns the command for a body at time t, for a step of duration dt
+	Command(b body.ControllableBody, t, dt float64) Command
+}
+
+// ThrustObserver is implemented by the modes that need to know the thrust actually applied
+// (e.g. to measure the delta-v of a burn like an accelerometer)
+type ThrustObserver interface {
+	// ThrustApplied is called with the acceleration given by the thrust during a step of duration dt
+	ThrustApplied(acceleration vector.Vector3, dt float64)
+}
+
+// PID is a proportional-integral-derivative controller on a vector error
+type PID struct {
+	Kp, Ki, Kd float64
+
+	integral    vector.Vector3
+	previous    vector.Vector3
+	initialized bool
+}
+
+// NewPID creates a new PID controller with the given gains
+func NewPID(kp, ki, kd float64) *PID {
+	return &PID{
+		Kp:       kp,
+		Ki:       ki,
+		Kd:       kd,
+		integral: vector.Zero3(),
+		previous: vector.Zero3(),
+	}
+}
+
+// Update returns the control output for an error over a step of duration dt
+func (pid *PID) Update(err vector.Vector3, dt float64) vector.Vector3 {
+	if dt <= 0 {
+		return err.Scale(pid.Kp)
+	}
+
+	derivative := vector.Zero3()
+	if pid.initialized {
+		derivative = err.Sub(pid.previous).Scale(1.0 / dt)
+	}
+	pid.integral = pid.integral.Add(err.Scale(dt))
+	pid.previous = err
+	pid.initialized = true
+
+	return err.Scale(pid.Kp).Add(pid.integral.Scale(pid.Ki)).Add(derivative.Scale(pid.Kd))
+}
+
+// Reset clears the integral and derivative terms
+func (pid *PID) Reset() {
+	pid.integral = vector.Zero3()
+	pid.previous = vector.Zero3()
+	pid.initialized = false
+}
+
+// Autopilot flies a controllable body with a guidance mode.
+// The attitude is controlled by a PID on the pointing error, whose output is the angular acceleration of the body
+// (like reaction wheels), up to a maximum angular acceleration. It is applied through the angular acceleration
+// of the body, so that the world integrates it with the torques of the step (through the inertia tensor of the
+// bodies that have one).
+type Autopilot struct {
+	body                   body.ControllableBody
+	mode                   Mode
+	attitude               *PID
+	maxAngularAcceleration float64 // Maximum angular acceleration of the attitude control (rad/s²)
+	alignmentTolerance     float64 // Maximum pointing error to fire the main engine (rad)
+}
+
+// NewAutopilot creates a new autopilot for a body, without a guidance mode
+func NewAutopilot(b body.ControllableBody) *Autopilot {
+	return &Autopilot{
+		body:                   b,
+		attitude:               NewPID(4, 0, 4),
+		maxAngularAcceleration: 1,
+		alignmentTolerance:     math.Pi / 180,
+	}
+}
+
+// SetMode sets the guidance mode (nil to disengage the autopilot)
+func (ap *Autopilot) SetMode(mode Mode) {
+	ap.mode = mode
+	ap.attitude.Reset()
+}
+
+// GetMode returns the guidance mode
+func (ap *Autopilot) GetMode() Mode {
+	return ap.mode
+}
+
+// SetAttitudeController sets the PID controller of the attitude
+func (ap *Autopilot) SetAttitudeController(pid *PID) {
+	ap.attitude = pid
+}
+
+// GetAttitudeController returns the PID controller of the attitude
+func (ap *Autopilot) GetAttitudeController() *PID {
+	return ap.attitude
+}
+
+// SetMaxAngularAcceleration sets the maximum angular acceleration of the attitude control (rad/s²)
+func (ap *Autopilot) SetMaxAngularAcceleration(acceleration float64) {
+	ap.maxAngularAcceleration = acceleration
+}
+
+// GetMaxAngularAcceleration returns the maximum angular acceleration of the attitude control (rad/s²)
+func (ap *Autopilot) GetMaxAngularAcceleration() float64 {
+	return ap.maxAngularAcceleration
+}
+
+// SetAlignmentTolerance sets the maximum pointing error to fire the main engine (rad)
+func (ap *Autopilot) SetAlignmentTolerance(tolerance float64) {
+	ap.alignmentTolerance = tolerance
+}
+
+// GetAlignmentTolerance returns the maximum pointing error to fire the main engine (rad)
+func (ap *Autopilot) GetAlignmentTolerance() float64 {
+	return ap.alignmentTolerance
+}
+
+// Update flies the body for a step of duration dt at time t.
+// It must be called before each step of the world, as it applies the thrust and the attitude control to the body.
+func (ap *Autopilot) Update(t, dt float64) {
+	if ap.mode == nil || !ap.body.IsControllable() {
+		return
+	}
+	command := ap.mode.Command(ap.body, t, dt)
+
+	aligned := true
+	if command.Attitude != nil && command.Attitude.Length() > 0 {
+		aligned = ap.point(command.Attitude, dt) <= ap.alignmentTolerance
+	}
+
+	if command.Thrust == nil || command.Thrust.Length() == 0 || command.Throttle <= 0 || !aligned {
+		return
+	}
+	mass := units.ConvertToStandardUnit(ap.body.Mass())
+	thrust := ap.body.Thrust(command.Thrust, command.Throttle, dt)
+	if observer, ok := ap.mode.(ThrustObserver); ok && mass > 0 {
+		observer.ThrustApplied(thrust.Scale(1.0/mass), dt)
+	}
+}
+
+// point turns the body toward a direction and returns the pointing error (rad)
+func (ap *Autopilot) point(direction vector.Vector3, dt float64) float64 {
+	forward := ap.body.Forward()
+	target := direction.Normalize()
+
+	// Rotation vector from the current to the target direction
+	axis := forward.Cross(target)
+	angle := math.Atan2(axis.Length(), forward.Dot(target))
+	if axis.Length() > 1e-12 {
+		axis = axis.Normalize()
+	} else if angle > math.Pi/2 {
+		// Pointing the opposite way: turn around any perpendicular axis
+		axis = forward.Cross(vector.NewVector3(1, 0, 0))
+		if axis.Length() < 1e-6 {
+			axis = forward.Cross(vector.NewVector3(0, 1, 0))
+		}
+		axis = axis.Normalize()
+	}
+
+	acceleration := ap.attitude.Update(axis.Scale(angle), dt)
+	if magnitude := acceleration.Length(); magnitude > ap.maxAngularAcceleration {
+		acceleration = acceleration.Scale(ap.maxAngularAcceleration / magnitude)
+	}
+	ap.body.SetAngularAcceleration(ap.body.AngularAcceleration().Add(acceleration))
+	return angle
+}
@@ -0,0 +1,257 @@
+package guidance
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// HoldAttitude points the body along a fixed direction
+type HoldAttitude struct {
+	direction vector.Vector3
+}
+
+// NewHoldAttitude creates a mode that holds the attitude along a direction in world coordinates
+func NewHoldAttitude(direction vector.Vector3) *HoldAttitude {
+	return &HoldAttitude{direction: direction.Normalize()}
+}
+
+// GetDirection returns the direction to point to
+func (h *HoldAttitude) GetDirection() vector.Vector3 {
+	return h.direction
+}
+
+// Command points the body along the direction, without thrust
+func (h *HoldAttitude) Command(b body.ControllableBody, t, dt float64) Command {
+	return Command{Attitude: h.direction}
+}
+
+// Direction is a direction relative to the orbit around a reference body
+type Direction int
+
+const (
+	// Prograde is along the velocity relative to the reference body
+	Prograde Direction = iota
+	// Retrograde is against the velocity relative to the reference body
+	Retrograde
+	// Normal is along the angular momentum of the orbit (r × v)
+	Normal
+	// AntiNormal is against the angular momentum of the orbit
+	AntiNormal
+	// RadialOut is away from the reference body
+	RadialOut
+	// RadialIn is toward the reference body
+	RadialIn
+)
+
+// String returns the name of the direction
+func (d Direction) String() string {
+	switch d {
+	case Prograde:
+		return "Prograde"
+	case Retrograde:
+		return "Retrograde"
+	case Normal:
+		return "Normal"
+	case AntiNormal:
+		return "AntiNormal"
+	case RadialOut:
+		return "RadialOut"
+	case RadialIn:
+		return "RadialIn"
+	default:
+		return "Unknown"
+	}
+}
+
+// Vector returns the direction for a body relative to a reference body (zero if it is undefined)
+func (d Direction) Vector(b, reference body.Body) vector.Vector3 {
+	position := b.Position().Sub(reference.Position())
+	velocity := b.Velocity().Sub(reference.Velocity())
+
+	var direction vector.Vector3
+	switch d {
+	case Prograde:
+		direction = velocity
+	case Retrograde:
+		direction = velocity.Scale(-1)
+	case Normal:
+		direction = position.Cross(velocity)
+	case AntiNormal:
+		direction = velocity.Cross(position)
+	case RadialOut:
+		direction = position
+	case RadialIn:
+		direction = position.Scale(-1)
+	default:
+		return vector.Zero3()
+	}
+
+	if direction.Length() < 1e-12 {
+		return vector.Zero3()
+	}
+	return direction.Normalize()
+}
+
+// PointRelative points the body along a direction relative to its orbit around a reference body
+type PointRelative struct {
+	reference body.Body
+	direction Direction
+}
+
+// NewPointRelative creates a mode that points the body prograde, retrograde, normal, etc. relative to a reference body
+func NewPointRelative(reference body.Body, direction Direction) *PointRelative {
+	return &PointRelative{
+		reference: reference,
+		direction: direction,
+	}
+}
+
+// GetReference returns the reference body
+func (p *PointRelative) GetReference() body.Body {
+	return p.reference
+}
+
+// GetDirection returns the direction relative to the orbit
+func (p *PointRelative) GetDirection() Direction {
+	return p.direction
+}
+
+// Command points the body along the relative direction, without thrust
+func (p *PointRelative) Command(b body.ControllableBody, t, dt float64) Command {
+	return Command{Attitude: p.direction.Vector(b, p.reference)}
+}
+
+// PlannedBurn executes a burn of a given delta-v, starting at a given time.
+// The body turns toward the burn direction in advance and fires its main engine from the start time
+// until the thrust has delivered the delta-v.
+type PlannedBurn struct {
+	startTime float64        // Start of the burn (s)
+	deltaV    vector.Vector3 // Velocity change to deliver (m/s)
+	delivered vector.Vector3 // Velocity change delivered by the thrust so far (m/s)
+}
+
+// NewPlannedBurn creates a burn of a delta-v vector (in world coordinates) starting at a time
+func NewPlannedBurn(startTime units.Quantity, deltaV vector.Vector3) *PlannedBurn {
+	if startTime.Unit().Type() != units.Time {
+		panic("Start time must be a time quantity")
+	}
+	return &PlannedBurn{
+		startTime: units.ConvertToStandardUnit(startTime),
+		deltaV:    deltaV,
+		delivered: vector.Zero3(),
+	}
+}
+
+// GetStartTime returns the start of the burn
+func (pb *PlannedBurn) GetStartTime() units.Quantity {
+	return units.NewQuantity(pb.startTime, units.Second)
+}
+
+// GetDeltaV returns the velocity change to deliver
+func (pb *PlannedBurn) GetDeltaV() vector.Vector3 {
+	return pb.deltaV
+}
+
+// Remaining returns the velocity change still to deliver (m/s)
+func (pb *PlannedBurn) Remaining() float64 {
+	return math.Max(0, pb.deltaV.Length()-pb.delivered.Dot(pb.deltaV.Normalize()))
+}
+
+// IsComplete returns true if the burn has delivered its delta-v
+func (pb *PlannedBurn) IsComplete() bool {
+	return pb.Remaining() <= 1e-9
+}
+
+// Command points the body along the burn and fires the engine once the start time is reached.
+// The throttle is reduced in the last step to deliver the exact delta-v.
+func (pb *PlannedBurn) Command(b body.ControllableBody, t, dt float64) Command {
+	if pb.deltaV.Length() == 0 {
+		return Command{}
+	}
+	direction := pb.deltaV.Normalize()
+	command := Command{Attitude: direction}
+	if t < pb.startTime || pb.IsComplete() {
+		return command
+	}
+
+	throttle := 1.0
+	if mass := units.ConvertToStandardUnit(b.Mass()); mass > 0 {
+		if step := units.ConvertToStandardUnit(b.MaxThrust()) / mass * dt; step > 0 {
+			throttle = math.Min(1, pb.Remaining()/step)
+		}
+	}
+	command.Thrust = direction
+	command.Throttle = throttle
+	return command
+}
+
+// ThrustApplied accumulates the velocity change delivered by the thrust
+func (pb *PlannedBurn) ThrustApplied(acceleration vector.Vector3, dt float64) {
+	pb.delivered = pb.delivered.Add(acceleration.Scale(dt))
+}
+
+// StationKeeping keeps the body at a position, optionally relative to a reference body, with its translation
+// thrusters driven by a PD controller: a = ω² (target - x) - 2ζω (v - vref)
+type StationKeeping struct {
+	target    vector.Vector3 // Position to keep (relative to the reference body if any)
+	reference body.Body      // Reference body (nil for world coordinates)
+	frequency float64        // Natural frequency of the controller (rad/s)
+	damping   float64        // Damping ratio of the controller
+}
+
+// NewStationKeeping creates a mode that keeps the body at a position in world coordinates,
+// or relative to a reference body if it is not nil
+func NewStationKeeping(target vector.Vector3, reference body.Body) *StationKeeping {
+	return &StationKeeping{
+		target:    target,
+		reference: reference,
+		frequency: 0.5,
+		damping:   1,
+	}
+}
+
+// SetResponse sets the natural frequency (rad/s) and damping ratio of the controller
+func (sk *StationKeeping) SetResponse(frequency, damping float64) {
+	sk.frequency = frequency
+	sk.damping = damping
+}
+
+// GetTarget returns the position to keep
+func (sk *StationKeeping) GetTarget() vector.Vector3 {
+	return sk.target
+}
+
+// Error returns the displacement of the body from the position to keep
+func (sk *StationKeeping) Error(b body.Body) vector.Vector3 {
+	position := b.Position()
+	if sk.reference != nil {
+		position = position.Sub(sk.reference.Position())
+	}
+	return position.Sub(sk.target)
+}
+
+// Command fires the translation thrusters toward the position to keep
+func (sk *StationKeeping) Command(b body.ControllableBody, t, dt float64) Command {
+	velocity := b.Velocity()
+	if sk.reference != nil {
+		velocity = velocity.Sub(sk.reference.Velocity())
+	}
+
+	acceleration := sk.Error(b).Scale(-sk.frequency * sk.frequency).Sub(velocity.Scale(2 * sk.damping * sk.frequency))
+	mass := units.ConvertToStandardUnit(b.Mass())
+	if acceleration.Length() < 1e-12 || mass <= 0 {
+		return Command{}
+	}
+	maxAcceleration := units.ConvertToStandardUnit(b.MaxThrust()) / mass
+	if maxAcceleration <= 0 {
+		return Command{}
+	}
+
+	return Command{
+		Thrust:   acceleration,
+		Throttle: math.Min(1, acceleration.Length()/maxAcceleration),
+	}
+}
@@ -63,10 +63,17 @@ func (ei *EulerIntegrator) IntegrateAll(bodies []body.Body, dt float64, taskSubm
 	taskSubmitter.Wait()
 }
 
+// verletState stores the state of a body between Verlet steps
+type verletState struct {
+	previous [3]float64 // Position at the previous step
+	position [3]float64 // Position produced by the last step
+	velocity [3]float64 // Velocity produced by the last step
+}
+
 // VerletIntegrator implements the Verlet integrator
 type VerletIntegrator struct {
-	// Map that stores the previous positions of bodies
-	previousPositions map[uuid.UUID]vector.Vector3
+	// Map that stores the state of bodies between steps
+	states map[uuid.UUID]verletState
 	// Mutex to protect access to the map
 	mutex sync.RWMutex
 }
@@ -74,8 +81,8 @@ type VerletIntegrator struct {
 // NewVerletIntegrator creates a new Verlet integrator
 func NewVerletIntegrator() *VerletIntegrator {
 	return &VerletIntegrator{
-		previousPositions: make(map[uuid.UUID]vector.Vector3),
-		mutex:             sync.RWMutex{},
+		states: make(map[uuid.UUID]verletState),
+		mutex:  sync.RWMutex{},
 	}
 }
 
@@ -89,18 +96,20 @@ func (vi *VerletIntegrator) Integrate(b body.Body, dt float64) {
 	// Get the current position
 	currentPosition := b.Position()
 
-	// Check if there is a previous position for this body
+	// Check if there is a previous state for this body
 	vi.mutex.RLock()
-	previousPosition, exists := vi.previousPositions[b.ID()]
+	state, exists := vi.states[b.ID()]
 	vi.mutex.RUnlock()
 
-	if !exists {
-		// If there is no previous position, use the Euler integrator for the first step
+	// The position history is only valid if the body was not moved or accelerated
+	// outside the integrator (collisions, periodic wrapping, SetPosition/SetVelocity)
+	var previousPosition vector.Vector3
+	if exists && state.position == currentPosition.ToArray() && state.velocity == b.Velocity().ToArray() {
+		previousPosition = vector.NewVector3(state.previous[0], state.previous[1], state.previous[2])
+	} else {
+		// Otherwise use the Euler integrator to estimate the previous position
 		// x(t-dt) = x(t) - v(t)*dt + 0.5*a(t)*dt^2
 		previousPosition = currentPosition.Sub(b.Velocity().Scale(dt)).Add(b.Acceleration().Scale(0.5 * dt * dt))
-		vi.mutex.Lock()
-		vi.previousPositions[b.ID()] = previousPosition
-		vi.mutex.Unlock()
 	}
 
 	// Calculate the new position using the Verlet algorithm
@@ -111,15 +120,19 @@ func (vi *VerletIntegrator) Integrate(b body.Body, dt float64) {
 	// v(t+dt) = (x(t+dt) - x(t-dt)) / (2*dt)
 	newVelocity := newPosition.Sub(previousPosition).Scale(1.0 / (2.0 * dt))
 
-	// Update the previous position for the next step
-	vi.mutex.Lock()
-	vi.previousPositions[b.ID()] = currentPosition
-	vi.mutex.Unlock()
-
 	// Update the position and velocity of the body
 	b.SetPosition(newPosition)
 	b.SetVelocity(newVelocity)
 
+	// Store the state for the next step
+	vi.mutex.Lock()
+	vi.states[b.ID()] = verletState{
+		previous: currentPosition.ToArray(),
+		position: b.Position().ToArray(),
+		velocity: b.Velocity().ToArray(),
+	}
+	vi.mutex.Unlock()
+
 	// Reset acceleration (will be recalculated in the next cycle)
 	b.SetAcceleration(vector.Zero3())
 }
@@ -207,3 +220,46 @@ func (rk *RK4Integrator) IntegrateAll(bodies []body.Body, dt float64, taskSubmit
 	}
 	taskSubmitter.Wait()
 }
+
+// RelativisticIntegrator implements a special-relativistic integrator.
+// The state of each body is its momentum p = γmv: forces change the momentum (dp/dt = F)
+// and the velocity is derived from it, so the speed of a body never reaches the speed of light.
+type RelativisticIntegrator struct{}
+
+// NewRelativisticIntegrator creates a new special-relativistic integrator
+func NewRelativisticIntegrator() *RelativisticIntegrator {
+	return &RelativisticIntegrator{}
+}
+
+// Integrate integrates the equations of motion for a body using relativistic momentum
+func (ri *RelativisticIntegrator) Integrate(b body.Body, dt float64) {
+	// If the body is static, do nothing
+	if b.IsStatic() {
+		return
+	}
+
+	// Update the momentum per unit of rest mass: u(t+dt) = γv(t) + F/m*dt
+	// (the acceleration accumulated by the body is F/m)
+	properVelocity := body.ProperVelocity(b.Velocity()).Add(b.Acceleration().Scale(dt))
+
+	// Derive the velocity: v = u / sqrt(1 + u²/c²)
+	newVelocity := body.VelocityFromProperVelocity(properVelocity)
+
+	// Update the position with the new velocity: x(t+dt) = x(t) + v(t+dt)*dt
+	b.SetPosition(b.Position().Add(newVelocity.Scale(dt)))
+	b.SetVelocity(newVelocity)
+
+	// Reset acceleration (will be recalculated in the next cycle)
+	b.SetAcceleration(vector.Zero3())
+}
+
+// IntegrateAll integrates the equations of motion for all bodies in parallel using relativistic momentum
+func (ri *RelativisticIntegrator) IntegrateAll(bodies []body.Body, dt float64, taskSubmitter TaskSubmitter) {
+	for _, b := range bodies {
+		b := b // Capture the variable for the goroutine
+		taskSubmitter.Submit(func() {
+			ri.Integrate(b, dt)
+		})
+	}
+	taskSubmitter.Wait()
+}
@@ -5,6 +5,12 @@ import (
 	"github.com/alexanderi96/go-space-engine/core/units"
 )
 
+// zeroTemperature and zeroLatentHeat are the phase change properties of materials that never change phase
+var (
+	zeroTemperature = units.NewQuantity(0.0, units.Kelvin)
+	zeroLatentHeat  = units.NewQuantity(0.0, units.Joule)
+)
+
 // Material represents the physical properties of a material
 type Material interface {
 	// Name returns the name of the material
@@ -27,6 +33,24 @@ type Material interface {
 
 	// Color returns the color of the material as RGB
 	Color() [3]float64
+
+	// MeltingPoint returns the melting point of the material (zero if it does not melt)
+	MeltingPoint() units.Quantity
+
+	// LatentHeatOfFusion returns the heat per unit mass needed to melt the material
+	LatentHeatOfFusion() units.Quantity
+
+	// BoilingPoint returns the boiling point of the material (zero if it does not vaporize)
+	BoilingPoint() units.Quantity
+
+	// LatentHeatOfVaporization returns the heat per unit mass needed to vaporize the material
+	LatentHeatOfVaporization() units.Quantity
+
+	// LiquidPhase returns the material the solid turns into when it melts (nil if it has none)
+	LiquidPhase() Material
+
+	// SolidPhase returns the material the liquid turns into when it freezes (nil if it has none)
+	SolidPhase() Material
 }
 
 // BasicMaterial implements a basic material
@@ -38,6 +62,12 @@ type BasicMaterial struct {
 	emissivity          float64
 	elasticity          float64
 	color               [3]float64
+	meltingPoint        units.Quantity
+	latentHeatOfFusion  units.Quantity
+	boilingPoint        units.Quantity
+	latentHeatOfVapor   units.Quantity
+	liquid              *BasicMaterial // Phase after melting
+	solid               *BasicMaterial // Phase after freezing
 }
 
 // NewBasicMaterial creates a new basic material
@@ -58,7 +88,46 @@ func NewBasicMaterial(
 		emissivity:          emissivity,
 		elasticity:          elasticity,
 		color:               color,
+		meltingPoint:        zeroTemperature,
+		latentHeatOfFusion:  zeroLatentHeat,
+		boilingPoint:        zeroTemperature,
+		latentHeatOfVapor:   zeroLatentHeat,
+	}
+}
+
+// WithMelting sets the melting point and the latent heat of fusion (J/kg) of a solid material
+// and links it to the liquid it melts into. The liquid freezes back into this material at the same temperature.
+func (m *BasicMaterial) WithMelting(meltingPoint units.Quantity, latentHeat units.Quantity, liquid *BasicMaterial) *BasicMaterial {
+	if meltingPoint.Unit().Type() != units.Temperature {
+		panic("Melting point must be a temperature quantity")
+	}
+	if latentHeat.Unit().Type() != units.Energy {
+		panic("Latent heat must be an energy quantity")
+	}
+
+	m.meltingPoint = meltingPoint
+	m.latentHeatOfFusion = latentHeat
+	m.liquid = liquid
+	if liquid != nil {
+		liquid.meltingPoint = meltingPoint
+		liquid.latentHeatOfFusion = latentHeat
+		liquid.solid = m
+	}
+	return m
+}
+
+// WithVaporization sets the boiling point and the latent heat of vaporization (J/kg) of the material
+func (m *BasicMaterial) WithVaporization(boilingPoint units.Quantity, latentHeat units.Quantity) *BasicMaterial {
+	if boilingPoint.Unit().Type() != units.Temperature {
+		panic("Boiling point must be a temperature quantity")
+	}
+	if latentHeat.Unit().Type() != units.Energy {
+		panic("Latent heat must be an energy quantity")
 	}
+
+	m.boilingPoint = boilingPoint
+	m.latentHeatOfVapor = latentHeat
+	return m
 }
 
 // Name returns the name of the material
@@ -96,6 +165,42 @@ func (m *BasicMaterial) Color() [3]float64 {
 	return m.color
 }
 
+// MeltingPoint returns the melting point of the material
+func (m *BasicMaterial) MeltingPoint() units.Quantity {
+	return m.meltingPoint
+}
+
+// LatentHeatOfFusion returns the latent heat of fusion of the material
+func (m *BasicMaterial) LatentHeatOfFusion() units.Quantity {
+	return m.latentHeatOfFusion
+}
+
+// BoilingPoint returns the boiling point of the material
+func (m *BasicMaterial) BoilingPoint() units.Quantity {
+	return m.boilingPoint
+}
+
+// LatentHeatOfVaporization returns the latent heat of vaporization of the material
+func (m *BasicMaterial) LatentHeatOfVaporization() units.Quantity {
+	return m.latentHeatOfVapor
+}
+
+// LiquidPhase returns the material the solid turns into when it melts
+func (m *BasicMaterial) LiquidPhase() Material {
+	if m.liquid == nil {
+		return nil
+	}
+	return m.liquid
+}
+
+// SolidPhase returns the material the liquid turns into when it freezes
+func (m *BasicMaterial) SolidPhase() Material {
+	if m.solid == nil {
+		return nil
+	}
+	return m.solid
+}
+
 // Predefined materials
 var (
 	// Iron represents iron
@@ -107,6 +212,9 @@ var (
 		0.3,                                       // Emissivity
 		0.7,                                       // Elasticity
 		[3]float64{0.6, 0.6, 0.6},                 // Gray color
+	).WithVaporization(
+		units.NewQuantity(3134.0, units.Kelvin), // K (boiling point)
+		units.NewQuantity(6.09e6, units.Joule),  // J/kg (latent heat of vaporization)
 	)
 
 	// Copper represents copper
@@ -118,6 +226,9 @@ var (
 		0.03,                                      // Emissivity
 		0.75,                                      // Elasticity
 		[3]float64{0.85, 0.45, 0.2},               // Copper color
+	).WithVaporization(
+		units.NewQuantity(2835.0, units.Kelvin), // K
+		units.NewQuantity(4.73e6, units.Joule),  // J/kg
 	)
 
 	// Ice represents ice
@@ -129,6 +240,10 @@ var (
 		0.97,                                     // Emissivity
 		0.3,                                      // Elasticity
 		[3]float64{0.8, 0.9, 0.95},               // Light blue color
+	).WithMelting(
+		units.NewQuantity(273.15, units.Kelvin), // K (melting point)
+		units.NewQuantity(3.34e5, units.Joule),  // J/kg (latent heat of fusion)
+		Water,                                   // Melts into water
 	)
 
 	// Water represents water
@@ -140,6 +255,9 @@ var (
 		0.95,                                     // Emissivity
 		0.0,                                      // Elasticity (fluid)
 		[3]float64{0.0, 0.3, 0.8},                // Blue color
+	).WithVaporization(
+		units.NewQuantity(373.15, units.Kelvin), // K (boiling point)
+		units.NewQuantity(2.256e6, units.Joule), // J/kg (latent heat of vaporization)
 	)
 
 	// Rock represents rock
@@ -151,6 +269,9 @@ var (
 		0.8,                                       // Emissivity
 		0.4,                                       // Elasticity
 		[3]float64{0.5, 0.5, 0.5},                 // Gray color
+	).WithVaporization(
+		units.NewQuantity(3000.0, units.Kelvin), // K (silicates)
+		units.NewQuantity(1.2e7, units.Joule),   // J/kg
 	)
 )
 
@@ -0,0 +1,140 @@
+// Package orbit provides Keplerian orbital elements and helpers to place bodies on orbits
+package orbit
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+// tolerance below which an orbit is considered circular or equatorial
+const tolerance = 1e-10
+
+// Elements are the classical orbital elements of a Keplerian orbit.
+// The angles are measured in the XY reference plane, from the X axis, with Z as the pole.
+// When an angle is undefined it is set to zero and the following one absorbs it: for circular orbits the
+// argument of periapsis is zero and the true anomaly is measured from the ascending node (argument of
+// latitude); for equatorial orbits the longitude of the ascending node is zero and the argument of periapsis
+// is measured from the X axis (longitude of periapsis).
+type Elements struct {
+	SemiMajorAxis            float64 // a (m), negative for hyperbolic orbits
+	Eccentricity             float64 // e, 0 for circular, between 0 and 1 for elliptic, greater than 1 for hyperbolic orbits
+	Inclination              float64 // i (rad), between 0 and π
+	LongitudeOfAscendingNode float64 // Ω (rad), between 0 and 2π
+	ArgumentOfPeriapsis      float64 // ω (rad), between 0 and 2π
+	TrueAnomaly              float64 // ν (rad), between 0 and 2π
+}
+
+// FromStateVectors computes the orbital elements from a position and a velocity relative to the central body,
+// with the gravitational parameter μ = G (M + m) of the orbit
+func FromStateVectors(position, velocity vector.Vector3, mu float64) Elements {
+	r := position.Length()
+	speedSquared := velocity.LengthSquared()
+
+	// Angular momentum, node vector (toward the ascending node) and eccentricity vector
+	h := position.Cross(velocity)
+	hUnit := h.Normalize()
+	node := vector.NewVector3(-h.Y(), h.X(), 0)
+	eccentricityVector := position.Scale(speedSquared - mu/r).Sub(velocity.Scale(position.Dot(velocity))).Scale(1.0 / mu)
+
+	elements := Elements{Eccentricity: eccentricityVector.Length()}
+
+	// a = -μ / (2 ε)
+	energy := speedSquared/2 - mu/r
+	elements.SemiMajorAxis = -mu / (2 * energy)
+
+	elements.Inclination = math.Acos(math.Max(-1, math.Min(1, h.Z()/h.Length())))
+
+	equatorial := node.Length() <= tolerance*h.Length()
+	circular := elements.Eccentricity <= tolerance
+	if !equatorial {
+		elements.LongitudeOfAscendingNode = normalizeAngle(math.Atan2(node.Y(), node.X()))
+	}
+
+	// Reference direction of the argument of periapsis and of the true anomaly
+	reference := vector.NewVector3(1, 0, 0)
+	if !equatorial {
+		reference = node
+	}
+	if !circular {
+		elements.ArgumentOfPeriapsis = angleBetween(reference, eccentricityVector, hUnit)
+		elements.TrueAnomaly = angleBetween(eccentricityVector, position, hUnit)
+	} else {
+		elements.TrueAnomaly = angleBetween(reference, position, hUnit)
+	}
+	return elements
+}
+
+// StateVectors returns the position and the velocity relative to the central body,
+// with the gravitational parameter μ = G (M + m) of the orbit
+func (e Elements) StateVectors(mu float64) (vector.Vector3, vector.Vector3) {
+	p := e.SemiLatusRectum()
+	cosNu, sinNu := math.Cos(e.TrueAnomaly), math.Sin(e.TrueAnomaly)
+	r := p / (1 + e.Eccentricity*cosNu)
+	speed := math.Sqrt(mu / p)
+
+	// Position and velocity in the perifocal frame (x toward the periapsis, z along the angular momentum)
+	position := vector.NewVector3(r*cosNu, r*sinNu, 0)
+	velocity := vector.NewVector3(-speed*sinNu, speed*(e.Eccentricity+cosNu), 0)
+
+	return e.toReference(position), e.toReference(velocity)
+}
+
+// toReference rotates a vector from the perifocal frame to the reference frame: Rz(Ω) Rx(i) Rz(ω)
+func (e Elements) toReference(v vector.Vector3) vector.Vector3 {
+	v = rotateZ(v, e.ArgumentOfPeriapsis)
+	cosI, sinI := math.Cos(e.Inclination), math.Sin(e.Inclination)
+	v = vector.NewVector3(v.X(), v.Y()*cosI-v.Z()*sinI, v.Y()*sinI+v.Z()*cosI)
+	return rotateZ(v, e.LongitudeOfAscendingNode)
+}
+
+// SemiLatusRectum returns the semi-latus rectum p = a (1 - e²) (m)
+func (e Elements) SemiLatusRectum() float64 {
+	return e.SemiMajorAxis * (1 - e.Eccentricity*e.Eccentricity)
+}
+
+// Periapsis returns the distance of the periapsis from the central body (m)
+func (e Elements) Periapsis() float64 {
+	return e.SemiMajorAxis * (1 - e.Eccentricity)
+}
+
+// Apoapsis returns the distance of the apoapsis from the central body (m), infinite for open orbits
+func (e Elements) Apoapsis() float64 {
+	if e.Eccentricity >= 1 {
+		return math.Inf(1)
+	}
+	return e.SemiMajorAxis * (1 + e.Eccentricity)
+}
+
+// Period returns the orbital period T = 2π √(a³ / μ) (s), infinite for open orbits
+func (e Elements) Period(mu float64) float64 {
+	if e.Eccentricity >= 1 || e.SemiMajorAxis <= 0 {
+		return math.Inf(1)
+	}
+	return 2 * math.Pi * math.Sqrt(e.SemiMajorAxis*e.SemiMajorAxis*e.SemiMajorAxis/mu)
+}
+
+// IsBound returns true if the orbit is closed (elliptic or circular)
+func (e Elements) IsBound() bool {
+	return e.Eccentricity < 1
+}
+
+// angleBetween returns the angle from a to b around an axis, between 0 and 2π
+func angleBetween(a, b, axis vector.Vector3) float64 {
+	return normalizeAngle(math.Atan2(a.Cross(b).Dot(axis), a.Dot(b)))
+}
+
+// normalizeAngle returns an angle between 0 and 2π
+func normalizeAngle(angle float64) float64 {
+	angle = math.Mod(angle, 2*math.Pi)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	return angle
+}
+
+// rotateZ rotates a vector around the Z axis
+func rotateZ(v vector.Vector3, angle float64) vector.Vector3 {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return vector.NewVector3(v.X()*cos-v.Y()*sin, v.X()*sin+v.Y()*cos, v.Z())
+}
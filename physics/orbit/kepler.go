@@ -0,0 +1,122 @@
+package orbit
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+// maxKeplerIterations is the maximum number of iterations of the Kepler solver
+const maxKeplerIterations = 50
+
+// Propagate advances a position and a velocity relative to the central body analytically along their
+// Keplerian orbit by a time dt (negative to go back in time), with the gravitational parameter μ = G (M + m).
+// It uses the universal variable formulation, valid for elliptic, parabolic and hyperbolic orbits,
+// solved with the Laguerre-Conway method.
+func Propagate(position, velocity vector.Vector3, mu, dt float64) (vector.Vector3, vector.Vector3) {
+	r0 := position.Length()
+	if dt == 0 || r0 == 0 || mu <= 0 {
+		return position, velocity
+	}
+	sqrtMu := math.Sqrt(mu)
+	sigma0 := position.Dot(velocity) / sqrtMu
+
+	// Reciprocal of the semi-major axis: α = 2 / r0 - v0² / μ
+	alpha := 2/r0 - velocity.LengthSquared()/mu
+
+	// Whole periods of an elliptic orbit can be skipped
+	if alpha > 0 {
+		period := 2 * math.Pi / (sqrtMu * alpha * math.Sqrt(alpha))
+		dt = math.Mod(dt, period)
+	}
+
+	// Universal Kepler equation: F(χ) = σ0 χ² C(z) + (1 - α r0) χ³ S(z) + r0 χ - √μ dt = 0, with z = α χ²
+	chi := initialUniversalAnomaly(r0, alpha, sqrtMu, dt, position.Dot(velocity))
+	for i := 0; i < maxKeplerIterations; i++ {
+		z := alpha * chi * chi
+		c, s := stumpffC(z), stumpffS(z)
+		f := sigma0*chi*chi*c + (1-alpha*r0)*chi*chi*chi*s + r0*chi - sqrtMu*dt
+		df := sigma0*chi*(1-z*s) + (1-alpha*r0)*chi*chi*c + r0
+		ddf := sigma0*(1-z*c) + (1-alpha*r0)*chi*(1-z*s)
+
+		// Laguerre step with n = 5
+		const n = 5.0
+		root := math.Sqrt(math.Abs((n-1)*(n-1)*df*df - n*(n-1)*f*ddf))
+		denominator := df + math.Copysign(root, df)
+		if denominator == 0 {
+			break
+		}
+		step := n * f / denominator
+		chi -= step
+		if math.Abs(step) <= 1e-13*math.Max(1, math.Abs(chi)) {
+			break
+		}
+	}
+
+	// Lagrange coefficients
+	z := alpha * chi * chi
+	c, s := stumpffC(z), stumpffS(z)
+	f := 1 - chi*chi/r0*c
+	g := dt - chi*chi*chi/sqrtMu*s
+	newPosition := position.Scale(f).Add(velocity.Scale(g))
+	r := newPosition.Length()
+	fDot := sqrtMu / (r * r0) * (z*s - 1) * chi
+	gDot := 1 - chi*chi/r*c
+	newVelocity := position.Scale(fDot).Add(velocity.Scale(gDot))
+
+	return newPosition, newVelocity
+}
+
+// PropagateElements advances orbital elements by a time dt, with the gravitational parameter μ = G (M + m)
+func PropagateElements(elements Elements, mu, dt float64) Elements {
+	position, velocity := elements.StateVectors(mu)
+	position, velocity = Propagate(position, velocity, mu, dt)
+	return FromStateVectors(position, velocity, mu)
+}
+
+// initialUniversalAnomaly returns the initial guess of the universal anomaly for a time dt
+func initialUniversalAnomaly(r0, alpha, sqrtMu, dt, rv float64) float64 {
+	switch {
+	case alpha > 1e-12/r0:
+		// Elliptic orbit
+		return sqrtMu * dt * alpha
+	case alpha < -1e-12/r0:
+		// Hyperbolic orbit
+		a := 1 / alpha
+		sign := math.Copysign(1, dt)
+		argument := -2 * sqrtMu * sqrtMu * alpha * dt / (rv + sign*math.Sqrt(-sqrtMu*sqrtMu*a)*(1-r0*alpha))
+		if argument > 0 {
+			return sign * math.Sqrt(-a) * math.Log(argument)
+		}
+		return sqrtMu * dt / r0
+	default:
+		// Parabolic orbit
+		return sqrtMu * dt / r0
+	}
+}
+
+// stumpffC returns the Stumpff function C(z) = (1 - cos √z) / z
+func stumpffC(z float64) float64 {
+	switch {
+	case z > 1e-6:
+		return (1 - math.Cos(math.Sqrt(z))) / z
+	case z < -1e-6:
+		return (math.Cosh(math.Sqrt(-z)) - 1) / -z
+	default:
+		return 1.0/2 - z/24 + z*z/720
+	}
+}
+
+// stumpffS returns the Stumpff function S(z) = (√z - sin √z) / √z³
+func stumpffS(z float64) float64 {
+	switch {
+	case z > 1e-6:
+		sqrtZ := math.Sqrt(z)
+		return (sqrtZ - math.Sin(sqrtZ)) / (sqrtZ * sqrtZ * sqrtZ)
+	case z < -1e-6:
+		sqrtZ := math.Sqrt(-z)
+		return (math.Sinh(sqrtZ) - sqrtZ) / (sqrtZ * sqrtZ * sqrtZ)
+	default:
+		return 1.0/6 - z/120 + z*z/5040
+	}
+}
@@ -0,0 +1,90 @@
+package orbit
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+// maxLambertIterations is the maximum number of iterations of the Lambert solver
+const maxLambertIterations = 200
+
+// Lambert solves Lambert's problem: it returns the velocities at the start and at the end of the Keplerian arc
+// going from the position r1 to the position r2 in a time of flight tof, with the gravitational parameter μ.
+// The transfer is the single-revolution arc moving counterclockwise around the normal (e.g. the angular momentum
+// of the departure orbit); a nil or zero normal defaults to the Z axis.
+// It uses the universal variable formulation, solved by bisection on z = α χ².
+func Lambert(r1, r2 vector.Vector3, tof, mu float64, normal vector.Vector3) (vector.Vector3, vector.Vector3, error) {
+	if tof <= 0 || mu <= 0 {
+		return nil, nil, fmt.Errorf("the time of flight and the gravitational parameter must be positive")
+	}
+	if normal == nil || normal.Length() == 0 {
+		normal = vector.NewVector3(0, 0, 1)
+	}
+
+	length1, length2 := r1.Length(), r2.Length()
+	if length1 == 0 || length2 == 0 {
+		return nil, nil, fmt.Errorf("the positions must be away from the central body")
+	}
+
+	// Transfer angle, measured in the direction of motion around the normal
+	cosAngle := math.Max(-1, math.Min(1, r1.Dot(r2)/(length1*length2)))
+	angle := math.Acos(cosAngle)
+	if r1.Cross(r2).Dot(normal) < 0 {
+		angle = 2*math.Pi - angle
+	}
+	a := math.Sin(angle) * math.Sqrt(length1*length2/(1-cosAngle))
+	if math.IsNaN(a) || math.Abs(a) < 1e-12*math.Sqrt(length1*length2) {
+		return nil, nil, fmt.Errorf("the transfer plane is undefined for a transfer angle of %v rad", angle)
+	}
+
+	// y(z) and the time of flight t(z), increasing with z on the single-revolution branch
+	y := func(z float64) float64 {
+		return length1 + length2 + a*(z*stumpffS(z)-1)/math.Sqrt(stumpffC(z))
+	}
+	sqrtMu := math.Sqrt(mu)
+	timeOfFlight := func(z float64) float64 {
+		yz := y(z)
+		if yz < 0 {
+			return math.Inf(-1)
+		}
+		return (math.Pow(yz/stumpffC(z), 1.5)*stumpffS(z) + a*math.Sqrt(yz)) / sqrtMu
+	}
+
+	// Bracket the solution: z < 4π² for a single revolution, very negative z for fast hyperbolic transfers
+	upper := 4 * math.Pi * math.Pi * (1 - 1e-6)
+	lower := -4 * math.Pi * math.Pi
+	for timeOfFlight(lower) > tof {
+		lower *= 2
+		if lower < -1e4 {
+			return nil, nil, fmt.Errorf("no transfer found for a time of flight of %v s", tof)
+		}
+	}
+	if timeOfFlight(upper) < tof {
+		return nil, nil, fmt.Errorf("the time of flight %v s requires more than one revolution", tof)
+	}
+
+	z := 0.0
+	for i := 0; i < maxLambertIterations; i++ {
+		z = (lower + upper) / 2
+		if timeOfFlight(z) < tof {
+			lower = z
+		} else {
+			upper = z
+		}
+		if upper-lower <= 1e-14*math.Max(1, math.Abs(z)) {
+			break
+		}
+	}
+
+	// Lagrange coefficients
+	yz := y(z)
+	f := 1 - yz/length1
+	g := a * math.Sqrt(yz/mu)
+	gDot := 1 - yz/length2
+	v1 := r2.Sub(r1.Scale(f)).Scale(1 / g)
+	v2 := r2.Scale(gDot).Sub(r1).Scale(1 / g)
+
+	return v1, v2, nil
+}
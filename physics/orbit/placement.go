@@ -0,0 +1,47 @@
+package orbit
+
+import (
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// GravitationalParameter returns the gravitational parameter μ = G (M + m) of a body orbiting a central body
+func GravitationalParameter(central body.Body, orbiting body.Body) float64 {
+	return constants.G * (units.ConvertToStandardUnit(central.Mass()) + units.ConvertToStandardUnit(orbiting.Mass()))
+}
+
+// ElementsOf returns the orbital elements of a body relative to a central body
+func ElementsOf(b, central body.Body) Elements {
+	return FromStateVectors(
+		b.Position().Sub(central.Position()),
+		b.Velocity().Sub(central.Velocity()),
+		GravitationalParameter(central, b),
+	)
+}
+
+// Place moves a body onto an orbit around a central body, setting its position and velocity
+func Place(b, central body.Body, elements Elements) {
+	position, velocity := elements.StateVectors(GravitationalParameter(central, b))
+	b.SetPosition(central.Position().Add(position))
+	b.SetVelocity(central.Velocity().Add(velocity))
+}
+
+// NewBodyOnOrbit creates a new body on an orbit around a central body
+func NewBodyOnOrbit(central body.Body, elements Elements, mass, radius units.Quantity, mat body.Material) *body.RigidBody {
+	b := body.NewRigidBody(mass, radius, vector.Zero3(), vector.Zero3(), mat)
+	Place(b, central, elements)
+	return b
+}
+
+// Circular returns the elements of a circular orbit of a given radius (m), inclination and longitude of the
+// ascending node, with the body at an angle from the ascending node (rad)
+func Circular(radius, inclination, ascendingNode, angle float64) Elements {
+	return Elements{
+		SemiMajorAxis:            radius,
+		Inclination:              inclination,
+		LongitudeOfAscendingNode: ascendingNode,
+		TrueAnomaly:              angle,
+	}
+}
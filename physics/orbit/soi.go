@@ -0,0 +1,218 @@
+package orbit
+
+import (
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/google/uuid"
+)
+
+// SphereOfInfluence returns the Laplace radius of the sphere of influence of a body orbiting a parent body,
+// r = a (m / M)^(2/5). The distance from the parent is used when the orbit is not bound.
+func SphereOfInfluence(b, parent body.Body) float64 {
+	a := ElementsOf(b, parent).SemiMajorAxis
+	if a <= 0 || math.IsInf(a, 0) || math.IsNaN(a) {
+		a = b.Position().Sub(parent.Position()).Length()
+	}
+	return a * math.Pow(units.ConvertToStandardUnit(b.Mass())/units.ConvertToStandardUnit(parent.Mass()), 0.4)
+}
+
+// Transition is the passage of a tracked body from the sphere of influence of a body to another
+type Transition struct {
+	Body     body.Body      // Tracked body
+	From     body.Body      // Previous dominant body
+	To       body.Body      // New dominant body
+	Time     float64        // Time of the transition (s)
+	Position vector.Vector3 // Position relative to the new dominant body
+	Velocity vector.Vector3 // Velocity relative to the new dominant body
+}
+
+// SOITracker determines which body of a hierarchy dominates each tracked body (e.g. a spacecraft) and records
+// the transitions between spheres of influence.
+// In patched-conic mode the world applies to the tracked bodies only the gravity of their dominant body,
+// computed in its reference frame, instead of the gravity of all the bodies. A tracked body without a dominant
+// body keeps the gravity of all the bodies.
+type SOITracker struct {
+	parents  map[uuid.UUID]body.Body // Parent of each body of the hierarchy, nil for the roots
+	bodies   map[uuid.UUID]body.Body // Bodies of the hierarchy
+	tracked  map[uuid.UUID]body.Body // Tracked bodies
+	dominant map[uuid.UUID]body.Body // Dominant body of each tracked body
+	patched  bool
+}
+
+// NewSOITracker creates a new sphere of influence tracker
+func NewSOITracker() *SOITracker {
+	return &SOITracker{
+		parents:  make(map[uuid.UUID]body.Body),
+		bodies:   make(map[uuid.UUID]body.Body),
+		tracked:  make(map[uuid.UUID]body.Body),
+		dominant: make(map[uuid.UUID]body.Body),
+	}
+}
+
+// AddBody adds a body orbiting a parent to the hierarchy (nil for a root, e.g. the Sun)
+func (t *SOITracker) AddBody(b, parent body.Body) {
+	t.bodies[b.ID()] = b
+	t.parents[b.ID()] = parent
+}
+
+// Track starts tracking the sphere of influence a body is in
+func (t *SOITracker) Track(b body.Body) {
+	t.tracked[b.ID()] = b
+}
+
+// Remove removes a body from the hierarchy and from the tracked bodies.
+// The children of a removed body are attached to its parent.
+func (t *SOITracker) Remove(id uuid.UUID) {
+	if _, ok := t.bodies[id]; ok {
+		parent := t.parents[id]
+		for child, p := range t.parents {
+			if p != nil && p.ID() == id {
+				t.parents[child] = parent
+			}
+		}
+		for tracked, dominant := range t.dominant {
+			if dominant.ID() == id {
+				delete(t.dominant, tracked)
+			}
+		}
+	}
+	delete(t.bodies, id)
+	delete(t.parents, id)
+	delete(t.tracked, id)
+	delete(t.dominant, id)
+}
+
+// IsTracked returns true if a body is tracked
+func (t *SOITracker) IsTracked(id uuid.UUID) bool {
+	_, ok := t.tracked[id]
+	return ok
+}
+
+// GetTracked returns the tracked bodies
+func (t *SOITracker) GetTracked() []body.Body {
+	tracked := make([]body.Body, 0, len(t.tracked))
+	for _, b := range t.tracked {
+		tracked = append(tracked, b)
+	}
+	return tracked
+}
+
+// SetPatched enables or disables the patched-conic mode
+func (t *SOITracker) SetPatched(patched bool) {
+	t.patched = patched
+}
+
+// IsPatched returns true if the patched-conic mode is enabled
+func (t *SOITracker) IsPatched() bool {
+	return t.patched
+}
+
+// Radius returns the radius of the sphere of influence of a body of the hierarchy (infinite for the roots)
+func (t *SOITracker) Radius(id uuid.UUID) float64 {
+	b, ok := t.bodies[id]
+	if !ok {
+		return 0
+	}
+	parent := t.parents[id]
+	if parent == nil {
+		return math.Inf(1)
+	}
+	return SphereOfInfluence(b, parent)
+}
+
+// Dominant returns the dominant body of a tracked body, nil if it is unknown
+func (t *SOITracker) Dominant(id uuid.UUID) body.Body {
+	return t.dominant[id]
+}
+
+// RelativeState returns the position and the velocity of a tracked body in the frame of its dominant body
+func (t *SOITracker) RelativeState(id uuid.UUID) (vector.Vector3, vector.Vector3) {
+	b, dominant := t.tracked[id], t.dominant[id]
+	if b == nil || dominant == nil {
+		return nil, nil
+	}
+	return b.Position().Sub(dominant.Position()), b.Velocity().Sub(dominant.Velocity())
+}
+
+// Update determines the dominant body of each tracked body and returns the transitions since the last update
+func (t *SOITracker) Update(time float64) []Transition {
+	var transitions []Transition
+	for id, b := range t.tracked {
+		dominant := t.findDominant(b)
+		previous := t.dominant[id]
+		if dominant == nil {
+			delete(t.dominant, id)
+			continue
+		}
+		t.dominant[id] = dominant
+		if previous != nil && previous.ID() != dominant.ID() {
+			transitions = append(transitions, Transition{
+				Body:     b,
+				From:     previous,
+				To:       dominant,
+				Time:     time,
+				Position: b.Position().Sub(dominant.Position()),
+				Velocity: b.Velocity().Sub(dominant.Velocity()),
+			})
+		}
+	}
+	return transitions
+}
+
+// Gravity returns the gravitational force on a tracked body in patched-conic mode: the attraction of the dominant
+// body in its reference frame, plus the acceleration of the frame itself (the dominant body's acceleration
+// must already include the forces of the current step)
+func (t *SOITracker) Gravity(b body.Body, g float64) vector.Vector3 {
+	dominant := t.dominant[b.ID()]
+	if dominant == nil {
+		return vector.Zero3()
+	}
+	mass := units.ConvertToStandardUnit(b.Mass())
+	r := b.Position().Sub(dominant.Position())
+	distance := r.Length()
+	if distance == 0 {
+		return vector.Zero3()
+	}
+
+	attraction := r.Scale(-g * units.ConvertToStandardUnit(dominant.Mass()) / (distance * distance * distance))
+	return attraction.Add(dominant.Acceleration()).Scale(mass)
+}
+
+// findDominant returns the body whose sphere of influence contains a body, descending the hierarchy from the root
+// that attracts it the most
+func (t *SOITracker) findDominant(b body.Body) body.Body {
+	var current body.Body
+	strongest := 0.0
+	for id, root := range t.bodies {
+		if t.parents[id] != nil || id == b.ID() {
+			continue
+		}
+		distance := b.Position().Sub(root.Position()).Length()
+		if attraction := units.ConvertToStandardUnit(root.Mass()) / (distance * distance); current == nil || attraction > strongest {
+			current, strongest = root, attraction
+		}
+	}
+
+	for current != nil {
+		var next body.Body
+		nearest := math.Inf(1)
+		for id, child := range t.bodies {
+			parent := t.parents[id]
+			if parent == nil || parent.ID() != current.ID() || id == b.ID() {
+				continue
+			}
+			distance := b.Position().Sub(child.Position()).Length()
+			if distance < SphereOfInfluence(child, current) && distance < nearest {
+				next, nearest = child, distance
+			}
+		}
+		if next == nil {
+			break
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,158 @@
+package orbit
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// Transfer is a two-impulse transfer between two bodies orbiting the same central body.
+// The times are measured from the current state of the bodies (s), the vectors are relative to the central body.
+type Transfer struct {
+	Departure         float64        // Departure time (s)
+	Arrival           float64        // Arrival time (s)
+	DeparturePosition vector.Vector3 // Position of the departure body at the departure
+	ArrivalPosition   vector.Vector3 // Position of the arrival body at the arrival
+	DepartureVelocity vector.Vector3 // Velocity on the transfer orbit at the departure
+	ArrivalVelocity   vector.Vector3 // Velocity on the transfer orbit at the arrival
+	DepartureDeltaV   vector.Vector3 // Velocity change to leave the orbit of the departure body
+	ArrivalDeltaV     vector.Vector3 // Velocity change to match the velocity of the arrival body
+}
+
+// TimeOfFlight returns the duration of the transfer (s)
+func (t Transfer) TimeOfFlight() float64 {
+	return t.Arrival - t.Departure
+}
+
+// TotalDeltaV returns the delta-v budget of the transfer (m/s)
+func (t Transfer) TotalDeltaV() float64 {
+	return t.DepartureDeltaV.Length() + t.ArrivalDeltaV.Length()
+}
+
+// PlanTransfer computes the transfer from a body to another around a central body, leaving after the departure
+// time and arriving after the arrival time (s from now). The bodies are propagated along their Keplerian orbits,
+// the mass of the spacecraft is neglected. The departure delta-v can be flown with a guidance.PlannedBurn.
+// The bodies and the transfer orbit are propagated with the same gravitational parameter μ = G M of the central
+// body, the one of the massless spacecraft, so that the velocities to match are measured on the same orbits.
+func PlanTransfer(central, from, to body.Body, departure, arrival float64) (Transfer, error) {
+	if arrival <= departure {
+		return Transfer{}, fmt.Errorf("the arrival must follow the departure")
+	}
+
+	mu := constants.G * units.ConvertToStandardUnit(central.Mass())
+	fromPosition, fromVelocity := Propagate(
+		from.Position().Sub(central.Position()),
+		from.Velocity().Sub(central.Velocity()),
+		mu,
+		departure,
+	)
+	toPosition, toVelocity := Propagate(
+		to.Position().Sub(central.Position()),
+		to.Velocity().Sub(central.Velocity()),
+		mu,
+		arrival,
+	)
+
+	v1, v2, err := Lambert(fromPosition, toPosition, arrival-departure, mu, fromPosition.Cross(fromVelocity))
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	return Transfer{
+		Departure:         departure,
+		Arrival:           arrival,
+		DeparturePosition: fromPosition,
+		ArrivalPosition:   toPosition,
+		DepartureVelocity: v1,
+		ArrivalVelocity:   v2,
+		DepartureDeltaV:   v1.Sub(fromVelocity),
+		ArrivalDeltaV:     toVelocity.Sub(v2),
+	}, nil
+}
+
+// Porkchop contains the delta-v budgets of the transfers over a grid of departure and arrival times
+type Porkchop struct {
+	Departures []float64     // Departure times (s from now)
+	Arrivals   []float64     // Arrival times (s from now)
+	DeltaV     [][]float64   // Total delta-v (m/s) by departure and arrival, NaN if there is no transfer
+	Transfers  [][]*Transfer // Transfers by departure and arrival, nil if there is no transfer
+}
+
+// NewPorkchop computes the transfers from a body to another around a central body for every pair of departure
+// and arrival times (s from now)
+func NewPorkchop(central, from, to body.Body, departures, arrivals []float64) *Porkchop {
+	p := &Porkchop{
+		Departures: departures,
+		Arrivals:   arrivals,
+		DeltaV:     make([][]float64, len(departures)),
+		Transfers:  make([][]*Transfer, len(departures)),
+	}
+	for i, departure := range departures {
+		p.DeltaV[i] = make([]float64, len(arrivals))
+		p.Transfers[i] = make([]*Transfer, len(arrivals))
+		for j, arrival := range arrivals {
+			transfer, err := PlanTransfer(central, from, to, departure, arrival)
+			if err != nil {
+				p.DeltaV[i][j] = math.NaN()
+				continue
+			}
+			p.DeltaV[i][j] = transfer.TotalDeltaV()
+			p.Transfers[i][j] = &transfer
+		}
+	}
+	return p
+}
+
+// Best returns the transfer with the lowest delta-v budget, nil if there is none
+func (p *Porkchop) Best() *Transfer {
+	var best *Transfer
+	for i := range p.Transfers {
+		for j, transfer := range p.Transfers[i] {
+			if transfer != nil && (best == nil || p.DeltaV[i][j] < best.TotalDeltaV()) {
+				best = transfer
+			}
+		}
+	}
+	return best
+}
+
+// WriteCSV writes the grid as CSV, one row per pair of departure and arrival times
+func (p *Porkchop) WriteCSV(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "departure_s,arrival_s,time_of_flight_s,departure_delta_v_m_s,arrival_delta_v_m_s,total_delta_v_m_s"); err != nil {
+		return err
+	}
+	for i, departure := range p.Departures {
+		for j, arrival := range p.Arrivals {
+			transfer := p.Transfers[i][j]
+			if transfer == nil {
+				continue
+			}
+			_, err := fmt.Fprintf(w, "%g,%g,%g,%g,%g,%g\n",
+				departure, arrival, transfer.TimeOfFlight(),
+				transfer.DepartureDeltaV.Length(), transfer.ArrivalDeltaV.Length(), transfer.TotalDeltaV())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SaveCSV saves the grid to a CSV file
+func (p *Porkchop) SaveCSV(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteCSV(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
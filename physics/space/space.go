@@ -9,6 +9,7 @@ import (
 	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/google/uuid"
 )
 
 // TaskSubmitter represents an interface for submitting tasks to be executed in parallel
@@ -89,6 +90,94 @@ func (aabb *AABB) Size() vector.Vector3 {
 	return aabb.Max.Sub(aabb.Min)
 }
 
+// Periodicity indicates which axes (X, Y, Z) of a region wrap around
+type Periodicity [3]bool
+
+// Any returns true if at least one axis is periodic
+func (p Periodicity) Any() bool {
+	return p[0] || p[1] || p[2]
+}
+
+// MinimumImage returns the displacement from one point to another using the
+// minimum image convention along the periodic axes of the AABB
+func (aabb *AABB) MinimumImage(from, to vector.Vector3, periodic Periodicity) vector.Vector3 {
+	delta := to.Sub(from).ToArray()
+	size := aabb.Size().ToArray()
+
+	for k := 0; k < 3; k++ {
+		if periodic[k] && size[k] > 0 {
+			delta[k] -= size[k] * math.Round(delta[k]/size[k])
+		}
+	}
+
+	return vector.NewVector3(delta[0], delta[1], delta[2])
+}
+
+// MinimumImageFunc returns a function computing displacements with the minimum image convention.
+// It returns nil if no axis is periodic.
+func (aabb *AABB) MinimumImageFunc(periodic Periodicity) func(from, to vector.Vector3) vector.Vector3 {
+	if !periodic.Any() {
+		return nil
+	}
+	return func(from, to vector.Vector3) vector.Vector3 {
+		return aabb.MinimumImage(from, to, periodic)
+	}
+}
+
+// Wrap maps a point back into the AABB along the periodic axes
+func (aabb *AABB) Wrap(point vector.Vector3, periodic Periodicity) vector.Vector3 {
+	coords := point.ToArray()
+	min := aabb.Min.ToArray()
+	size := aabb.Size().ToArray()
+
+	for k := 0; k < 3; k++ {
+		if !periodic[k] || size[k] <= 0 {
+			continue
+		}
+		offset := math.Mod(coords[k]-min[k], size[k])
+		if offset < 0 {
+			offset += size[k]
+		}
+		coords[k] = min[k] + offset
+	}
+
+	return vector.NewVector3(coords[0], coords[1], coords[2])
+}
+
+// images returns the offsets of the periodic images of a sphere that cross the faces of the AABB
+func (aabb *AABB) images(center vector.Vector3, radius float64, periodic Periodicity) []vector.Vector3 {
+	c := center.ToArray()
+	min := aabb.Min.ToArray()
+	max := aabb.Max.ToArray()
+	size := aabb.Size().ToArray()
+
+	// Collect the possible shifts along each axis
+	var shifts [3][]float64
+	for k := 0; k < 3; k++ {
+		shifts[k] = []float64{0}
+		if !periodic[k] {
+			continue
+		}
+		if c[k]-radius < min[k] {
+			shifts[k] = append(shifts[k], size[k])
+		}
+		if c[k]+radius > max[k] {
+			shifts[k] = append(shifts[k], -size[k])
+		}
+	}
+
+	// Combine the shifts of all axes
+	result := make([]vector.Vector3, 0, len(shifts[0])*len(shifts[1])*len(shifts[2]))
+	for _, dx := range shifts[0] {
+		for _, dy := range shifts[1] {
+			for _, dz := range shifts[2] {
+				result = append(result, vector.NewVector3(dx, dy, dz))
+			}
+		}
+	}
+	return result
+}
+
 // SpatialStructure represents a spatial structure to optimize spatial queries
 type SpatialStructure interface {
 	// Insert inserts a body into the structure
@@ -121,6 +210,15 @@ type Octree struct {
 	totalMass    float64        // Total mass of all bodies in this node and its children
 	centerOfMass vector.Vector3 // Center of mass of all bodies in this node and its children
 
+	// Fields for Coulomb force calculation
+	totalCharge    float64        // Total (signed) charge of all bodies in this node and its children
+	absoluteCharge float64        // Sum of the absolute values of the charges
+	chargeCenter   vector.Vector3 // Center of the charges, weighted by their absolute value
+
+	// Fields for periodic boundaries
+	domain   *AABB       // Bounds of the root node (the periodic domain)
+	periodic Periodicity // Periodic axes of the domain
+
 	// Mutex to protect concurrent access
 	mutex sync.RWMutex
 }
@@ -136,10 +234,42 @@ func NewOctree(bounds *AABB, maxObjects, maxLevels int) *Octree {
 		divided:      false,
 		totalMass:    0,
 		centerOfMass: vector.Zero3(),
+		chargeCenter: vector.Zero3(),
+		domain:       bounds,
 		mutex:        sync.RWMutex{},
 	}
 }
 
+// SetPeriodicity sets which axes of the octree bounds wrap around.
+// Periodic axes are honored by sphere queries and by gravity calculations (minimum image convention).
+func (ot *Octree) SetPeriodicity(periodic Periodicity) {
+	ot.mutex.Lock()
+	defer ot.mutex.Unlock()
+
+	ot.periodic = periodic
+	if ot.divided {
+		for i := 0; i < 8; i++ {
+			ot.children[i].SetPeriodicity(periodic)
+		}
+	}
+}
+
+// GetPeriodicity returns which axes of the octree bounds wrap around
+func (ot *Octree) GetPeriodicity() Periodicity {
+	ot.mutex.RLock()
+	defer ot.mutex.RUnlock()
+
+	return ot.periodic
+}
+
+// separation returns the displacement between two points, honoring periodic axes
+func (ot *Octree) separation(from, to vector.Vector3) vector.Vector3 {
+	if ot.periodic.Any() {
+		return ot.domain.MinimumImage(from, to, ot.periodic)
+	}
+	return to.Sub(from)
+}
+
 // Insert inserts a body into the octree
 func (ot *Octree) Insert(b body.Body) {
 	ot.mutex.Lock()
@@ -159,16 +289,18 @@ func (ot *Octree) insertUnsafe(b body.Body) {
 			}
 		}
 
-		// Update the center of mass and total mass
+		// Update the center of mass, total mass and charge
 		ot.updateMassAndCenterOfMass(b, true)
+		ot.updateCharge(b, true)
 		return
 	}
 
 	// Add the object to this node
 	ot.objects = append(ot.objects, b)
 
-	// Update the center of mass and total mass
+	// Update the center of mass, total mass and charge
 	ot.updateMassAndCenterOfMass(b, true)
+	ot.updateCharge(b, true)
 
 	// Check if it's necessary to divide the octree
 	if len(ot.objects) > ot.maxObjects && ot.level < ot.maxLevels {
@@ -209,8 +341,9 @@ func (ot *Octree) removeUnsafe(b body.Body) {
 			}
 		}
 
-		// Update the center of mass and total mass
+		// Update the center of mass, total mass and charge
 		ot.updateMassAndCenterOfMass(b, false)
+		ot.updateCharge(b, false)
 		return
 	}
 
@@ -222,8 +355,9 @@ func (ot *Octree) removeUnsafe(b body.Body) {
 			ot.objects[i] = ot.objects[lastIndex]
 			ot.objects = ot.objects[:lastIndex]
 
-			// Update the center of mass and total mass
+			// Update the center of mass, total mass and charge
 			ot.updateMassAndCenterOfMass(b, false)
+			ot.updateCharge(b, false)
 			break
 		}
 	}
@@ -276,8 +410,33 @@ func (ot *Octree) Query(region Region) []body.Body {
 	return result
 }
 
-// QuerySphere returns all bodies that might interact with the specified sphere
+// QuerySphere returns all bodies that might interact with the specified sphere.
+// On periodic axes the images of the sphere crossing the bounds are queried too.
 func (ot *Octree) QuerySphere(center vector.Vector3, radius float64) []body.Body {
+	ot.mutex.RLock()
+	periodic := ot.periodic
+	ot.mutex.RUnlock()
+
+	if !periodic.Any() {
+		return ot.querySphere(center, radius)
+	}
+
+	// Query every image of the sphere and remove duplicates
+	result := make([]body.Body, 0)
+	seen := make(map[uuid.UUID]bool)
+	for _, offset := range ot.domain.images(center, radius, periodic) {
+		for _, b := range ot.querySphere(center.Add(offset), radius) {
+			if !seen[b.ID()] {
+				seen[b.ID()] = true
+				result = append(result, b)
+			}
+		}
+	}
+	return result
+}
+
+// querySphere returns all bodies that might interact with the specified sphere, ignoring periodicity
+func (ot *Octree) querySphere(center vector.Vector3, radius float64) []body.Body {
 	ot.mutex.RLock()
 	defer ot.mutex.RUnlock()
 
@@ -294,7 +453,7 @@ func (ot *Octree) QuerySphere(center vector.Vector3, radius float64) []body.Body
 	// If the octree is divided, query the children
 	if ot.divided {
 		for i := 0; i < 8; i++ {
-			childResult := ot.children[i].QuerySphere(center, radius)
+			childResult := ot.children[i].querySphere(center, radius)
 			result = append(result, childResult...)
 		}
 	}
@@ -309,9 +468,12 @@ func (ot *Octree) Clear() {
 
 	ot.objects = make([]body.Body, 0)
 
-	// Reset the center of mass and total mass
+	// Reset the center of mass, total mass and charge
 	ot.totalMass = 0
 	ot.centerOfMass = vector.Zero3()
+	ot.totalCharge = 0
+	ot.absoluteCharge = 0
+	ot.chargeCenter = vector.Zero3()
 
 	if ot.divided {
 		for i := 0; i < 8; i++ {
@@ -377,6 +539,8 @@ func (ot *Octree) split() {
 	for i := 0; i < 8; i++ {
 		ot.children[i] = NewOctree(childBounds[i], ot.maxObjects, ot.maxLevels)
 		ot.children[i].level = ot.level + 1
+		ot.children[i].domain = ot.domain
+		ot.children[i].periodic = ot.periodic
 	}
 
 	ot.divided = true
@@ -499,6 +663,123 @@ func (ot *Octree) updateMassAndCenterOfMass(b body.Body, adding bool) {
 	}
 }
 
+// updateCharge updates the total charge and the center of the charges
+func (ot *Octree) updateCharge(b body.Body, adding bool) {
+	// Recalculate from the children if the octree is divided
+	if ot.divided {
+		ot.totalCharge = 0
+		ot.absoluteCharge = 0
+		weightedPosition := vector.Zero3()
+
+		for i := 0; i < 8; i++ {
+			if ot.children[i] != nil && ot.children[i].absoluteCharge > 0 {
+				ot.totalCharge += ot.children[i].totalCharge
+				ot.absoluteCharge += ot.children[i].absoluteCharge
+				weightedPosition = weightedPosition.Add(ot.children[i].chargeCenter.Scale(ot.children[i].absoluteCharge))
+			}
+		}
+
+		if ot.absoluteCharge > 0 {
+			ot.chargeCenter = weightedPosition.Scale(1.0 / ot.absoluteCharge)
+		} else {
+			ot.chargeCenter = vector.Zero3()
+		}
+		return
+	}
+
+	// Convert charge to standard unit (coulombs)
+	charge := units.ConvertToStandardUnit(b.Charge())
+	weight := math.Abs(charge)
+	if weight == 0 {
+		return
+	}
+	position := b.Position()
+
+	if adding {
+		oldAbsoluteCharge := ot.absoluteCharge
+		ot.totalCharge += charge
+		ot.absoluteCharge += weight
+		ot.chargeCenter = ot.chargeCenter.Scale(oldAbsoluteCharge).Add(position.Scale(weight)).Scale(1.0 / ot.absoluteCharge)
+	} else if ot.absoluteCharge > weight {
+		oldAbsoluteCharge := ot.absoluteCharge
+		ot.totalCharge -= charge
+		ot.absoluteCharge -= weight
+		ot.chargeCenter = ot.chargeCenter.Scale(oldAbsoluteCharge).Sub(position.Scale(weight)).Scale(1.0 / ot.absoluteCharge)
+	} else {
+		// If it was the last charge, reset the center of the charges
+		ot.totalCharge = 0
+		ot.absoluteCharge = 0
+		ot.chargeCenter = vector.Zero3()
+	}
+}
+
+// CalculateCoulomb calculates the electrostatic force on a body using the Barnes-Hut algorithm.
+// Distant nodes are approximated by their total charge placed at the center of their charges
+// (weighted by the absolute value of the charges); k is the Coulomb constant.
+func (ot *Octree) CalculateCoulomb(b body.Body, theta, k float64) vector.Vector3 {
+	ot.mutex.RLock()
+	defer ot.mutex.RUnlock()
+
+	charge := units.ConvertToStandardUnit(b.Charge())
+	force := vector.Zero3()
+	if charge == 0 {
+		return force
+	}
+	ot.calculateCoulombRecursive(b, k*charge, theta, &force)
+	return force
+}
+
+// calculateCoulombRecursive recursively calculates the electrostatic force (kq is k times the body charge)
+func (ot *Octree) calculateCoulombRecursive(b body.Body, kq, theta float64, force *vector.Vector3) {
+	if ot.absoluteCharge == 0 {
+		return
+	}
+
+	bodyPos := b.Position()
+
+	// Leaf node: sum the contribution of each body
+	if !ot.divided {
+		for _, obj := range ot.objects {
+			// Avoid calculating the force on itself
+			if obj.ID() == b.ID() {
+				continue
+			}
+			objCharge := units.ConvertToStandardUnit(obj.Charge())
+			*force = (*force).Add(coulombForce(ot.separation(bodyPos, obj.Position()), kq*objCharge))
+		}
+		return
+	}
+
+	// If the width/distance ratio is less than theta, approximate with the center of the charges
+	width := ot.bounds.Max.X() - ot.bounds.Min.X()
+	deltaPos := ot.separation(bodyPos, ot.chargeCenter)
+	if (width * width) < (theta * theta * deltaPos.LengthSquared()) {
+		*force = (*force).Add(coulombForce(deltaPos, kq*ot.totalCharge))
+		return
+	}
+
+	// Otherwise, calculate recursively for each child
+	for i := 0; i < 8; i++ {
+		if ot.children[i] != nil {
+			ot.children[i].calculateCoulombRecursive(b, kq, theta, force)
+		}
+	}
+}
+
+// coulombForce returns the force k q1 q2 / r² on a charge whose displacement to the other charge is deltaPos
+// (repulsive for charges of the same sign)
+func coulombForce(deltaPos vector.Vector3, kq1q2 float64) vector.Vector3 {
+	distanceSquared := deltaPos.LengthSquared()
+
+	// Avoid division by zero
+	if distanceSquared <= 1e-10 {
+		return vector.Zero3()
+	}
+
+	distance := math.Sqrt(distanceSquared)
+	return deltaPos.Scale(-kq1q2 / (distanceSquared * distance))
+}
+
 // CalculateGravity calculates the gravitational force on a body using the Barnes-Hut algorithm
 func (ot *Octree) CalculateGravity(b body.Body, theta float64) vector.Vector3 {
 	ot.mutex.RLock()
@@ -519,7 +800,7 @@ func (ot *Octree) calculateGravityRecursive(b body.Body, theta float64, force *v
 
 	// Calculate the node width and the distance from the body to the center of mass
 	width := ot.bounds.Max.X() - ot.bounds.Min.X()
-	deltaPos := ot.centerOfMass.Sub(b.Position())
+	deltaPos := ot.separation(b.Position(), ot.centerOfMass)
 	distanceSquared := deltaPos.LengthSquared()
 
 	// Avoid division by zero
@@ -556,7 +837,7 @@ func (ot *Octree) calculateLeafNodeGravity(b body.Body, force *vector.Vector3) {
 		}
 
 		// Calculate the direction vector
-		deltaPos := obj.Position().Sub(bodyPos)
+		deltaPos := ot.separation(bodyPos, obj.Position())
 		distanceSquared := deltaPos.LengthSquared()
 
 		// Avoid division by zero
@@ -587,7 +868,7 @@ func (ot *Octree) approximateGravityWithCenterOfMass(b body.Body, force *vector.
 	bodyPos := b.Position()
 
 	// Calculate the direction vector
-	deltaPos := ot.centerOfMass.Sub(bodyPos)
+	deltaPos := ot.separation(bodyPos, ot.centerOfMass)
 	distanceSquared := deltaPos.LengthSquared()
 
 	// Avoid division by zero
@@ -606,3 +887,125 @@ func (ot *Octree) approximateGravityWithCenterOfMass(b body.Body, force *vector.
 	forceVector := *force
 	*force = forceVector.Add(direction.Scale(forceMagnitude))
 }
+
+// CalculatePotential calculates the gravitational potential energy of a body (J) with all the other bodies of
+// the octree, -G m Σ m_i / r_i, using the Barnes-Hut algorithm
+func (ot *Octree) CalculatePotential(b body.Body, theta, g float64) float64 {
+	ot.mutex.RLock()
+	defer ot.mutex.RUnlock()
+
+	return -g * units.ConvertToStandardUnit(b.Mass()) * ot.calculatePotentialRecursive(b, theta)
+}
+
+// calculatePotentialRecursive recursively calculates the sum of m_i / r_i over the bodies of the node
+func (ot *Octree) calculatePotentialRecursive(b body.Body, theta float64) float64 {
+	sum := 0.0
+	if !ot.divided || ot.totalMass == 0 {
+		for _, obj := range ot.objects {
+			if obj.ID() == b.ID() {
+				continue
+			}
+			distanceSquared := ot.separation(b.Position(), obj.Position()).LengthSquared()
+			if distanceSquared <= 1e-10 {
+				continue
+			}
+			sum += units.ConvertToStandardUnit(obj.Mass()) / math.Sqrt(distanceSquared)
+		}
+		return sum
+	}
+
+	// Approximate the node with its center of mass if it is far enough
+	// (a body at the center of mass of the node is never far enough: the children are visited instead)
+	width := ot.bounds.Max.X() - ot.bounds.Min.X()
+	distanceSquared := ot.separation(b.Position(), ot.centerOfMass).LengthSquared()
+	if distanceSquared >= 1e-10 && (width*width) < (theta*theta*distanceSquared) {
+		return ot.totalMass / math.Sqrt(distanceSquared)
+	}
+
+	for i := 0; i < 8; i++ {
+		if ot.children[i] != nil && ot.children[i].totalMass > 0 {
+			sum += ot.children[i].calculatePotentialRecursive(b, theta)
+		}
+	}
+	return sum
+}
+
+// CalculateShortRangeGravity calculates the short-range part of the gravitational force on a body
+// using the Barnes-Hut algorithm. The Newtonian force of every body or node is scaled by
+// kernel(distance), and nodes farther than cutoff from the body are skipped.
+// It is used by TreePM solvers, where the long-range part is computed on a mesh.
+func (ot *Octree) CalculateShortRangeGravity(b body.Body, theta, g, cutoff float64, kernel func(distance float64) float64) vector.Vector3 {
+	ot.mutex.RLock()
+	defer ot.mutex.RUnlock()
+
+	force := vector.Zero3()
+	ot.calculateShortRangeRecursive(b, theta, g, cutoff, kernel, &force)
+	return force
+}
+
+// calculateShortRangeRecursive recursively calculates the short-range gravitational force
+func (ot *Octree) calculateShortRangeRecursive(b body.Body, theta, g, cutoff float64, kernel func(distance float64) float64, force *vector.Vector3) {
+	if ot.totalMass == 0 || ot.distanceToBounds(b.Position()) > cutoff {
+		return
+	}
+
+	bodyMass := units.ConvertToStandardUnit(b.Mass())
+	bodyPos := b.Position()
+
+	// Leaf node: sum the contribution of each body
+	if !ot.divided {
+		for _, obj := range ot.objects {
+			// Avoid calculating the force on itself
+			if obj.ID() == b.ID() {
+				continue
+			}
+			objMass := units.ConvertToStandardUnit(obj.Mass())
+			*force = (*force).Add(shortRangeForce(ot.separation(bodyPos, obj.Position()), g*bodyMass*objMass, kernel))
+		}
+		return
+	}
+
+	// If the width/distance ratio is less than theta, approximate with the center of mass
+	width := ot.bounds.Max.X() - ot.bounds.Min.X()
+	deltaPos := ot.separation(bodyPos, ot.centerOfMass)
+	if (width * width) < (theta * theta * deltaPos.LengthSquared()) {
+		*force = (*force).Add(shortRangeForce(deltaPos, g*bodyMass*ot.totalMass, kernel))
+		return
+	}
+
+	// Otherwise, calculate recursively for each child
+	for i := 0; i < 8; i++ {
+		if ot.children[i] != nil {
+			ot.children[i].calculateShortRangeRecursive(b, theta, g, cutoff, kernel, force)
+		}
+	}
+}
+
+// distanceToBounds returns the distance from a point to the closest point of the node bounds
+func (ot *Octree) distanceToBounds(point vector.Vector3) float64 {
+	delta := ot.separation(point, ot.bounds.Center()).ToArray()
+	half := ot.bounds.Size().Scale(0.5).ToArray()
+
+	distanceSquared := 0.0
+	for k := 0; k < 3; k++ {
+		excess := math.Abs(delta[k]) - half[k]
+		if excess > 0 {
+			distanceSquared += excess * excess
+		}
+	}
+	return math.Sqrt(distanceSquared)
+}
+
+// shortRangeForce returns the Newtonian force gm1m2/r^2 along deltaPos scaled by kernel(r)
+func shortRangeForce(deltaPos vector.Vector3, gm1m2 float64, kernel func(distance float64) float64) vector.Vector3 {
+	distanceSquared := deltaPos.LengthSquared()
+
+	// Avoid division by zero
+	if distanceSquared <= 1e-10 {
+		return vector.Zero3()
+	}
+
+	distance := math.Sqrt(distanceSquared)
+	forceMagnitude := gm1m2 / distanceSquared * kernel(distance)
+	return deltaPos.Scale(forceMagnitude / distance)
+}
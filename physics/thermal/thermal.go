@@ -0,0 +1,447 @@
+// Package thermal provides heat transfer between bodies (radiation and conduction) and phase changes
+package thermal
+
+import (
+	"math"
+	"sync"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/google/uuid"
+)
+
+// TaskSubmitter represents an interface for submitting tasks to be executed in parallel
+type TaskSubmitter interface {
+	// Submit submits a task to be executed
+	Submit(task func())
+	// Wait waits for all tasks to be completed
+	Wait()
+}
+
+// SeparationFunc returns the displacement vector from one point to another
+type SeparationFunc func(from, to vector.Vector3) vector.Vector3
+
+// PhaseTransition represents the kind of a phase change
+type PhaseTransition int
+
+const (
+	// Melting is the transition from a solid material to its liquid phase
+	Melting PhaseTransition = iota
+	// Freezing is the transition from a liquid material to its solid phase
+	Freezing
+	// Vaporization is the loss of mass of a body heated above its boiling point
+	Vaporization
+)
+
+// String returns the name of the phase transition
+func (t PhaseTransition) String() string {
+	switch t {
+	case Melting:
+		return "melting"
+	case Freezing:
+		return "freezing"
+	case Vaporization:
+		return "vaporization"
+	default:
+		return "unknown"
+	}
+}
+
+// PhaseChange describes a phase change of a body during a step
+type PhaseChange struct {
+	Body          body.Body       // Body that changed phase
+	Transition    PhaseTransition // Kind of phase change
+	From          body.Material   // Material before the phase change
+	To            body.Material   // Material after the phase change (unchanged for vaporization)
+	Mass          float64         // Mass that changed phase (kg)
+	RemainingMass float64         // Mass of the body after the phase change (kg), zero if it fully vaporized
+	Temperature   float64         // Temperature of the body after the phase change (K)
+}
+
+// Model implements heat transfer between bodies:
+//   - radiative cooling of every body toward the ambient temperature (Stefan-Boltzmann law)
+//   - radiative heating from luminous bodies (stars) with an inverse-square flux
+//   - conduction between touching bodies based on the thermal conductivity of their materials
+//   - melting, freezing and vaporization based on the phase change properties of the materials
+//
+// Luminous bodies keep their temperature: their output is given by their luminosity.
+type Model struct {
+	ambientTemperature float64               // Temperature of the surrounding space (K)
+	luminosities       map[uuid.UUID]float64 // Luminosity of the luminous bodies (W)
+	latentHeat         map[uuid.UUID]float64 // Heat stored toward an incomplete melting or freezing (J)
+	conduction         bool                  // Indicates if conduction between touching bodies is enabled
+	mutex              sync.RWMutex
+}
+
+// NewModel creates a new thermal model with the cosmic microwave background as ambient temperature
+func NewModel() *Model {
+	return &Model{
+		ambientTemperature: constants.CosmicBackgroundTemperature,
+		luminosities:       make(map[uuid.UUID]float64),
+		latentHeat:         make(map[uuid.UUID]float64),
+		conduction:         true,
+	}
+}
+
+// SetAmbientTemperature sets the temperature of the surrounding space
+func (m *Model) SetAmbientTemperature(temperature units.Quantity) {
+	if temperature.Unit().Type() != units.Temperature {
+		panic("Ambient temperature must be a temperature quantity")
+	}
+	m.ambientTemperature = units.ConvertToStandardUnit(temperature)
+}
+
+// GetAmbientTemperature returns the temperature of the surrounding space
+func (m *Model) GetAmbientTemperature() units.Quantity {
+	return units.NewQuantity(m.ambientTemperature, units.Kelvin)
+}
+
+// SetConduction sets whether conduction between touching bodies is enabled
+func (m *Model) SetConduction(enabled bool) {
+	m.conduction = enabled
+}
+
+// IsConductionEnabled returns true if conduction between touching bodies is enabled
+func (m *Model) IsConductionEnabled() bool {
+	return m.conduction
+}
+
+// AddLuminousBody designates a body as a light source (e.g. a star) with the given luminosity
+func (m *Model) AddLuminousBody(b body.Body, luminosity units.Quantity) {
+	if luminosity.Unit().Type() != units.Power {
+		panic("Luminosity must be a power quantity")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.luminosities[b.ID()] = units.ConvertToStandardUnit(luminosity)
+}
+
+// RemoveLuminousBody removes a body from the light sources
+func (m *Model) RemoveLuminousBody(id uuid.UUID) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.luminosities, id)
+}
+
+// Luminosity returns the luminosity of a body (zero if it is not a light source)
+func (m *Model) Luminosity(id uuid.UUID) units.Quantity {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return units.NewQuantity(m.luminosities[id], units.Watt)
+}
+
+// IsLuminous returns true if the body is a light source
+func (m *Model) IsLuminous(id uuid.UUID) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	_, exists := m.luminosities[id]
+	return exists
+}
+
+// Step transfers heat between the bodies over a time interval and returns the phase changes that occurred.
+// The spatial structure is used to find touching bodies for conduction (it can be nil), and the separation
+// function to measure their distance, e.g. across periodic boundaries (nil for Euclidean).
+// Bodies that fully vaporized are reported with a zero remaining mass: removing them is up to the caller.
+func (m *Model) Step(bodies []body.Body, spatialStructure space.SpatialStructure, separation SeparationFunc, dt float64, taskSubmitter TaskSubmitter) []PhaseChange {
+	// Collect the light sources
+	m.mutex.RLock()
+	sources := make([]body.Body, 0, len(m.luminosities))
+	luminosities := make([]float64, 0, len(m.luminosities))
+	for _, b := range bodies {
+		if luminosity, exists := m.luminosities[b.ID()]; exists {
+			sources = append(sources, b)
+			luminosities = append(luminosities, luminosity)
+		}
+	}
+	m.mutex.RUnlock()
+
+	// Radiation in parallel (each body only changes its own temperature)
+	for _, b := range bodies {
+		b := b // Capture the variable for the goroutine
+		if m.IsLuminous(b.ID()) {
+			continue
+		}
+		taskSubmitter.Submit(func() {
+			m.radiate(b, sources, luminosities, dt)
+		})
+	}
+	taskSubmitter.Wait()
+
+	// Conduction between touching bodies (sequentially, since it changes both bodies)
+	if m.conduction && spatialStructure != nil {
+		m.conduct(bodies, spatialStructure, separation, dt)
+	}
+
+	// Phase changes driven by the new temperatures
+	var changes []PhaseChange
+	for _, b := range bodies {
+		if m.IsLuminous(b.ID()) {
+			continue
+		}
+		changes = append(changes, m.changePhase(b)...)
+	}
+	return changes
+}
+
+// LatentHeat returns the heat a body has absorbed toward melting (or released toward freezing)
+// without completing the phase change
+func (m *Model) LatentHeat(id uuid.UUID) units.Quantity {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return units.NewQuantity(m.latentHeat[id], units.Joule)
+}
+
+// AbsorbedPower returns the power absorbed by a body from a light source:
+// P = ε * L / (4π d²) * π r² (absorptivity equals emissivity by Kirchhoff's law)
+func AbsorbedPower(b body.Body, source body.Body, luminosity float64) float64 {
+	distanceSquared := b.Position().Sub(source.Position()).LengthSquared()
+	if distanceSquared <= 0 {
+		return 0
+	}
+
+	radius := units.ConvertToStandardUnit(b.Radius())
+	flux := luminosity / (4 * math.Pi * distanceSquared)
+	return b.Material().Emissivity() * flux * math.Pi * radius * radius
+}
+
+// EmittedPower returns the net power radiated by a body toward space at the ambient temperature:
+// P = ε σ 4π r² (T⁴ - Tamb⁴)
+func EmittedPower(b body.Body, ambientTemperature float64) float64 {
+	radius := units.ConvertToStandardUnit(b.Radius())
+	temperature := units.ConvertToStandardUnit(b.Temperature())
+	area := 4 * math.Pi * radius * radius
+	return b.Material().Emissivity() * constants.StefanBoltzmannConstant * area *
+		(math.Pow(temperature, 4) - math.Pow(ambientTemperature, 4))
+}
+
+// EquilibriumTemperature returns the temperature at which a body radiates as much power as it absorbs
+func EquilibriumTemperature(absorbedPower float64, b body.Body, ambientTemperature float64) float64 {
+	radius := units.ConvertToStandardUnit(b.Radius())
+	area := 4 * math.Pi * radius * radius
+	emissivity := b.Material().Emissivity()
+	if emissivity <= 0 || area <= 0 {
+		return units.ConvertToStandardUnit(b.Temperature())
+	}
+	return math.Pow(absorbedPower/(emissivity*constants.StefanBoltzmannConstant*area)+math.Pow(ambientTemperature, 4), 0.25)
+}
+
+// radiate applies radiative heating and cooling to a body
+func (m *Model) radiate(b body.Body, sources []body.Body, luminosities []float64, dt float64) {
+	heatCapacity := heatCapacity(b)
+	if heatCapacity <= 0 || b.Material().Emissivity() <= 0 {
+		return
+	}
+
+	// Power absorbed from the light sources
+	absorbed := 0.0
+	for i, source := range sources {
+		absorbed += AbsorbedPower(b, source, luminosities[i])
+	}
+
+	// Net power and resulting temperature change
+	net := absorbed - EmittedPower(b, m.ambientTemperature)
+	temperature := units.ConvertToStandardUnit(b.Temperature())
+	newTemperature := temperature + net*dt/heatCapacity
+
+	// Do not overshoot the equilibrium temperature when the time step is large
+	equilibrium := EquilibriumTemperature(absorbed, b, m.ambientTemperature)
+	if (temperature-equilibrium)*(newTemperature-equilibrium) < 0 {
+		newTemperature = equilibrium
+	}
+
+	b.SetTemperature(units.NewQuantity(newTemperature, units.Kelvin))
+}
+
+// conduct exchanges heat between touching bodies.
+// The heat flow is P = k * A * ΔT / d, with k the harmonic mean of the conductivities,
+// A the cross section of the smaller body and d the distance between the centers.
+func (m *Model) conduct(bodies []body.Body, spatialStructure space.SpatialStructure, separation SeparationFunc, dt float64) {
+	// Each pair is handled from one side only, so the neighbors are searched up to the largest body they can touch
+	maxRadius := 0.0
+	for _, b := range bodies {
+		maxRadius = math.Max(maxRadius, units.ConvertToStandardUnit(b.Radius()))
+	}
+
+	for _, a := range bodies {
+		radiusA := units.ConvertToStandardUnit(a.Radius())
+		nearbyBodies := spatialStructure.QuerySphere(a.Position(), radiusA+maxRadius)
+
+		for _, b := range nearbyBodies {
+			// Handle each pair once
+			if a.ID().String() >= b.ID().String() {
+				continue
+			}
+
+			radiusB := units.ConvertToStandardUnit(b.Radius())
+			var distance float64
+			if separation != nil {
+				distance = separation(a.Position(), b.Position()).Length()
+			} else {
+				distance = a.Position().Sub(b.Position()).Length()
+			}
+			if distance > radiusA+radiusB || distance <= 0 {
+				continue
+			}
+
+			conductivityA := a.Material().ThermalConductivity().Value()
+			conductivityB := b.Material().ThermalConductivity().Value()
+			if conductivityA <= 0 || conductivityB <= 0 {
+				continue
+			}
+			conductivity := 2 * conductivityA * conductivityB / (conductivityA + conductivityB)
+
+			minRadius := math.Min(radiusA, radiusB)
+			area := math.Pi * minRadius * minRadius
+
+			temperatureA := units.ConvertToStandardUnit(a.Temperature())
+			temperatureB := units.ConvertToStandardUnit(b.Temperature())
+			heat := conductivity * area * (temperatureB - temperatureA) / distance * dt
+
+			// Do not exchange more heat than needed to reach a common temperature
+			capacityA := heatCapacity(a)
+			capacityB := heatCapacity(b)
+			if capacityA <= 0 || capacityB <= 0 {
+				continue
+			}
+			maxHeat := math.Abs(temperatureB-temperatureA) * capacityA * capacityB / (capacityA + capacityB)
+			if math.Abs(heat) > maxHeat {
+				heat = math.Copysign(maxHeat, heat)
+			}
+
+			a.AddHeat(units.NewQuantity(heat, units.Joule))
+			b.AddHeat(units.NewQuantity(-heat, units.Joule))
+		}
+	}
+}
+
+// changePhase melts, freezes or vaporizes a body whose temperature crossed a phase change threshold.
+// The body stays at the threshold temperature while the latent heat is exchanged.
+func (m *Model) changePhase(b body.Body) []PhaseChange {
+	var changes []PhaseChange
+	if change, changed := m.changeState(b); changed {
+		changes = append(changes, change)
+	}
+	if change, changed := vaporize(b); changed {
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// changeState melts a solid above its melting point or freezes a liquid below it
+func (m *Model) changeState(b body.Body) (PhaseChange, bool) {
+	current, ok := b.Material().(material.Material)
+	if !ok {
+		return PhaseChange{}, false
+	}
+
+	// A solid melts above the threshold, a liquid freezes below it
+	var next material.Material
+	var transition PhaseTransition
+	direction := 1.0
+	if liquid := current.LiquidPhase(); liquid != nil {
+		next, transition = liquid, Melting
+	} else if solid := current.SolidPhase(); solid != nil {
+		next, transition, direction = solid, Freezing, -1.0
+	} else {
+		return PhaseChange{}, false
+	}
+
+	threshold := units.ConvertToStandardUnit(current.MeltingPoint())
+	capacity := heatCapacity(b)
+	if threshold <= 0 || capacity <= 0 {
+		return PhaseChange{}, false
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Heat beyond the threshold goes into the phase change; heat back across the threshold
+	// is first taken from the latent heat already stored
+	temperature := units.ConvertToStandardUnit(b.Temperature())
+	stored := m.latentHeat[b.ID()]
+	excess := direction * (temperature - threshold) * capacity
+	if excess > 0 {
+		stored += excess
+		temperature = threshold
+	} else if stored > 0 {
+		taken := math.Min(stored, -excess)
+		stored -= taken
+		temperature += direction * taken / capacity
+	}
+
+	mass := units.ConvertToStandardUnit(b.Mass())
+	required := mass * current.LatentHeatOfFusion().Value()
+	if stored < required {
+		if stored > 0 {
+			m.latentHeat[b.ID()] = stored
+		} else {
+			delete(m.latentHeat, b.ID())
+		}
+		b.SetTemperature(units.NewQuantity(temperature, units.Kelvin))
+		return PhaseChange{}, false
+	}
+
+	// Complete the phase change: the volume follows the density of the new phase
+	delete(m.latentHeat, b.ID())
+	radius := units.ConvertToStandardUnit(b.Radius())
+	ratio := current.Density().Value() / next.Density().Value()
+	b.SetMaterial(next)
+	b.SetRadius(units.NewQuantity(radius*math.Cbrt(ratio), units.Meter))
+
+	// The leftover heat changes the temperature of the new phase
+	temperature = threshold + direction*(stored-required)/heatCapacity(b)
+	b.SetTemperature(units.NewQuantity(temperature, units.Kelvin))
+
+	return PhaseChange{
+		Body:          b,
+		Transition:    transition,
+		From:          current,
+		To:            next,
+		Mass:          mass,
+		RemainingMass: mass,
+		Temperature:   temperature,
+	}, true
+}
+
+// vaporize removes the mass vaporized by the heat beyond the boiling point: Δm = m c (T - Tb) / Lv.
+// The radius shrinks with the mass at constant density.
+func vaporize(b body.Body) (PhaseChange, bool) {
+	mat := b.Material()
+	boilingPoint := units.ConvertToStandardUnit(mat.BoilingPoint())
+	latentHeat := mat.LatentHeatOfVaporization().Value()
+	temperature := units.ConvertToStandardUnit(b.Temperature())
+	if boilingPoint <= 0 || latentHeat <= 0 || temperature <= boilingPoint {
+		return PhaseChange{}, false
+	}
+
+	mass := units.ConvertToStandardUnit(b.Mass())
+	vaporized := math.Min(heatCapacity(b)*(temperature-boilingPoint)/latentHeat, mass)
+	remaining := mass - vaporized
+
+	if remaining > 0 {
+		radius := units.ConvertToStandardUnit(b.Radius())
+		b.SetMass(units.NewQuantity(remaining, units.Kilogram))
+		b.SetRadius(units.NewQuantity(radius*math.Cbrt(remaining/mass), units.Meter))
+	}
+	b.SetTemperature(units.NewQuantity(boilingPoint, units.Kelvin))
+
+	return PhaseChange{
+		Body:          b,
+		Transition:    Vaporization,
+		From:          mat,
+		To:            mat,
+		Mass:          vaporized,
+		RemainingMass: remaining,
+		Temperature:   boilingPoint,
+	}, true
+}
+
+// heatCapacity returns the heat capacity m * c of a body (J/K)
+func heatCapacity(b body.Body) float64 {
+	return units.ConvertToStandardUnit(b.Mass()) * b.Material().SpecificHeat().Value()
+}
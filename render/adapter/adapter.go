@@ -5,6 +5,7 @@ import (
 	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/frame"
 	"github.com/alexanderi96/go-space-engine/physics/space"
 	"github.com/alexanderi96/go-space-engine/simulation/world"
 )
@@ -24,6 +25,45 @@ func NewColor(r, g, b, a float64) Color {
 	}
 }
 
+// temperatureStops are the reference colors of the temperature scale (K)
+var temperatureStops = []struct {
+	temperature float64
+	color       Color
+}{
+	{0, NewColor(0.0, 0.0, 0.3, 1.0)},      // Deep blue
+	{150, NewColor(0.0, 0.4, 1.0, 1.0)},    // Blue
+	{273.15, NewColor(0.0, 0.9, 0.9, 1.0)}, // Cyan at the melting point of ice
+	{373.15, NewColor(0.5, 0.9, 0.3, 1.0)}, // Green at the boiling point of water
+	{800, NewColor(1.0, 0.2, 0.0, 1.0)},    // Red heat
+	{1500, NewColor(1.0, 0.6, 0.0, 1.0)},   // Orange heat
+	{3000, NewColor(1.0, 1.0, 0.4, 1.0)},   // Yellow heat
+	{6000, NewColor(1.0, 1.0, 1.0, 1.0)},   // White heat
+}
+
+// TemperatureColor returns the color of a temperature (K) on a false color scale,
+// from deep blue for cold bodies to white for incandescent ones
+func TemperatureColor(temperature float64) Color {
+	if temperature <= temperatureStops[0].temperature {
+		return temperatureStops[0].color
+	}
+
+	for i := 1; i < len(temperatureStops); i++ {
+		upper := temperatureStops[i]
+		if temperature < upper.temperature {
+			lower := temperatureStops[i-1]
+			t := (temperature - lower.temperature) / (upper.temperature - lower.temperature)
+			return NewColor(
+				lower.color.R+t*(upper.color.R-lower.color.R),
+				lower.color.G+t*(upper.color.G-lower.color.G),
+				lower.color.B+t*(upper.color.B-lower.color.B),
+				1.0,
+			)
+		}
+	}
+
+	return temperatureStops[len(temperatureStops)-1].color
+}
+
 // Renderer represents an interface for rendering
 type Renderer interface {
 	// Initialize initializes the renderer
@@ -108,6 +148,16 @@ type RenderAdapter interface {
 	SetRenderForces(render bool)
 	// IsRenderForces returns true if force vectors are being rendered
 	IsRenderForces() bool
+
+	// SetColorByTemperature sets whether bodies are colored by their temperature instead of their material
+	SetColorByTemperature(enabled bool)
+	// IsColorByTemperature returns true if bodies are colored by their temperature
+	IsColorByTemperature() bool
+
+	// SetCameraFrame sets the reference frame the camera follows (nil for the inertial frame of the world)
+	SetCameraFrame(f frame.Frame)
+	// GetCameraFrame returns the reference frame the camera follows
+	GetCameraFrame() frame.Frame
 }
 
 // BaseRenderAdapter implements a base adapter for rendering
@@ -119,6 +169,8 @@ type BaseRenderAdapter struct {
 	renderVelocities    bool
 	renderAccelerations bool
 	renderForces        bool
+	colorByTemperature  bool
+	cameraFrame         frame.Frame
 }
 
 // NewBaseRenderAdapter creates a new base adapter for rendering
@@ -131,6 +183,7 @@ func NewBaseRenderAdapter(renderer Renderer) *BaseRenderAdapter {
 		renderVelocities:    false,
 		renderAccelerations: false,
 		renderForces:        false,
+		colorByTemperature:  false,
 	}
 }
 
@@ -261,3 +314,25 @@ func (ra *BaseRenderAdapter) SetRenderForces(render bool) {
 func (ra *BaseRenderAdapter) IsRenderForces() bool {
 	return ra.renderForces
 }
+
+// SetColorByTemperature sets whether bodies are colored by their temperature instead of their material
+func (ra *BaseRenderAdapter) SetColorByTemperature(enabled bool) {
+	ra.colorByTemperature = enabled
+}
+
+// IsColorByTemperature returns true if bodies are colored by their temperature
+func (ra *BaseRenderAdapter) IsColorByTemperature() bool {
+	return ra.colorByTemperature
+}
+
+// SetCameraFrame sets the reference frame the camera follows (nil for the inertial frame of the world).
+// The camera position and target are expressed in this frame, e.g. a body frame keeps a planet in view
+// and a rotating frame keeps a primary and a secondary body still.
+func (ra *BaseRenderAdapter) SetCameraFrame(f frame.Frame) {
+	ra.cameraFrame = f
+}
+
+// GetCameraFrame returns the reference frame the camera follows
+func (ra *BaseRenderAdapter) GetCameraFrame() frame.Frame {
+	return ra.cameraFrame
+}
@@ -4,7 +4,9 @@ package g3n
 import (
 	"time"
 
+	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/frame"
 	"github.com/alexanderi96/go-space-engine/render/adapter"
 	"github.com/alexanderi96/go-space-engine/simulation/world"
 	"github.com/google/uuid"
@@ -29,8 +31,10 @@ func NewColor(r, g, b, a float64) adapter.Color {
 
 // BodyMesh represents a mesh with an associated point light
 type BodyMesh struct {
-	Mesh  *graphic.Mesh
-	Light *light.Point
+	Mesh     *graphic.Mesh
+	Light    *light.Point
+	Material *material.Standard
+	Color    math32.Color // Color of the material of the body
 }
 
 // G3NAdapter is an adapter for rendering with G3N
@@ -42,6 +46,9 @@ type G3NAdapter struct {
 	bodyMeshes map[uuid.UUID]*BodyMesh
 	bgColor    adapter.Color
 	debugMode  bool
+
+	colorByTemperature bool
+	cameraFrame        frame.Frame
 }
 
 // NewG3NAdapter creates a new G3N adapter
@@ -70,11 +77,16 @@ func (ga *G3NAdapter) RenderWorld(w world.World) {
 	// Update the position of meshes
 	for _, b := range w.GetBodies() {
 		if bodyMesh, exists := ga.bodyMeshes[b.ID()]; exists {
+			// The meshes are placed in the frame followed by the camera
 			pos := b.Position()
+			if ga.cameraFrame != nil {
+				pos, _ = frame.StateIn(ga.cameraFrame, b)
+			}
 			bodyMesh.Mesh.SetPosition(float32(pos.X()), float32(pos.Y()), float32(pos.Z()))
 			if bodyMesh.Light != nil {
 				bodyMesh.Light.SetPosition(float32(pos.X()), float32(pos.Y()), float32(pos.Z()))
 			}
+			ga.updateMeshColor(b, bodyMesh)
 		} else {
 			// If the body does not have an associated mesh, create it
 			ga.createMeshForBody(b)
@@ -301,10 +313,28 @@ func (ga *G3NAdapter) createMeshForBody(b body.Body) {
 	ga.scene.Add(mesh)
 
 	// Store the BodyMesh in the map
+	standardMat, _ := mat.(*material.Standard)
 	ga.bodyMeshes[b.ID()] = &BodyMesh{
-		Mesh:  mesh,
-		Light: bodyLight,
+		Mesh:     mesh,
+		Light:    bodyLight,
+		Material: standardMat,
+		Color:    bodyColor,
+	}
+	ga.updateMeshColor(b, ga.bodyMeshes[b.ID()])
+}
+
+// updateMeshColor colors the mesh of a body by its temperature, or restores the color of its material
+func (ga *G3NAdapter) updateMeshColor(b body.Body, bodyMesh *BodyMesh) {
+	if bodyMesh.Material == nil {
+		return
+	}
+
+	color := bodyMesh.Color
+	if ga.colorByTemperature {
+		c := adapter.TemperatureColor(units.ConvertToStandardUnit(b.Temperature()))
+		color = math32.Color{R: float32(c.R), G: float32(c.G), B: float32(c.B)}
 	}
+	bodyMesh.Material.SetColor(&color)
 }
 
 // SetDebugMode sets the debug mode
@@ -367,6 +397,16 @@ func (ga *G3NAdapter) IsRenderForces() bool {
 	return false
 }
 
+// SetColorByTemperature sets whether bodies are colored by their temperature instead of their material
+func (ga *G3NAdapter) SetColorByTemperature(enabled bool) {
+	ga.colorByTemperature = enabled
+}
+
+// IsColorByTemperature returns true if bodies are colored by their temperature
+func (ga *G3NAdapter) IsColorByTemperature() bool {
+	return ga.colorByTemperature
+}
+
 // SetBackgroundColor sets the background color
 func (ga *G3NAdapter) SetBackgroundColor(color adapter.Color) {
 	ga.bgColor = color
@@ -388,3 +428,13 @@ func (ga *G3NAdapter) onWindowResize(evname string, ev interface{}) {
 	gl := ga.app.Gls()
 	gl.Viewport(0, 0, int32(width), int32(height))
 }
+
+// SetCameraFrame sets the reference frame the camera follows (nil for the inertial frame of the world)
+func (ga *G3NAdapter) SetCameraFrame(f frame.Frame) {
+	ga.cameraFrame = f
+}
+
+// GetCameraFrame returns the reference frame the camera follows
+func (ga *G3NAdapter) GetCameraFrame() frame.Frame {
+	return ga.cameraFrame
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/frame"
 	"github.com/alexanderi96/go-space-engine/physics/space"
 	"github.com/alexanderi96/go-space-engine/render/adapter"
 	"github.com/alexanderi96/go-space-engine/simulation/world"
@@ -17,16 +18,18 @@ import (
 
 // RaylibRenderer implements the Renderer interface using Raylib
 type RaylibRenderer struct {
-	width            int32
-	height           int32
-	title            string
-	isInitialized    bool
-	isRunning        bool
-	camera           rl.Camera3D
-	bgColor          adapter.Color
-	mouseCaptured    bool
-	cameraSpeed      float32
-	mouseSensitivity float32
+	width              int32
+	height             int32
+	title              string
+	isInitialized      bool
+	isRunning          bool
+	camera             rl.Camera3D
+	bgColor            adapter.Color
+	mouseCaptured      bool
+	cameraSpeed        float32
+	mouseSensitivity   float32
+	colorByTemperature bool
+	cameraFrame        frame.Frame // Frame of the camera position and target, nil for the inertial frame
 }
 
 // NewRaylibRenderer creates a new Raylib renderer
@@ -79,7 +82,29 @@ func (r *RaylibRenderer) BeginFrame() {
 		B: uint8(r.bgColor.B * 255),
 		A: uint8(r.bgColor.A * 255),
 	})
-	rl.BeginMode3D(r.camera)
+	rl.BeginMode3D(r.inertialCamera())
+}
+
+// inertialCamera returns the camera converted from the frame it follows to the inertial frame of the world
+func (r *RaylibRenderer) inertialCamera() rl.Camera3D {
+	if r.cameraFrame == nil {
+		return r.camera
+	}
+
+	toInertial := func(v rl.Vector3) vector.Vector3 {
+		position, _ := r.cameraFrame.FromFrame(vector.NewVector3(float64(v.X), float64(v.Y), float64(v.Z)), vector.Zero3())
+		return position
+	}
+	toRaylib := func(v vector.Vector3) rl.Vector3 {
+		return rl.Vector3{X: float32(v.X()), Y: float32(v.Y()), Z: float32(v.Z())}
+	}
+
+	camera := r.camera
+	origin := toInertial(rl.Vector3{})
+	camera.Position = toRaylib(toInertial(r.camera.Position))
+	camera.Target = toRaylib(toInertial(r.camera.Target))
+	camera.Up = toRaylib(toInertial(r.camera.Up).Sub(origin))
+	return camera
 }
 
 // EndFrame ends the current frame
@@ -273,7 +298,18 @@ func (r *RaylibRenderer) RenderBody(b body.Body) {
 	color := r.getBodyColor(b)
 
 	// Render based on body type
-	if b.Material() != nil && b.Material().Name() == "Spacecraft" {
+	if compound, ok := b.(body.CompoundShape); ok {
+		// Render each part of compound bodies as a sphere
+		for _, sphere := range compound.CollisionSpheres() {
+			center := rl.Vector3{
+				X: float32(sphere.Center.X()),
+				Y: float32(sphere.Center.Y()),
+				Z: float32(sphere.Center.Z()),
+			}
+			rl.DrawSphere(center, float32(sphere.Radius), color)
+			rl.DrawSphereWires(center, float32(sphere.Radius), 16, 16, rl.White)
+		}
+	} else if b.Material() != nil && b.Material().Name() == "Spacecraft" {
 		// Render spacecraft as a cube
 		rl.DrawCube(position, radius*2, radius*2, radius*2, color)
 		rl.DrawCubeWires(position, radius*2, radius*2, radius*2, rl.White)
@@ -441,8 +477,14 @@ func (r *RaylibRenderer) ProcessEvents() {
 	// Raylib handles events internally, but we can add custom logic here if needed
 }
 
-// getBodyColor returns the appropriate color for a body based on its material
+// getBodyColor returns the appropriate color for a body based on its material,
+// or on its temperature if coloring by temperature is enabled
 func (r *RaylibRenderer) getBodyColor(b body.Body) rl.Color {
+	if r.colorByTemperature {
+		c := adapter.TemperatureColor(units.ConvertToStandardUnit(b.Temperature()))
+		return rl.Color{R: uint8(c.R * 255), G: uint8(c.G * 255), B: uint8(c.B * 255), A: 255}
+	}
+
 	if b.Material() == nil {
 		return rl.Gray
 	}
@@ -556,7 +598,9 @@ func (ra *RaylibAdapter) Run(updateFunc func(deltaTime time.Duration)) {
 
 // RenderWorld renders the world with enhanced features
 func (ra *RaylibAdapter) RenderWorld(w world.World) {
-	// Start frame
+	// Apply the coloring mode and start frame
+	ra.renderer.colorByTemperature = ra.IsColorByTemperature()
+	ra.renderer.cameraFrame = ra.GetCameraFrame()
 	ra.renderer.BeginFrame()
 
 	// Render all bodies
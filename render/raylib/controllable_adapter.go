@@ -2,9 +2,10 @@
 package raylib
 
 import (
-	"math"
+	"fmt"
 	"time"
 
+	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 	"github.com/alexanderi96/go-space-engine/physics/body"
 	"github.com/alexanderi96/go-space-engine/render/adapter"
@@ -55,22 +56,11 @@ func (cra *ControllableRaylibAdapter) updateCameraToFollowSpacecraft() {
 		return
 	}
 
-	// Ottieni la posizione e la rotazione della navicella
+	// Ottieni la posizione della navicella
 	spacecraftPos := cra.controllableBody.Position()
-	spacecraftRot := cra.controllableBody.Rotation()
 
 	// Calcola la direzione in cui la navicella sta guardando
-	// Assumiamo che la direzione iniziale sia (0, 0, -1) e applichiamo la rotazione
-	forward := vector.NewVector3(0, 0, -1)
-
-	// Applica la rotazione Y (yaw)
-	cosY := math.Cos(spacecraftRot.Y())
-	sinY := math.Sin(spacecraftRot.Y())
-	rotatedForward := vector.NewVector3(
-		forward.X()*cosY-forward.Z()*sinY,
-		forward.Y(),
-		forward.X()*sinY+forward.Z()*cosY,
-	)
+	rotatedForward := cra.controllableBody.Forward()
 
 	// Calcola la posizione della camera (dietro la navicella)
 	// Distanza della camera dalla navicella
@@ -227,4 +217,16 @@ func (cra *ControllableRaylibAdapter) DrawUI() {
 			y += 25
 		}
 	}
+
+	// Draw the propellant and the delta-v left
+	if cra.controllableBody != nil {
+		fuel := units.ConvertToStandardUnit(cra.controllableBody.Fuel())
+		deltaV := units.ConvertToStandardUnit(cra.controllableBody.DeltaV())
+		color := rl.White
+		if fuel <= 0 {
+			color = rl.Red
+		}
+		rl.DrawText(fmt.Sprintf("Fuel: %.1f kg", fuel), 10, int32(rl.GetScreenHeight())-60, 20, color)
+		rl.DrawText(fmt.Sprintf("Delta-v: %.1f m/s", deltaV), 10, int32(rl.GetScreenHeight())-35, 20, color)
+	}
 }
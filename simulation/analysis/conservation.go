@@ -0,0 +1,187 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// Drift contains the relative errors of the conserved quantities of a world at a time, with respect to the
+// start of the monitoring
+type Drift struct {
+	Time            float64 // s
+	Energy          float64 // |E - E0| / |E0|
+	Momentum        float64 // |P - P0| divided by the initial sum of the momenta magnitudes Σ m |v|
+	AngularMomentum float64 // |L - L0| divided by the initial sum of the angular momenta magnitudes Σ m |r × v|
+	CenterOfMass    float64 // Distance of the center of mass from its uniform motion, divided by the initial size of the system
+}
+
+// DriftLimits are the largest relative errors accepted by a ConservationMonitor (0 disables a check)
+type DriftLimits struct {
+	Energy          float64
+	Momentum        float64
+	AngularMomentum float64
+	CenterOfMass    float64
+}
+
+// ConservationMonitor measures the drift of the energy, the momenta and the center of mass of a world.
+// Added to a world as a step observer, it records the drift every N steps and reports the first time a drift
+// exceeds its limit, as an error or a panic.
+type ConservationMonitor struct {
+	method   world.PotentialMethod
+	interval int // Steps between two measurements
+	limits   DriftLimits
+	panics   bool // Panic instead of recording the error when a limit is exceeded
+	steps    int
+	initial  world.Diagnostics
+
+	// Scales of the relative errors, measured at the start
+	energyScale          float64
+	momentumScale        float64
+	angularMomentumScale float64
+	size                 float64
+
+	records []Drift
+	err     error
+}
+
+// NewConservationMonitor creates a monitor of the current state of a world, measuring the drift every interval
+// steps with a method for the potential energy
+func NewConservationMonitor(w world.World, method world.PotentialMethod, interval int) *ConservationMonitor {
+	if interval < 1 {
+		interval = 1
+	}
+	m := &ConservationMonitor{method: method, interval: interval}
+	m.Reset(w)
+	return m
+}
+
+// Reset takes the current state of a world as the reference of the drift and discards the records
+func (m *ConservationMonitor) Reset(w world.World) {
+	m.initial = w.ComputeDiagnostics(m.method)
+	m.steps = 0
+	m.records = nil
+	m.err = nil
+
+	m.energyScale = math.Abs(m.initial.TotalEnergy())
+	if m.energyScale == 0 {
+		m.energyScale = m.initial.KineticEnergy + math.Abs(m.initial.PotentialEnergy)
+	}
+	m.momentumScale, m.angularMomentumScale, m.size = 0, 0, 0
+	for _, b := range w.GetBodies() {
+		mass := units.ConvertToStandardUnit(b.Mass())
+		m.momentumScale += mass * b.Velocity().Length()
+		m.angularMomentumScale += mass * b.Position().Cross(b.Velocity()).Length()
+		m.size += mass * b.Position().Sub(m.initial.CenterOfMass).LengthSquared()
+	}
+	if m.initial.Mass > 0 {
+		m.size = math.Sqrt(m.size / m.initial.Mass)
+	}
+}
+
+// GetInitial returns the diagnostics of the world at the start of the monitoring
+func (m *ConservationMonitor) GetInitial() world.Diagnostics {
+	return m.initial
+}
+
+// SetLimits sets the largest relative errors accepted
+func (m *ConservationMonitor) SetLimits(limits DriftLimits) {
+	m.limits = limits
+}
+
+// GetLimits returns the largest relative errors accepted
+func (m *ConservationMonitor) GetLimits() DriftLimits {
+	return m.limits
+}
+
+// SetPanicOnViolation sets whether the monitor panics when a limit is exceeded, instead of recording the error
+func (m *ConservationMonitor) SetPanicOnViolation(panics bool) {
+	m.panics = panics
+}
+
+// IsPanicOnViolation returns true if the monitor panics when a limit is exceeded
+func (m *ConservationMonitor) IsPanicOnViolation() bool {
+	return m.panics
+}
+
+// Measure returns the current drift of a world, without recording it
+func (m *ConservationMonitor) Measure(w world.World) Drift {
+	d := w.ComputeDiagnostics(m.method)
+	elapsed := d.Time - m.initial.Time
+	expectedCenter := m.initial.CenterOfMass.Add(m.initial.CenterOfMassVelocity.Scale(elapsed))
+	return Drift{
+		Time:            d.Time,
+		Energy:          relativeError(math.Abs(d.TotalEnergy()-m.initial.TotalEnergy()), m.energyScale),
+		Momentum:        relativeError(d.Momentum.Sub(m.initial.Momentum).Length(), m.momentumScale),
+		AngularMomentum: relativeError(d.AngularMomentum.Sub(m.initial.AngularMomentum).Length(), m.angularMomentumScale),
+		CenterOfMass:    relativeError(d.CenterOfMass.Sub(expectedCenter).Length(), m.size),
+	}
+}
+
+// relativeError divides an error by a scale, or returns it unchanged if the scale is zero
+func relativeError(err, scale float64) float64 {
+	if scale == 0 {
+		return err
+	}
+	return err / scale
+}
+
+// ObserveStep measures and checks the drift of a world every interval steps
+func (m *ConservationMonitor) ObserveStep(w world.World, dt float64) {
+	m.steps++
+	if m.steps%m.interval != 0 {
+		return
+	}
+	drift := m.Measure(w)
+	m.records = append(m.records, drift)
+	if err := m.check(drift); err != nil && m.err == nil {
+		m.err = err
+		if m.panics {
+			panic(err)
+		}
+	}
+}
+
+// check returns an error if a drift exceeds its limit
+func (m *ConservationMonitor) check(drift Drift) error {
+	checks := []struct {
+		name         string
+		value, limit float64
+	}{
+		{"energy", drift.Energy, m.limits.Energy},
+		{"momentum", drift.Momentum, m.limits.Momentum},
+		{"angular momentum", drift.AngularMomentum, m.limits.AngularMomentum},
+		{"center of mass", drift.CenterOfMass, m.limits.CenterOfMass},
+	}
+	for _, c := range checks {
+		if c.limit > 0 && c.value > c.limit {
+			return fmt.Errorf("%s drift %g exceeds the limit %g at %g s", c.name, c.value, c.limit, drift.Time)
+		}
+	}
+	return nil
+}
+
+// GetRecords returns the recorded drifts, in chronological order
+func (m *ConservationMonitor) GetRecords() []Drift {
+	return m.records
+}
+
+// MaxDrift returns the largest recorded drift of each quantity, at the time of the last record
+func (m *ConservationMonitor) MaxDrift() Drift {
+	var max Drift
+	for _, d := range m.records {
+		max.Time = d.Time
+		max.Energy = math.Max(max.Energy, d.Energy)
+		max.Momentum = math.Max(max.Momentum, d.Momentum)
+		max.AngularMomentum = math.Max(max.AngularMomentum, d.AngularMomentum)
+		max.CenterOfMass = math.Max(max.CenterOfMass, d.CenterOfMass)
+	}
+	return max
+}
+
+// Err returns the first violation of a limit, or nil
+func (m *ConservationMonitor) Err() error {
+	return m.err
+}
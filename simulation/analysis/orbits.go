@@ -0,0 +1,310 @@
+// Package analysis provides tools that extract statistics from the runs of a world
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+	"github.com/google/uuid"
+)
+
+// Apsis is the point of an orbit closest to or farthest from the central body
+type Apsis int
+
+const (
+	// Periapsis is the point of the orbit closest to the central body
+	Periapsis Apsis = iota
+	// Apoapsis is the point of the orbit farthest from the central body
+	Apoapsis
+)
+
+// String returns the name of the apsis
+func (a Apsis) String() string {
+	if a == Apoapsis {
+		return "apoapsis"
+	}
+	return "periapsis"
+}
+
+// Passage is the passage of a body through an apsis of its orbit.
+// The time and the state are interpolated between the steps that bracket it.
+type Passage struct {
+	Body     body.Body
+	Apsis    Apsis
+	Time     float64        // s
+	Position vector.Vector3 // Relative to the central body (m)
+	Velocity vector.Vector3 // Relative to the central body (m/s)
+}
+
+// Distance returns the distance of the body from the central body at the passage (m)
+func (p Passage) Distance() float64 {
+	return p.Position.Length()
+}
+
+// ElementsSample is the osculating orbit of a body at a time
+type ElementsSample struct {
+	Time     float64 // s
+	Elements orbit.Elements
+}
+
+// orbitTrack is the record of a body followed by an OrbitRecorder
+type orbitTrack struct {
+	body      body.Body
+	times     []float64        // Times of the last three states (s)
+	positions []vector.Vector3 // Last three positions relative to the central body (m)
+	radial    float64          // Radial velocity between the last two states (m/s)
+	passages  []Passage
+	samples   []ElementsSample
+}
+
+// OrbitRecorder records the orbits of some bodies around a central body while a world runs.
+// Added to a world as a step observer, it detects the periapsis and apoapsis passages from the sign changes
+// of the radial velocity and samples the osculating orbital elements.
+// The radial velocity is measured from the distances of consecutive steps, so the passages do not depend on
+// the velocity returned by the integrator (the Verlet one lags one step behind the position).
+type OrbitRecorder struct {
+	central  body.Body
+	tracks   map[uuid.UUID]*orbitTrack
+	order    []uuid.UUID
+	interval int // Steps between two samples of the orbital elements
+	steps    int
+}
+
+// NewOrbitRecorder creates a recorder of the orbits around a central body, sampling the elements at each step
+func NewOrbitRecorder(central body.Body) *OrbitRecorder {
+	return &OrbitRecorder{
+		central:  central,
+		tracks:   make(map[uuid.UUID]*orbitTrack),
+		interval: 1,
+	}
+}
+
+// GetCentral returns the central body
+func (r *OrbitRecorder) GetCentral() body.Body {
+	return r.central
+}
+
+// Track starts recording the orbits of some bodies
+func (r *OrbitRecorder) Track(bodies ...body.Body) {
+	for _, b := range bodies {
+		if _, exists := r.tracks[b.ID()]; exists {
+			continue
+		}
+		r.tracks[b.ID()] = &orbitTrack{body: b}
+		r.order = append(r.order, b.ID())
+	}
+}
+
+// Remove stops recording the orbit of a body and discards its record
+func (r *OrbitRecorder) Remove(id uuid.UUID) {
+	if _, exists := r.tracks[id]; !exists {
+		return
+	}
+	delete(r.tracks, id)
+	for i, tracked := range r.order {
+		if tracked == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// GetTracked returns the recorded bodies, in the order they were added
+func (r *OrbitRecorder) GetTracked() []body.Body {
+	bodies := make([]body.Body, 0, len(r.order))
+	for _, id := range r.order {
+		bodies = append(bodies, r.tracks[id].body)
+	}
+	return bodies
+}
+
+// SetSampleInterval sets the number of steps between two samples of the orbital elements
+func (r *OrbitRecorder) SetSampleInterval(steps int) {
+	if steps < 1 {
+		steps = 1
+	}
+	r.interval = steps
+}
+
+// GetSampleInterval returns the number of steps between two samples of the orbital elements
+func (r *OrbitRecorder) GetSampleInterval() int {
+	return r.interval
+}
+
+// ObserveStep records the state of the tracked bodies after a step of a world
+func (r *OrbitRecorder) ObserveStep(w world.World, dt float64) {
+	sample := r.steps%r.interval == 0
+	r.steps++
+	r.Record(w.GetTime(), sample)
+}
+
+// Record records the state of the tracked bodies at a time, sampling their orbital elements if requested.
+// It is called by ObserveStep and can be used directly when the bodies are advanced outside a world.
+func (r *OrbitRecorder) Record(time float64, sample bool) {
+	for _, id := range r.order {
+		track := r.tracks[id]
+		position := track.body.Position().Sub(r.central.Position())
+		if n := len(track.times); n > 0 && time <= track.times[n-1] {
+			continue
+		}
+		if n := len(track.times); n == 3 {
+			track.times, track.positions = track.times[1:], track.positions[1:]
+		}
+		track.times = append(track.times, time)
+		track.positions = append(track.positions, position)
+
+		if sample {
+			velocity := track.body.Velocity().Sub(r.central.Velocity())
+			mu := orbit.GravitationalParameter(r.central, track.body)
+			track.samples = append(track.samples, ElementsSample{Time: time, Elements: orbit.FromStateVectors(position, velocity, mu)})
+		}
+
+		n := len(track.times)
+		if n < 2 {
+			continue
+		}
+		radial := (position.Length() - track.positions[n-2].Length()) / (time - track.times[n-2])
+
+		// Periapsis: the radial velocity changes sign from negative to positive, apoapsis: the opposite.
+		// The radial velocities are those of the midpoints of the last two intervals.
+		periapsis := track.radial < 0 && radial >= 0
+		apoapsis := track.radial > 0 && radial <= 0
+		if n == 3 && (periapsis || apoapsis) {
+			apsis := Periapsis
+			if apoapsis {
+				apsis = Apoapsis
+			}
+			previous := (track.times[0] + track.times[1]) / 2
+			current := (track.times[1] + track.times[2]) / 2
+			t := previous + track.radial/(track.radial-radial)*(current-previous)
+			p, v := interpolate(track.times, track.positions, t)
+			track.passages = append(track.passages, Passage{Body: track.body, Apsis: apsis, Time: t, Position: p, Velocity: v})
+		}
+		track.radial = radial
+	}
+}
+
+// interpolate returns the position and the velocity at a time of the parabola through three states
+func interpolate(times []float64, positions []vector.Vector3, t float64) (vector.Vector3, vector.Vector3) {
+	position, velocity := vector.Zero3(), vector.Zero3()
+	for i := 0; i < 3; i++ {
+		j, k := (i+1)%3, (i+2)%3
+		denominator := (times[i] - times[j]) * (times[i] - times[k])
+		weight := (t - times[j]) * (t - times[k]) / denominator
+		derivative := (2*t - times[j] - times[k]) / denominator
+		position = position.Add(positions[i].Scale(weight))
+		velocity = velocity.Add(positions[i].Scale(derivative))
+	}
+	return position, velocity
+}
+
+// GetPassages returns the apsis passages of a body, in chronological order
+func (r *OrbitRecorder) GetPassages(id uuid.UUID) []Passage {
+	if track, exists := r.tracks[id]; exists {
+		return track.passages
+	}
+	return nil
+}
+
+// GetElements returns the samples of the orbital elements of a body, in chronological order
+func (r *OrbitRecorder) GetElements(id uuid.UUID) []ElementsSample {
+	if track, exists := r.tracks[id]; exists {
+		return track.samples
+	}
+	return nil
+}
+
+// apsisPassages returns the passages of a body through one apsis
+func (r *OrbitRecorder) apsisPassages(id uuid.UUID, apsis Apsis) []Passage {
+	var passages []Passage
+	for _, p := range r.GetPassages(id) {
+		if p.Apsis == apsis {
+			passages = append(passages, p)
+		}
+	}
+	return passages
+}
+
+// Periods returns the durations of the complete orbits of a body (s), measured between consecutive periapsis
+// passages
+func (r *OrbitRecorder) Periods(id uuid.UUID) []float64 {
+	passages := r.apsisPassages(id, Periapsis)
+	if len(passages) < 2 {
+		return nil
+	}
+	periods := make([]float64, len(passages)-1)
+	for i := range periods {
+		periods[i] = passages[i+1].Time - passages[i].Time
+	}
+	return periods
+}
+
+// Period returns the mean period of a body (s), from its periapsis passages or, if it passed its periapsis
+// less than twice, from its apoapsis passages. It returns 0 before a complete orbit.
+func (r *OrbitRecorder) Period(id uuid.UUID) float64 {
+	for _, apsis := range []Apsis{Periapsis, Apoapsis} {
+		passages := r.apsisPassages(id, apsis)
+		if n := len(passages); n >= 2 {
+			return (passages[n-1].Time - passages[0].Time) / float64(n-1)
+		}
+	}
+	return 0
+}
+
+// PrecessionRate returns the mean rate of the apsidal precession of a body (rad/s), the rotation of the
+// direction of its periapsis around its angular momentum, positive in the direction of motion.
+// It returns 0 before two periapsis passages.
+func (r *OrbitRecorder) PrecessionRate(id uuid.UUID) float64 {
+	passages := r.apsisPassages(id, Periapsis)
+	n := len(passages)
+	if n < 2 {
+		return 0
+	}
+
+	angle := 0.0
+	for i := 1; i < n; i++ {
+		previous, current := passages[i-1].Position, passages[i].Position
+		axis := current.Cross(passages[i].Velocity).Normalize()
+		angle += math.Atan2(previous.Cross(current).Dot(axis), previous.Dot(current))
+	}
+	return angle / (passages[n-1].Time - passages[0].Time)
+}
+
+// WriteCSV writes the samples of the orbital elements as CSV, one row per body and time
+func (r *OrbitRecorder) WriteCSV(w io.Writer) error {
+	header := "body,time_s,semi_major_axis_m,eccentricity,inclination_rad,longitude_of_ascending_node_rad,argument_of_periapsis_rad,true_anomaly_rad"
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+	for _, id := range r.order {
+		for _, sample := range r.tracks[id].samples {
+			e := sample.Elements
+			_, err := fmt.Fprintf(w, "%s,%g,%g,%g,%g,%g,%g,%g\n",
+				id, sample.Time, e.SemiMajorAxis, e.Eccentricity, e.Inclination,
+				e.LongitudeOfAscendingNode, e.ArgumentOfPeriapsis, e.TrueAnomaly)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SaveCSV saves the samples of the orbital elements to a CSV file
+func (r *OrbitRecorder) SaveCSV(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteCSV(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -32,11 +32,14 @@ type Config struct {
 	// World boundaries units
 	WorldBoundsUnit units.Unit `json:"worldBoundsUnit"` // Unit for world boundaries (default: Meter)
 
+	// Periodic boundaries configuration
+	PeriodicBoundaries [3]bool `json:"periodicBoundaries"` // Axes (X, Y, Z) whose boundaries wrap around
+
 	// Physics configuration
 	Restitution float64 `json:"restitution"` // Coefficient of restitution (elasticity)
 
 	// Integrator configuration
-	IntegratorType string `json:"integratorType"` // Integrator type ("euler", "verlet", "rk4")
+	IntegratorType string `json:"integratorType"` // Integrator type ("euler", "verlet", "rk4", "relativistic")
 }
 
 // NewDefaultConfig creates a new configuration with default values
@@ -92,6 +95,11 @@ func (c *Config) GetWorldBounds() *space.AABB {
 	return space.NewAABB(minConverted, maxConverted)
 }
 
+// GetPeriodicity returns the periodic axes of the world boundaries
+func (c *Config) GetPeriodicity() space.Periodicity {
+	return space.Periodicity(c.PeriodicBoundaries)
+}
+
 // GetTimeStepQuantity returns the time step as a Quantity
 func (c *Config) GetTimeStepQuantity() units.Quantity {
 	return units.NewQuantity(c.TimeStep, units.Second)
@@ -204,6 +212,12 @@ func (b *SimulationBuilder) WithWorldBoundsWithUnit(min, max vector.Vector3, uni
 	return b
 }
 
+// WithPeriodicBoundaries sets which axes of the world boundaries wrap around
+func (b *SimulationBuilder) WithPeriodicBoundaries(x, y, z bool) *SimulationBuilder {
+	b.config.PeriodicBoundaries = [3]bool{x, y, z}
+	return b
+}
+
 // WithRestitution sets the coefficient of restitution
 func (b *SimulationBuilder) WithRestitution(restitution float64) *SimulationBuilder {
 	b.config.Restitution = restitution
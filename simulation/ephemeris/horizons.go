@@ -0,0 +1,264 @@
+// Package ephemeris loads the states of real solar-system bodies from JPL Horizons vector tables
+package ephemeris
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+)
+
+// State is the state of a body at an epoch, relative to the center of the table
+type State struct {
+	Epoch    float64        // Julian date (TDB)
+	Position vector.Vector3 // Position (m)
+	Velocity vector.Vector3 // Velocity (m/s)
+}
+
+// Table is a vector table exported from JPL Horizons (text or CSV format) for a target body
+type Table struct {
+	Target   string  // Name of the target body
+	TargetID int     // Horizons ID of the target body (e.g. 399 for the Earth)
+	Center   string  // Name of the center body
+	CenterID int     // Horizons ID of the center body (e.g. 10 for the Sun, 0 for the solar system barycenter)
+	Mass     float64 // Mass of the target body (kg), 0 if unknown
+	Radius   float64 // Mean radius of the target body (m), 0 if unknown
+	States   []State // States sorted by epoch
+}
+
+var (
+	targetPattern   = regexp.MustCompile(`Target body name:\s*(.+?)\s*\((-?\d+)\)`)
+	centerPattern   = regexp.MustCompile(`Center body name:\s*(.+?)\s*\((-?\d+)\)`)
+	unitsPattern    = regexp.MustCompile(`Output units\s*:\s*([A-Za-z]+-[A-Za-z]+)`)
+	massPattern     = regexp.MustCompile(`Mass,?\s*x?\s*10\^(\d+)\s*\(?kg\)?\s*=\s*~?([0-9.]+)`)
+	radiusPattern   = regexp.MustCompile(`(?i)mean radius,?\s*\(?km\)?\s*=\s*([0-9.]+)`)
+	recordPattern   = regexp.MustCompile(`^\s*([0-9]+\.[0-9]+)\s*=`)
+	componentRegexp = regexp.MustCompile(`\b(VX|VY|VZ|X|Y|Z)\s*=\s*([-+]?[0-9.]+(?:[eE][-+]?[0-9]+)?)`)
+)
+
+// LoadFromFile loads a Horizons vector table from a file
+func LoadFromFile(filename string) (*Table, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads a Horizons vector table in text or CSV format (CSV_FORMAT=YES).
+// The output units can be KM-S, KM-D or AU-D; the masses and radii are read from the header when present,
+// otherwise they are taken from the physical data of the major bodies.
+func Parse(r io.Reader) (*Table, error) {
+	table := &Table{}
+	lengthUnit, velocityUnit := units.Unit(units.Kilometer), units.Unit(units.KilometerPerSecond)
+	columns := []string{"JDTDB", "CALENDAR", "X", "Y", "Z", "VX", "VY", "VZ"}
+
+	inData := false
+	var current map[string]float64
+	var epoch float64
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		state, err := newState(epoch, current, lengthUnit, velocityUnit)
+		if err != nil {
+			return err
+		}
+		table.States = append(table.States, state)
+		current = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "$$SOE":
+			inData = true
+			continue
+		case trimmed == "$$EOE":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			inData = false
+			continue
+		}
+
+		if !inData {
+			if match := targetPattern.FindStringSubmatch(line); match != nil {
+				table.Target = match[1]
+				table.TargetID, _ = strconv.Atoi(match[2])
+			}
+			if match := centerPattern.FindStringSubmatch(line); match != nil {
+				table.Center = match[1]
+				table.CenterID, _ = strconv.Atoi(match[2])
+			}
+			if match := unitsPattern.FindStringSubmatch(line); match != nil {
+				var err error
+				if lengthUnit, velocityUnit, err = outputUnits(match[1]); err != nil {
+					return nil, err
+				}
+			}
+			if match := massPattern.FindStringSubmatch(line); match != nil && table.Mass == 0 {
+				exponent, _ := strconv.Atoi(match[1])
+				mantissa, _ := strconv.ParseFloat(match[2], 64)
+				table.Mass, _ = strconv.ParseFloat(fmt.Sprintf("%ge%d", mantissa, exponent), 64)
+			}
+			if match := radiusPattern.FindStringSubmatch(line); match != nil && table.Radius == 0 {
+				radius, _ := strconv.ParseFloat(match[1], 64)
+				table.Radius = units.ConvertToStandardUnit(units.NewQuantity(radius, units.Kilometer))
+			}
+			if strings.Contains(trimmed, "JDTDB") && strings.Contains(trimmed, ",") {
+				columns = csvColumns(trimmed)
+			}
+			continue
+		}
+
+		if strings.Contains(trimmed, ",") {
+			// CSV record: one line per epoch
+			fields := strings.Split(trimmed, ",")
+			values := make(map[string]float64)
+			for i, name := range columns {
+				if i >= len(fields) {
+					break
+				}
+				if value, err := strconv.ParseFloat(strings.TrimSpace(fields[i]), 64); err == nil {
+					values[name] = value
+				}
+			}
+			state, err := newState(values["JDTDB"], values, lengthUnit, velocityUnit)
+			if err != nil {
+				return nil, err
+			}
+			table.States = append(table.States, state)
+			continue
+		}
+
+		// Text record: the epoch line followed by the components
+		if match := recordPattern.FindStringSubmatch(line); match != nil {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			epoch, _ = strconv.ParseFloat(match[1], 64)
+			current = make(map[string]float64)
+			continue
+		}
+		if current != nil {
+			for _, match := range componentRegexp.FindAllStringSubmatch(line, -1) {
+				current[match[1]], _ = strconv.ParseFloat(match[2], 64)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(table.States) == 0 {
+		return nil, fmt.Errorf("no state vectors between $$SOE and $$EOE")
+	}
+	sort.Slice(table.States, func(i, j int) bool {
+		return table.States[i].Epoch < table.States[j].Epoch
+	})
+
+	// Fall back on the physical data of the major bodies
+	if data, ok := MajorBodies[table.TargetID]; ok {
+		if table.Mass == 0 {
+			table.Mass = data.Mass
+		}
+		if table.Radius == 0 {
+			table.Radius = data.Radius
+		}
+	}
+	return table, nil
+}
+
+// outputUnits returns the units of length and velocity of the Horizons output units
+func outputUnits(name string) (units.Unit, units.Unit, error) {
+	switch strings.ToUpper(name) {
+	case "KM-S":
+		return units.Kilometer, units.KilometerPerSecond, nil
+	case "KM-D":
+		return units.Kilometer, units.KilometerPerDay, nil
+	case "AU-D":
+		return units.AstronomicalUnit, units.AstronomicalUnitPerDay, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported output units %q", name)
+	}
+}
+
+// csvColumns returns the names of the columns of a CSV header
+func csvColumns(header string) []string {
+	fields := strings.Split(header, ",")
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = strings.ToUpper(strings.TrimSpace(field))
+		if strings.HasPrefix(columns[i], "CALENDAR") {
+			columns[i] = "CALENDAR"
+		}
+	}
+	return columns
+}
+
+// newState creates a state from its components in the output units of the table
+func newState(epoch float64, values map[string]float64, lengthUnit, velocityUnit units.Unit) (State, error) {
+	for _, name := range []string{"X", "Y", "Z", "VX", "VY", "VZ"} {
+		if _, ok := values[name]; !ok {
+			return State{}, fmt.Errorf("missing %s at epoch %v", name, epoch)
+		}
+	}
+	length := func(value float64) float64 {
+		return units.ConvertToStandardUnit(units.NewQuantity(value, lengthUnit))
+	}
+	speed := func(value float64) float64 {
+		return units.ConvertToStandardUnit(units.NewQuantity(value, velocityUnit))
+	}
+	return State{
+		Epoch:    epoch,
+		Position: vector.NewVector3(length(values["X"]), length(values["Y"]), length(values["Z"])),
+		Velocity: vector.NewVector3(speed(values["VX"]), speed(values["VY"]), speed(values["VZ"])),
+	}, nil
+}
+
+// StateAt returns the state at an epoch (Julian date), interpolated between the states of the table with cubic
+// Hermite polynomials. It returns false if the epoch is outside the table.
+func (t *Table) StateAt(epoch float64) (State, bool) {
+	const tolerance = 1e-9 // days
+	n := len(t.States)
+	if n == 0 || epoch < t.States[0].Epoch-tolerance || epoch > t.States[n-1].Epoch+tolerance {
+		return State{}, false
+	}
+
+	i := sort.Search(n, func(i int) bool { return t.States[i].Epoch >= epoch-tolerance })
+	if i < n && t.States[i].Epoch-epoch <= tolerance {
+		return t.States[i], true
+	}
+
+	// Hermite interpolation between the states before and after the epoch
+	a, b := t.States[i-1], t.States[i]
+	h := units.ConvertToStandardUnit(units.NewQuantity(b.Epoch-a.Epoch, units.Day))
+	s := (epoch - a.Epoch) / (b.Epoch - a.Epoch)
+	s2, s3 := s*s, s*s*s
+	h00, h10, h01, h11 := 2*s3-3*s2+1, s3-2*s2+s, -2*s3+3*s2, s3-s2
+	position := a.Position.Scale(h00).Add(a.Velocity.Scale(h10 * h)).Add(b.Position.Scale(h01)).Add(b.Velocity.Scale(h11 * h))
+
+	d00, d10, d01, d11 := (6*s2-6*s)/h, 3*s2-4*s+1, (-6*s2+6*s)/h, 3*s2-2*s
+	velocity := a.Position.Scale(d00).Add(a.Velocity.Scale(d10)).Add(b.Position.Scale(d01)).Add(b.Velocity.Scale(d11))
+
+	return State{Epoch: epoch, Position: position, Velocity: velocity}, true
+}
+
+// JulianDate returns the Julian date of a time (UTC; TDB is about a minute ahead)
+func JulianDate(t time.Time) float64 {
+	return float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5
+}
@@ -0,0 +1,148 @@
+package ephemeris
+
+import (
+	"fmt"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+)
+
+// PhysicalData contains the physical properties of a body
+type PhysicalData struct {
+	Name   string
+	Mass   float64 // kg
+	Radius float64 // Mean radius (m)
+}
+
+// MajorBodies contains the physical data of the Sun, the planets, the Moon and Pluto by Horizons ID
+var MajorBodies = map[int]PhysicalData{
+	10:  {"Sun", 1.98841e30, 6.957e8},
+	199: {"Mercury", 3.3011e23, 2.4397e6},
+	299: {"Venus", 4.8675e24, 6.0518e6},
+	399: {"Earth", 5.97219e24, 6.37101e6},
+	301: {"Moon", 7.34767e22, 1.7374e6},
+	499: {"Mars", 6.4169e23, 3.38992e6},
+	599: {"Jupiter", 1.89813e27, 6.9911e7},
+	699: {"Saturn", 5.68319e26, 5.8232e7},
+	799: {"Uranus", 8.68103e25, 2.5362e7},
+	899: {"Neptune", 1.0241e26, 2.4624e7},
+	999: {"Pluto", 1.307e22, 1.188e6},
+}
+
+// NewBody creates a body with the mass, the radius and the state of the target at an epoch (Julian date).
+// The body is tagged with the name of the target.
+func (t *Table) NewBody(epoch float64, mat body.Material) (*body.RigidBody, error) {
+	if t.Mass <= 0 || t.Radius <= 0 {
+		return nil, fmt.Errorf("unknown mass or radius of %s (%d)", t.Target, t.TargetID)
+	}
+	state, ok := t.StateAt(epoch)
+	if !ok {
+		return nil, fmt.Errorf("epoch %v outside the table of %s", epoch, t.Target)
+	}
+
+	b := body.NewRigidBody(
+		units.NewQuantity(t.Mass, units.Kilogram),
+		units.NewQuantity(t.Radius, units.Meter),
+		state.Position,
+		state.Velocity,
+		mat,
+	)
+	if t.Target != "" {
+		b.AddTag(t.Target)
+	}
+	return b, nil
+}
+
+// System is a set of bodies created from tables sharing the same center at an epoch
+type System struct {
+	epoch  float64
+	tables []*Table
+	bodies []*body.RigidBody
+	center *body.RigidBody
+}
+
+// NewSystem creates the bodies of the tables at an epoch (Julian date).
+// If the center of the tables is a major body without a table, it is created at rest at the origin.
+func NewSystem(epoch float64, mat body.Material, tables ...*Table) (*System, error) {
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no ephemeris tables")
+	}
+	s := &System{epoch: epoch, tables: tables}
+
+	centerID := tables[0].CenterID
+	hasCenter := false
+	for _, table := range tables {
+		if table.CenterID != centerID {
+			return nil, fmt.Errorf("%s is relative to %s, expected %s", table.Target, table.Center, tables[0].Center)
+		}
+		b, err := table.NewBody(epoch, mat)
+		if err != nil {
+			return nil, err
+		}
+		s.bodies = append(s.bodies, b)
+		hasCenter = hasCenter || table.TargetID == centerID
+	}
+
+	if data, ok := MajorBodies[centerID]; ok && !hasCenter {
+		s.center = body.NewRigidBody(
+			units.NewQuantity(data.Mass, units.Kilogram),
+			units.NewQuantity(data.Radius, units.Meter),
+			vector.Zero3(),
+			vector.Zero3(),
+			mat,
+		)
+		s.center.AddTag(data.Name)
+	}
+	return s, nil
+}
+
+// GetEpoch returns the epoch of the system (Julian date)
+func (s *System) GetEpoch() float64 {
+	return s.epoch
+}
+
+// GetBodies returns the bodies of the system, the center first if it was created
+func (s *System) GetBodies() []body.Body {
+	bodies := make([]body.Body, 0, len(s.bodies)+1)
+	if s.center != nil {
+		bodies = append(bodies, s.center)
+	}
+	for _, b := range s.bodies {
+		bodies = append(bodies, b)
+	}
+	return bodies
+}
+
+// GetBody returns the body of a target by name
+func (s *System) GetBody(name string) (body.Body, bool) {
+	for i, table := range s.tables {
+		if table.Target == name {
+			return s.bodies[i], true
+		}
+	}
+	if s.center != nil && s.center.HasTag(name) {
+		return s.center, true
+	}
+	return nil, false
+}
+
+// Drift returns the distance (m) of each body from the position in its table after the elapsed simulated time (s),
+// measured relative to the center. Bodies whose table does not cover the epoch are omitted.
+func (s *System) Drift(elapsed float64) map[string]float64 {
+	epoch := s.epoch + elapsed/units.ConvertToStandardUnit(units.NewQuantity(1, units.Day))
+	origin := vector.Zero3()
+	if s.center != nil {
+		origin = s.center.Position()
+	}
+
+	drift := make(map[string]float64)
+	for i, table := range s.tables {
+		state, ok := table.StateAt(epoch)
+		if !ok {
+			continue
+		}
+		drift[table.Target] = s.bodies[i].Position().Sub(origin).Sub(state.Position).Length()
+	}
+	return drift
+}
@@ -0,0 +1,255 @@
+package trajectory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/google/uuid"
+)
+
+// The binary format is little-endian:
+//
+//	header: magic "GSETRAJ" and version (8 bytes), then the symbols of the time, length, velocity and
+//	        temperature units, each as a uint16 length followed by UTF-8 bytes
+//	chunk:  number of snapshots (uint32), size of the payload in bytes (uint32), payload
+//	payload: for each snapshot its time (float64) and number of states (uint32), then for each state
+//	        the body ID (16 bytes), position, velocity (3 float64 each) and temperature (float64)
+const (
+	binaryMagic   = "GSETRAJ"
+	binaryVersion = 1
+	stateSize     = 16 + 7*8
+)
+
+// UnitSymbols are the symbols of the units of a recorded trajectory
+type UnitSymbols struct {
+	Time        string
+	Length      string
+	Velocity    string
+	Temperature string
+}
+
+// BinaryWriter writes the snapshots in a compact chunked binary format, read back by BinaryReader.
+// The snapshots are buffered and written a chunk at a time.
+type BinaryWriter struct {
+	w             io.Writer
+	units         Units
+	chunkSize     int // Snapshots per chunk
+	headerWritten bool
+	chunk         bytes.Buffer
+	count         int // Snapshots in the current chunk
+}
+
+// NewBinaryWriter creates a binary writer in SI units, grouping chunkSize snapshots per chunk
+func NewBinaryWriter(w io.Writer, chunkSize int) *BinaryWriter {
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	return &BinaryWriter{w: w, units: SIUnits(), chunkSize: chunkSize}
+}
+
+// SetUnits sets the units of the values, before the first snapshot is written
+func (bw *BinaryWriter) SetUnits(u Units) error {
+	if bw.headerWritten {
+		return fmt.Errorf("the units cannot change after the header is written")
+	}
+	if err := u.validate(); err != nil {
+		return err
+	}
+	bw.units = u
+	return nil
+}
+
+// GetUnits returns the units of the values
+func (bw *BinaryWriter) GetUnits() Units {
+	return bw.units
+}
+
+// WriteSnapshot adds a snapshot to the current chunk, writing the chunk when it is full
+func (bw *BinaryWriter) WriteSnapshot(s Snapshot) error {
+	if !bw.headerWritten {
+		if err := bw.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	lengthScale := convert(1, units.Meter, bw.units.Length)
+	velocityScale := convert(1, units.MeterPerSecond, bw.units.Velocity)
+	var scratch [8]byte
+	putFloat := func(value float64) {
+		binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(value))
+		bw.chunk.Write(scratch[:])
+	}
+
+	putFloat(convert(s.Time, units.Second, bw.units.Time))
+	binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s.States)))
+	bw.chunk.Write(scratch[:4])
+	for _, state := range s.States {
+		bw.chunk.Write(state.ID[:])
+		for _, value := range state.Position.Scale(lengthScale).ToArray() {
+			putFloat(value)
+		}
+		for _, value := range state.Velocity.Scale(velocityScale).ToArray() {
+			putFloat(value)
+		}
+		putFloat(convert(state.Temperature, units.Kelvin, bw.units.Temperature))
+	}
+
+	bw.count++
+	if bw.count >= bw.chunkSize {
+		return bw.Flush()
+	}
+	return nil
+}
+
+// writeHeader writes the magic number, the version and the unit symbols
+func (bw *BinaryWriter) writeHeader() error {
+	var header bytes.Buffer
+	header.WriteString(binaryMagic)
+	header.WriteByte(binaryVersion)
+	for _, unit := range []units.Unit{bw.units.Time, bw.units.Length, bw.units.Velocity, bw.units.Temperature} {
+		symbol := unit.Symbol()
+		binary.Write(&header, binary.LittleEndian, uint16(len(symbol)))
+		header.WriteString(symbol)
+	}
+	if _, err := bw.w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	bw.headerWritten = true
+	return nil
+}
+
+// Flush writes the current chunk, even if it is not full
+func (bw *BinaryWriter) Flush() error {
+	if bw.count == 0 {
+		return nil
+	}
+	var sizes [8]byte
+	binary.LittleEndian.PutUint32(sizes[:4], uint32(bw.count))
+	binary.LittleEndian.PutUint32(sizes[4:], uint32(bw.chunk.Len()))
+	if _, err := bw.w.Write(sizes[:]); err != nil {
+		return err
+	}
+	if _, err := bw.w.Write(bw.chunk.Bytes()); err != nil {
+		return err
+	}
+	bw.chunk.Reset()
+	bw.count = 0
+	return nil
+}
+
+// Close flushes the current chunk and closes the underlying writer if it is an io.Closer
+func (bw *BinaryWriter) Close() error {
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if closer, ok := bw.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// BinaryReader reads the snapshots written by a BinaryWriter.
+// The values are in the units of the file, given by Units.
+type BinaryReader struct {
+	r         *bufio.Reader
+	units     UnitSymbols
+	remaining int // Snapshots left in the current chunk
+}
+
+// NewBinaryReader creates a reader and reads the header of the file
+func NewBinaryReader(r io.Reader) (*BinaryReader, error) {
+	br := &BinaryReader{r: bufio.NewReader(r)}
+
+	magic := make([]byte, len(binaryMagic)+1)
+	if _, err := io.ReadFull(br.r, magic); err != nil {
+		return nil, fmt.Errorf("cannot read the trajectory header: %w", err)
+	}
+	if string(magic[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("not a trajectory file")
+	}
+	if magic[len(binaryMagic)] != binaryVersion {
+		return nil, fmt.Errorf("unsupported trajectory version %d", magic[len(binaryMagic)])
+	}
+
+	for _, symbol := range []*string{&br.units.Time, &br.units.Length, &br.units.Velocity, &br.units.Temperature} {
+		var length uint16
+		if err := binary.Read(br.r, binary.LittleEndian, &length); err != nil {
+			return nil, fmt.Errorf("cannot read the trajectory units: %w", err)
+		}
+		text := make([]byte, length)
+		if _, err := io.ReadFull(br.r, text); err != nil {
+			return nil, fmt.Errorf("cannot read the trajectory units: %w", err)
+		}
+		*symbol = string(text)
+	}
+	return br, nil
+}
+
+// Units returns the symbols of the units of the file
+func (br *BinaryReader) Units() UnitSymbols {
+	return br.units
+}
+
+// Next returns the next snapshot, or io.EOF at the end of the file
+func (br *BinaryReader) Next() (Snapshot, error) {
+	if br.remaining == 0 {
+		var sizes [8]byte
+		if _, err := io.ReadFull(br.r, sizes[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return Snapshot{}, fmt.Errorf("truncated trajectory chunk")
+			}
+			return Snapshot{}, err
+		}
+		br.remaining = int(binary.LittleEndian.Uint32(sizes[:4]))
+	}
+
+	var head [12]byte
+	if _, err := io.ReadFull(br.r, head[:]); err != nil {
+		return Snapshot{}, fmt.Errorf("truncated trajectory snapshot: %w", err)
+	}
+	snapshot := Snapshot{
+		Time:   math.Float64frombits(binary.LittleEndian.Uint64(head[:8])),
+		States: make([]State, binary.LittleEndian.Uint32(head[8:])),
+	}
+
+	var record [stateSize]byte
+	for i := range snapshot.States {
+		if _, err := io.ReadFull(br.r, record[:]); err != nil {
+			return Snapshot{}, fmt.Errorf("truncated trajectory state: %w", err)
+		}
+		values := make([]float64, 7)
+		for j := range values {
+			values[j] = math.Float64frombits(binary.LittleEndian.Uint64(record[16+8*j:]))
+		}
+		id, _ := uuid.FromBytes(record[:16])
+		snapshot.States[i] = State{
+			ID:          id,
+			Position:    vector.NewVector3(values[0], values[1], values[2]),
+			Velocity:    vector.NewVector3(values[3], values[4], values[5]),
+			Temperature: values[6],
+		}
+	}
+	br.remaining--
+	return snapshot, nil
+}
+
+// ReadAll returns all the remaining snapshots
+func (br *BinaryReader) ReadAll() ([]Snapshot, error) {
+	var snapshots []Snapshot
+	for {
+		snapshot, err := br.Next()
+		if err == io.EOF {
+			return snapshots, nil
+		}
+		if err != nil {
+			return snapshots, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+}
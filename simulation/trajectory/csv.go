@@ -0,0 +1,66 @@
+package trajectory
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+)
+
+// CSVWriter writes the snapshots as CSV, one row per body and time.
+// The header gives the unit of each column with its symbol, e.g. "x [m]".
+type CSVWriter struct {
+	w             io.Writer
+	units         Units
+	headerWritten bool
+}
+
+// NewCSVWriter creates a CSV writer in SI units
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: w, units: SIUnits()}
+}
+
+// SetUnits sets the units of the values, before the first snapshot is written
+func (cw *CSVWriter) SetUnits(u Units) error {
+	if cw.headerWritten {
+		return fmt.Errorf("the units cannot change after the header is written")
+	}
+	if err := u.validate(); err != nil {
+		return err
+	}
+	cw.units = u
+	return nil
+}
+
+// GetUnits returns the units of the values
+func (cw *CSVWriter) GetUnits() Units {
+	return cw.units
+}
+
+// WriteSnapshot writes the rows of a snapshot, preceded by the header for the first one
+func (cw *CSVWriter) WriteSnapshot(s Snapshot) error {
+	if !cw.headerWritten {
+		t, l, v, k := cw.units.Time.Symbol(), cw.units.Length.Symbol(), cw.units.Velocity.Symbol(), cw.units.Temperature.Symbol()
+		_, err := fmt.Fprintf(cw.w, "time [%s],body,x [%s],y [%s],z [%s],vx [%s],vy [%s],vz [%s],temperature [%s]\n",
+			t, l, l, l, v, v, v, k)
+		if err != nil {
+			return err
+		}
+		cw.headerWritten = true
+	}
+
+	time := convert(s.Time, units.Second, cw.units.Time)
+	for _, state := range s.States {
+		position := state.Position.Scale(convert(1, units.Meter, cw.units.Length))
+		velocity := state.Velocity.Scale(convert(1, units.MeterPerSecond, cw.units.Velocity))
+		_, err := fmt.Fprintf(cw.w, "%g,%s,%g,%g,%g,%g,%g,%g,%g\n",
+			time, state.ID,
+			position.X(), position.Y(), position.Z(),
+			velocity.X(), velocity.Y(), velocity.Z(),
+			convert(state.Temperature, units.Kelvin, cw.units.Temperature))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package trajectory records the trajectories of the bodies of a world for post-processing: as CSV, in a compact
+// chunked binary format or in an in-memory ring buffer queryable by time
+package trajectory
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+	"github.com/google/uuid"
+)
+
+// State is the state of a body in a snapshot, in SI units
+type State struct {
+	ID          uuid.UUID
+	Position    vector.Vector3 // m
+	Velocity    vector.Vector3 // m/s
+	Temperature float64        // K
+}
+
+// Snapshot contains the states of the recorded bodies at a time
+type Snapshot struct {
+	Time   float64 // s
+	States []State
+}
+
+// State returns the state of a body in the snapshot
+func (s Snapshot) State(id uuid.UUID) (State, bool) {
+	for _, state := range s.States {
+		if state.ID == id {
+			return state, true
+		}
+	}
+	return State{}, false
+}
+
+// Units are the units of the values written by the CSV and binary writers
+type Units struct {
+	Time        units.Unit
+	Length      units.Unit
+	Velocity    units.Unit
+	Temperature units.Unit
+}
+
+// SIUnits returns the SI units: seconds, meters, meters per second and kelvins
+func SIUnits() Units {
+	return Units{Time: units.Second, Length: units.Meter, Velocity: units.MeterPerSecond, Temperature: units.Kelvin}
+}
+
+// validate returns an error if a unit is missing or measures another quantity
+func (u Units) validate() error {
+	expected := []struct {
+		name     string
+		unit     units.Unit
+		unitType units.UnitType
+	}{
+		{"time", u.Time, units.Time},
+		{"length", u.Length, units.Length},
+		{"velocity", u.Velocity, units.Velocity},
+		{"temperature", u.Temperature, units.Temperature},
+	}
+	for _, e := range expected {
+		if e.unit == nil || e.unit.Type() != e.unitType {
+			return fmt.Errorf("invalid %s unit", e.name)
+		}
+	}
+	return nil
+}
+
+// convert converts a value from the SI unit of the same type to a unit
+func convert(value float64, standard, unit units.Unit) float64 {
+	if unit == standard {
+		return value
+	}
+	return units.NewQuantity(value, standard).ConvertTo(unit).Value()
+}
+
+// Sink receives the snapshots taken by a Recorder
+type Sink interface {
+	// WriteSnapshot stores or writes a snapshot
+	WriteSnapshot(s Snapshot) error
+}
+
+// Recorder samples the positions, velocities and temperatures of the bodies of a world every N steps and
+// passes the snapshots to its sinks. Added to a world as a step observer, it records all the bodies of the
+// world or only the selected ones.
+type Recorder struct {
+	interval int // Steps between two snapshots
+	steps    int
+	selected []uuid.UUID
+	sinks    []Sink
+	err      error
+}
+
+// NewRecorder creates a recorder taking a snapshot every interval steps
+func NewRecorder(interval int) *Recorder {
+	if interval < 1 {
+		interval = 1
+	}
+	return &Recorder{interval: interval}
+}
+
+// GetInterval returns the number of steps between two snapshots
+func (r *Recorder) GetInterval() int {
+	return r.interval
+}
+
+// Select restricts the recording to some bodies (all the bodies of the world if none is selected)
+func (r *Recorder) Select(bodies ...body.Body) {
+	for _, b := range bodies {
+		r.selected = append(r.selected, b.ID())
+	}
+}
+
+// GetSelected returns the IDs of the selected bodies (nil if all the bodies are recorded)
+func (r *Recorder) GetSelected() []uuid.UUID {
+	return r.selected
+}
+
+// AddSink adds a destination of the snapshots
+func (r *Recorder) AddSink(s Sink) {
+	r.sinks = append(r.sinks, s)
+}
+
+// GetSinks returns the destinations of the snapshots
+func (r *Recorder) GetSinks() []Sink {
+	return r.sinks
+}
+
+// ObserveStep takes a snapshot of a world every interval steps
+func (r *Recorder) ObserveStep(w world.World, dt float64) {
+	r.steps++
+	if r.steps%r.interval == 0 {
+		r.Record(w)
+	}
+}
+
+// Record takes a snapshot of a world and passes it to the sinks, e.g. to record the initial state before a run.
+// The first error of a sink is kept and returned by Err.
+func (r *Recorder) Record(w world.World) {
+	snapshot := r.Snapshot(w)
+	for _, s := range r.sinks {
+		if err := s.WriteSnapshot(snapshot); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+}
+
+// Snapshot returns the current states of the recorded bodies of a world
+func (r *Recorder) Snapshot(w world.World) Snapshot {
+	var bodies []body.Body
+	if r.selected == nil {
+		// In a stable order, the world stores its bodies in a map
+		bodies = w.GetBodies()
+		sort.Slice(bodies, func(i, j int) bool {
+			a, b := bodies[i].ID(), bodies[j].ID()
+			return bytes.Compare(a[:], b[:]) < 0
+		})
+	} else {
+		for _, id := range r.selected {
+			if b := w.GetBody(id); b != nil {
+				bodies = append(bodies, b)
+			}
+		}
+	}
+
+	snapshot := Snapshot{Time: w.GetTime(), States: make([]State, len(bodies))}
+	for i, b := range bodies {
+		snapshot.States[i] = State{
+			ID:          b.ID(),
+			Position:    b.Position(),
+			Velocity:    b.Velocity(),
+			Temperature: units.ConvertToStandardUnit(b.Temperature()),
+		}
+	}
+	return snapshot
+}
+
+// Err returns the first error of a sink, or nil
+func (r *Recorder) Err() error {
+	return r.err
+}
@@ -0,0 +1,128 @@
+package trajectory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// RingBuffer keeps the latest snapshots in memory, overwriting the oldest ones when it is full.
+// The snapshots are expected in chronological order and can be queried by time while the world runs.
+type RingBuffer struct {
+	snapshots []Snapshot
+	start     int // Index of the oldest snapshot
+	count     int
+	mutex     sync.RWMutex
+}
+
+// NewRingBuffer creates a buffer of the latest capacity snapshots
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer{snapshots: make([]Snapshot, capacity)}
+}
+
+// WriteSnapshot stores a snapshot, replacing the oldest one if the buffer is full
+func (rb *RingBuffer) WriteSnapshot(s Snapshot) error {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	if rb.count < len(rb.snapshots) {
+		rb.snapshots[(rb.start+rb.count)%len(rb.snapshots)] = s
+		rb.count++
+	} else {
+		rb.snapshots[rb.start] = s
+		rb.start = (rb.start + 1) % len(rb.snapshots)
+	}
+	return nil
+}
+
+// Capacity returns the largest number of snapshots kept
+func (rb *RingBuffer) Capacity() int {
+	return len(rb.snapshots)
+}
+
+// Len returns the number of snapshots kept
+func (rb *RingBuffer) Len() int {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	return rb.count
+}
+
+// get returns the i-th oldest snapshot
+func (rb *RingBuffer) get(i int) Snapshot {
+	return rb.snapshots[(rb.start+i)%len(rb.snapshots)]
+}
+
+// Snapshots returns the snapshots kept, in chronological order
+func (rb *RingBuffer) Snapshots() []Snapshot {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+
+	snapshots := make([]Snapshot, rb.count)
+	for i := range snapshots {
+		snapshots[i] = rb.get(i)
+	}
+	return snapshots
+}
+
+// Range returns the snapshots taken between two times (s), included
+func (rb *RingBuffer) Range(from, to float64) []Snapshot {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+
+	first := sort.Search(rb.count, func(i int) bool { return rb.get(i).Time >= from })
+	var snapshots []Snapshot
+	for i := first; i < rb.count && rb.get(i).Time <= to; i++ {
+		snapshots = append(snapshots, rb.get(i))
+	}
+	return snapshots
+}
+
+// At returns the states at a time (s), linearly interpolated between the snapshots that bracket it.
+// Only the bodies recorded in both snapshots are returned. It returns false outside the recorded interval.
+func (rb *RingBuffer) At(time float64) (Snapshot, bool) {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+
+	if rb.count == 0 || time < rb.get(0).Time || time > rb.get(rb.count-1).Time {
+		return Snapshot{}, false
+	}
+	next := sort.Search(rb.count, func(i int) bool { return rb.get(i).Time >= time })
+	after := rb.get(next)
+	if after.Time == time || next == 0 {
+		return after, true
+	}
+	before := rb.get(next - 1)
+
+	fraction := (time - before.Time) / (after.Time - before.Time)
+	previous := make(map[uuid.UUID]State, len(before.States))
+	for _, state := range before.States {
+		previous[state.ID] = state
+	}
+	snapshot := Snapshot{Time: time, States: make([]State, 0, len(after.States))}
+	for _, state := range after.States {
+		old, exists := previous[state.ID]
+		if !exists {
+			continue
+		}
+		snapshot.States = append(snapshot.States, State{
+			ID:          state.ID,
+			Position:    old.Position.Add(state.Position.Sub(old.Position).Scale(fraction)),
+			Velocity:    old.Velocity.Add(state.Velocity.Sub(old.Velocity).Scale(fraction)),
+			Temperature: old.Temperature + (state.Temperature-old.Temperature)*fraction,
+		})
+	}
+	return snapshot, true
+}
+
+// StateAt returns the state of a body at a time (s), linearly interpolated between the snapshots that bracket it
+func (rb *RingBuffer) StateAt(id uuid.UUID, time float64) (State, bool) {
+	snapshot, ok := rb.At(time)
+	if !ok {
+		return State{}, false
+	}
+	return snapshot.State(id)
+}
@@ -0,0 +1,90 @@
+package world
+
+import (
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+)
+
+// PotentialMethod selects how the gravitational potential energy of a world is computed
+type PotentialMethod int
+
+const (
+	// DirectPotential sums the potential energy of all pairs of bodies, O(n²)
+	DirectPotential PotentialMethod = iota
+	// TreePotential approximates distant groups of bodies with the octree of the world (Barnes-Hut), O(n log n).
+	// It falls back to the direct sum when the spatial structure is not an octree or gravity is attached to some
+	// bodies only.
+	TreePotential
+)
+
+// String returns the name of the method
+func (m PotentialMethod) String() string {
+	if m == TreePotential {
+		return "tree"
+	}
+	return "direct"
+}
+
+// Diagnostics are the quantities conserved by an isolated world, measured at a time
+type Diagnostics struct {
+	Time                 float64        // s
+	Mass                 float64        // Total mass (kg)
+	KineticEnergy        float64        // J
+	PotentialEnergy      float64        // Gravitational potential energy of the gravitational forces of the world (J)
+	Momentum             vector.Vector3 // Linear momentum (kg·m/s)
+	AngularMomentum      vector.Vector3 // Angular momentum about the origin (kg·m²/s)
+	CenterOfMass         vector.Vector3 // m
+	CenterOfMassVelocity vector.Vector3 // m/s
+}
+
+// TotalEnergy returns the sum of the kinetic and the potential energy (J)
+func (d Diagnostics) TotalEnergy() float64 {
+	return d.KineticEnergy + d.PotentialEnergy
+}
+
+// ComputeDiagnostics measures the energy, the momenta and the center of mass of the bodies of the world
+func (w *PhysicalWorld) ComputeDiagnostics(method PotentialMethod) Diagnostics {
+	bodies := w.GetBodies()
+	d := Diagnostics{
+		Time:                 w.time,
+		Momentum:             vector.Zero3(),
+		AngularMomentum:      vector.Zero3(),
+		CenterOfMass:         vector.Zero3(),
+		CenterOfMassVelocity: vector.Zero3(),
+	}
+
+	for _, b := range bodies {
+		mass := units.ConvertToStandardUnit(b.Mass())
+		momentum := b.Velocity().Scale(mass)
+		d.Mass += mass
+		d.KineticEnergy += 0.5 * mass * b.Velocity().LengthSquared()
+		d.Momentum = d.Momentum.Add(momentum)
+		d.AngularMomentum = d.AngularMomentum.Add(b.Position().Cross(momentum))
+		d.CenterOfMass = d.CenterOfMass.Add(b.Position().Scale(mass))
+	}
+	if d.Mass > 0 {
+		d.CenterOfMass = d.CenterOfMass.Scale(1 / d.Mass)
+		d.CenterOfMassVelocity = d.Momentum.Scale(1 / d.Mass)
+	}
+
+	for _, f := range w.forces {
+		gf, ok := f.(*force.GravitationalForce)
+		if !ok {
+			continue
+		}
+		targets := w.forceBodies(gf, bodies)
+		octree, isOctree := w.spatialStructure.(*space.Octree)
+		if method != TreePotential || !isOctree || len(targets) != len(w.bodies) {
+			d.PotentialEnergy += force.DirectPotentialEnergy(targets, gf.G, w.separation())
+			continue
+		}
+
+		// Each pair is counted from both bodies
+		for _, b := range targets {
+			d.PotentialEnergy += octree.CalculatePotential(b, gf.GetTheta(), gf.G) / 2
+		}
+	}
+	return d
+}
@@ -5,12 +5,16 @@ import (
 	"runtime"
 	"sync"
 
+	"github.com/alexanderi96/go-space-engine/core/units"
 	"github.com/alexanderi96/go-space-engine/core/vector"
 	"github.com/alexanderi96/go-space-engine/physics/body"
 	"github.com/alexanderi96/go-space-engine/physics/collision"
+	"github.com/alexanderi96/go-space-engine/physics/constraint"
 	"github.com/alexanderi96/go-space-engine/physics/force"
 	"github.com/alexanderi96/go-space-engine/physics/integrator"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
 	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/physics/thermal"
 	"github.com/google/uuid"
 )
 
@@ -26,6 +30,18 @@ type World interface {
 	GetBodies() []body.Body
 	// GetBodyCount returns the number of bodies in the world
 	GetBodyCount() int
+	// GetBodiesByTag returns all bodies in the world with the given tag
+	GetBodiesByTag(tag string) []body.Body
+	// PutOnRails makes a body follow its Keplerian orbit around a central body analytically
+	PutOnRails(id, central uuid.UUID)
+	// TakeOffRails makes a body on rails follow the integrated forces again
+	TakeOffRails(id uuid.UUID)
+	// IsOnRails returns true if a body follows an analytic orbit
+	IsOnRails(id uuid.UUID) bool
+	// DetachPart separates a part from a compound body and adds it to the world as an independent body
+	DetachPart(id uuid.UUID, name string) body.Body
+	// QuerySphere returns the bodies within a sphere that can collide with the given filter
+	QuerySphere(center vector.Vector3, radius float64, filter body.CollisionFilter) []body.Body
 
 	// AddForce adds a force to the world
 	AddForce(f force.Force)
@@ -33,6 +49,27 @@ type World interface {
 	RemoveForce(f force.Force)
 	// GetForces returns all forces in the world
 	GetForces() []force.Force
+	// AttachForce restricts a force to the given bodies, adding it to the world if needed
+	AttachForce(f force.Force, ids ...uuid.UUID)
+	// DetachForce removes bodies from the targets of an attached force
+	DetachForce(f force.Force, ids ...uuid.UUID)
+	// GetForceTargets returns the bodies a force is attached to (nil if it acts on all bodies)
+	GetForceTargets(f force.Force) []uuid.UUID
+	// AttachForceToTag restricts a force to the bodies with a tag, adding it to the world if needed
+	AttachForceToTag(f force.Force, tag string)
+	// DetachForceFromTag removes a tag from the targets of an attached force
+	DetachForceFromTag(f force.Force, tag string)
+
+	// AddConstraint adds a joint between two bodies
+	AddConstraint(c constraint.Constraint)
+	// RemoveConstraint removes a joint
+	RemoveConstraint(c constraint.Constraint)
+	// GetConstraints returns all joints in the world
+	GetConstraints() []constraint.Constraint
+	// SetConstraintSolver sets the solver that enforces the joints
+	SetConstraintSolver(s *constraint.Solver)
+	// GetConstraintSolver returns the solver that enforces the joints
+	GetConstraintSolver() *constraint.Solver
 
 	// SetIntegrator sets the numerical integrator
 	SetIntegrator(i integrator.Integrator)
@@ -59,13 +96,50 @@ type World interface {
 	// GetBounds returns the world boundaries
 	GetBounds() *space.AABB
 
+	// SetThermalModel sets the heat transfer model (nil disables heat transfer)
+	SetThermalModel(m *thermal.Model)
+	// GetThermalModel returns the heat transfer model
+	GetThermalModel() *thermal.Model
+	// GetPhaseChanges returns the phase changes that occurred during the last step
+	GetPhaseChanges() []thermal.PhaseChange
+
+	// SetSOITracker sets the sphere of influence tracker (nil disables it)
+	SetSOITracker(t *orbit.SOITracker)
+	// GetSOITracker returns the sphere of influence tracker
+	GetSOITracker() *orbit.SOITracker
+	// GetSOITransitions returns the transitions between spheres of influence that occurred during the last step
+	GetSOITransitions() []orbit.Transition
+
+	// SetPeriodicity sets which axes of the world boundaries wrap around
+	SetPeriodicity(periodic space.Periodicity)
+	// GetPeriodicity returns which axes of the world boundaries wrap around
+	GetPeriodicity() space.Periodicity
+
+	// ComputeDiagnostics measures the energy, the momenta and the center of mass of the bodies of the world
+	ComputeDiagnostics(method PotentialMethod) Diagnostics
+
+	// AddObserver adds an observer notified at the end of each step
+	AddObserver(o StepObserver)
+	// RemoveObserver removes an observer
+	RemoveObserver(o StepObserver)
+	// GetObservers returns all observers of the world
+	GetObservers() []StepObserver
+
 	// Step advances the simulation by one time step
 	Step(dt float64)
+	// GetTime returns the coordinate time elapsed in the world
+	GetTime() float64
 
-	// Clear removes all bodies and forces from the world
+	// Clear removes all bodies, forces and joints from the world
 	Clear()
 }
 
+// StepObserver is notified at the end of each step of a world, e.g. to record or analyze a run
+type StepObserver interface {
+	// ObserveStep is called with the world after a step of length dt (s)
+	ObserveStep(w World, dt float64)
+}
+
 // WorkerPool represents a worker pool for parallel computation
 type WorkerPool struct {
 	numWorkers int
@@ -114,12 +188,23 @@ func (wp *WorkerPool) Wait() {
 type PhysicalWorld struct {
 	bodies            map[uuid.UUID]body.Body
 	forces            []force.Force
+	forceTargets      map[force.Force]map[uuid.UUID]bool
+	forceTags         map[force.Force]map[string]bool
 	integrator        integrator.Integrator
 	collider          collision.Collider
 	collisionResolver collision.CollisionResolver
+	constraintSolver  *constraint.Solver
+	rails             map[uuid.UUID]uuid.UUID // Central body of each body on rails
 	spatialStructure  space.SpatialStructure
 	bounds            *space.AABB
+	periodic          space.Periodicity
+	thermalModel      *thermal.Model
+	phaseChanges      []thermal.PhaseChange
+	soiTracker        *orbit.SOITracker
+	soiTransitions    []orbit.Transition
+	observers         []StepObserver
 	workerPool        *WorkerPool
+	time              float64
 }
 
 // NewPhysicalWorld creates a new physical world
@@ -134,9 +219,13 @@ func NewPhysicalWorld(bounds *space.AABB) *PhysicalWorld {
 	return &PhysicalWorld{
 		bodies:            make(map[uuid.UUID]body.Body),
 		forces:            make([]force.Force, 0),
+		forceTargets:      make(map[force.Force]map[uuid.UUID]bool),
+		forceTags:         make(map[force.Force]map[string]bool),
 		integrator:        integrator.NewVerletIntegrator(),
 		collider:          collision.NewSphereCollider(),
 		collisionResolver: collision.NewImpulseResolver(0.5),
+		constraintSolver:  constraint.NewSolver(),
+		rails:             make(map[uuid.UUID]uuid.UUID),
 		spatialStructure:  spatialStructure,
 		bounds:            bounds,
 		workerPool:        workerPool,
@@ -154,6 +243,19 @@ func (w *PhysicalWorld) RemoveBody(id uuid.UUID) {
 	if b, exists := w.bodies[id]; exists {
 		w.spatialStructure.Remove(b)
 		delete(w.bodies, id)
+		for _, targets := range w.forceTargets {
+			delete(targets, id)
+		}
+		w.constraintSolver.RemoveBody(id)
+		delete(w.rails, id)
+		for railed, central := range w.rails {
+			if central == id {
+				delete(w.rails, railed)
+			}
+		}
+		if w.soiTracker != nil {
+			w.soiTracker.Remove(id)
+		}
 	}
 }
 
@@ -176,6 +278,141 @@ func (w *PhysicalWorld) GetBodyCount() int {
 	return len(w.bodies)
 }
 
+// GetBodiesByTag returns all bodies in the world with the given tag
+func (w *PhysicalWorld) GetBodiesByTag(tag string) []body.Body {
+	bodies := make([]body.Body, 0)
+	for _, b := range w.bodies {
+		if b.HasTag(tag) {
+			bodies = append(bodies, b)
+		}
+	}
+	return bodies
+}
+
+// PutOnRails makes a body follow its Keplerian orbit around a central body analytically, with the universal
+// variable propagator, instead of integrating the forces acting on it (e.g. for cheap far-field propagation).
+// The orbit is the one of the body relative to the central body at the start of each step; the body still
+// attracts and collides with the other bodies, but they do not change its motion.
+func (w *PhysicalWorld) PutOnRails(id, central uuid.UUID) {
+	if id == central {
+		return
+	}
+	w.rails[id] = central
+}
+
+// TakeOffRails makes a body on rails follow the integrated forces again
+func (w *PhysicalWorld) TakeOffRails(id uuid.UUID) {
+	delete(w.rails, id)
+}
+
+// IsOnRails returns true if a body follows an analytic orbit
+func (w *PhysicalWorld) IsOnRails(id uuid.UUID) bool {
+	_, exists := w.rails[id]
+	return exists
+}
+
+// railsOrbit is the orbit of a body on rails at the start of a step
+type railsOrbit struct {
+	central  uuid.UUID
+	position vector.Vector3 // Position relative to the central body
+	velocity vector.Vector3 // Velocity relative to the central body
+	mu       float64        // Gravitational parameter G (M + m)
+}
+
+// railsOrbits returns the orbits of the bodies on rails relative to their central bodies
+func (w *PhysicalWorld) railsOrbits() map[uuid.UUID]railsOrbit {
+	if len(w.rails) == 0 {
+		return nil
+	}
+	orbits := make(map[uuid.UUID]railsOrbit, len(w.rails))
+	for id, centralID := range w.rails {
+		b, central := w.bodies[id], w.bodies[centralID]
+		if b == nil || central == nil {
+			continue
+		}
+		orbits[id] = railsOrbit{
+			central:  centralID,
+			position: b.Position().Sub(central.Position()),
+			velocity: b.Velocity().Sub(central.Velocity()),
+			mu:       orbit.GravitationalParameter(central, b),
+		}
+	}
+	return orbits
+}
+
+// propagateRails moves the bodies on rails along their orbits, after their central bodies
+// (which can be on rails too, e.g. a moon around a planet around a star)
+func (w *PhysicalWorld) propagateRails(orbits map[uuid.UUID]railsOrbit, dt float64) {
+	placed := make(map[uuid.UUID]bool, len(orbits))
+	var place func(id uuid.UUID)
+	place = func(id uuid.UUID) {
+		if placed[id] {
+			return
+		}
+		placed[id] = true
+		state := orbits[id]
+		if _, railed := orbits[state.central]; railed {
+			place(state.central)
+		}
+
+		b, central := w.bodies[id], w.bodies[state.central]
+		if b == nil || central == nil {
+			return
+		}
+		position, velocity := orbit.Propagate(state.position, state.velocity, state.mu, dt)
+		b.SetPosition(central.Position().Add(position))
+		b.SetVelocity(central.Velocity().Add(velocity))
+		b.SetAcceleration(vector.Zero3())
+	}
+
+	for id := range orbits {
+		place(id)
+	}
+}
+
+// DetachPart separates a part from a compound body (e.g. a spent stage) and adds it to the world
+// as an independent body. It returns nil if the body is not compound or has no detachable part with that name.
+func (w *PhysicalWorld) DetachPart(id uuid.UUID, name string) body.Body {
+	compound, ok := w.bodies[id].(*body.CompoundBody)
+	if !ok {
+		return nil
+	}
+
+	// The position and the radius of the compound body change with its parts
+	w.spatialStructure.Remove(compound)
+	part := compound.Detach(name)
+	w.spatialStructure.Insert(compound)
+	if part == nil {
+		return nil
+	}
+
+	w.AddBody(part)
+	return part
+}
+
+// QuerySphere returns the bodies overlapping a sphere that can collide with the given filter.
+// A filter with every category and mask bit set returns all the bodies overlapping the sphere.
+func (w *PhysicalWorld) QuerySphere(center vector.Vector3, radius float64, filter body.CollisionFilter) []body.Body {
+	separation := w.separation()
+	nearbyBodies := w.spatialStructure.QuerySphere(center, radius)
+	bodies := make([]body.Body, 0, len(nearbyBodies))
+	for _, b := range nearbyBodies {
+		if !filter.CanCollide(b.CollisionFilter()) {
+			continue
+		}
+
+		// The spatial structure may return bodies outside the sphere
+		delta := b.Position().Sub(center)
+		if separation != nil {
+			delta = separation(center, b.Position())
+		}
+		if delta.Length() <= radius+units.ConvertToStandardUnit(b.Radius()) {
+			bodies = append(bodies, b)
+		}
+	}
+	return bodies
+}
+
 // AddForce adds a force to the world
 func (w *PhysicalWorld) AddForce(f force.Force) {
 	w.forces = append(w.forces, f)
@@ -192,6 +429,8 @@ func (w *PhysicalWorld) RemoveForce(f force.Force) {
 			break
 		}
 	}
+	delete(w.forceTargets, f)
+	delete(w.forceTags, f)
 }
 
 // GetForces returns all forces in the world
@@ -199,6 +438,137 @@ func (w *PhysicalWorld) GetForces() []force.Force {
 	return w.forces
 }
 
+// AttachForce restricts a force to the given bodies, adding it to the world if needed.
+// An attached global force is only applied to its targets, and an attached pair force only
+// between pairs of targets (e.g. a spring connecting exactly two bodies).
+func (w *PhysicalWorld) AttachForce(f force.Force, ids ...uuid.UUID) {
+	targets := w.attach(f)
+	for _, id := range ids {
+		targets[id] = true
+	}
+}
+
+// AttachForceToTag restricts a force to the bodies with a tag, adding it to the world if needed.
+// The tags are evaluated at every step, so bodies tagged later are targeted too.
+func (w *PhysicalWorld) AttachForceToTag(f force.Force, tag string) {
+	w.attach(f)
+	if w.forceTags[f] == nil {
+		w.forceTags[f] = make(map[string]bool)
+	}
+	w.forceTags[f][tag] = true
+}
+
+// DetachForceFromTag removes a tag from the targets of an attached force
+func (w *PhysicalWorld) DetachForceFromTag(f force.Force, tag string) {
+	delete(w.forceTags[f], tag)
+}
+
+// attach adds a force to the world if needed and returns its (possibly new) set of targets
+func (w *PhysicalWorld) attach(f force.Force) map[uuid.UUID]bool {
+	targets, attached := w.forceTargets[f]
+	if !attached {
+		if !w.hasForce(f) {
+			w.AddForce(f)
+		}
+		targets = make(map[uuid.UUID]bool)
+		w.forceTargets[f] = targets
+	}
+	return targets
+}
+
+// DetachForce removes bodies from the targets of an attached force.
+// The force stays restricted to its remaining targets, even if none is left:
+// use RemoveForce and AddForce to apply it to all bodies again.
+func (w *PhysicalWorld) DetachForce(f force.Force, ids ...uuid.UUID) {
+	if targets, attached := w.forceTargets[f]; attached {
+		for _, id := range ids {
+			delete(targets, id)
+		}
+	}
+}
+
+// GetForceTargets returns the bodies a force is attached to (nil if it acts on all bodies)
+func (w *PhysicalWorld) GetForceTargets(f force.Force) []uuid.UUID {
+	targets, attached := w.forceTargets[f]
+	if !attached {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(targets))
+	for id := range targets {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// hasAnyTag returns true if the body has at least one of the tags
+func hasAnyTag(b body.Body, tags map[string]bool) bool {
+	for tag := range tags {
+		if b.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasForce returns true if the force is in the world
+func (w *PhysicalWorld) hasForce(f force.Force) bool {
+	for _, existing := range w.forces {
+		if existing == f {
+			return true
+		}
+	}
+	return false
+}
+
+// forceBodies returns the bodies a force acts on: its targets (by ID or tag) if it is attached,
+// filtered by the force itself if it implements force.BodyFilter
+func (w *PhysicalWorld) forceBodies(f force.Force, bodies []body.Body) []body.Body {
+	targets, attached := w.forceTargets[f]
+	filter, filtered := f.(force.BodyFilter)
+	if !attached && !filtered {
+		return bodies
+	}
+
+	tags := w.forceTags[f]
+	selected := make([]body.Body, 0, len(bodies))
+	for _, b := range bodies {
+		if attached && !targets[b.ID()] && !hasAnyTag(b, tags) {
+			continue
+		}
+		if filtered && !filter.AppliesTo(b) {
+			continue
+		}
+		selected = append(selected, b)
+	}
+	return selected
+}
+
+// AddConstraint adds a joint between two bodies.
+// Bodies joined by a constraint do not collide with each other.
+func (w *PhysicalWorld) AddConstraint(c constraint.Constraint) {
+	w.constraintSolver.Add(c)
+}
+
+// RemoveConstraint removes a joint
+func (w *PhysicalWorld) RemoveConstraint(c constraint.Constraint) {
+	w.constraintSolver.Remove(c)
+}
+
+// GetConstraints returns all joints in the world
+func (w *PhysicalWorld) GetConstraints() []constraint.Constraint {
+	return w.constraintSolver.GetConstraints()
+}
+
+// SetConstraintSolver sets the solver that enforces the joints (e.g. with different iteration counts)
+func (w *PhysicalWorld) SetConstraintSolver(s *constraint.Solver) {
+	w.constraintSolver = s
+}
+
+// GetConstraintSolver returns the solver that enforces the joints
+func (w *PhysicalWorld) GetConstraintSolver() *constraint.Solver {
+	return w.constraintSolver
+}
+
 // SetIntegrator sets the numerical integrator
 func (w *PhysicalWorld) SetIntegrator(i integrator.Integrator) {
 	w.integrator = i
@@ -212,6 +582,7 @@ func (w *PhysicalWorld) GetIntegrator() integrator.Integrator {
 // SetCollider sets the collision detector
 func (w *PhysicalWorld) SetCollider(c collision.Collider) {
 	w.collider = c
+	w.configurePeriodicity()
 }
 
 // GetCollider returns the collision detector
@@ -234,6 +605,7 @@ func (w *PhysicalWorld) SetSpatialStructure(s space.SpatialStructure) {
 	// Transfer all bodies from the old structure to the new one
 	bodies := w.GetBodies()
 	w.spatialStructure = s
+	w.configurePeriodicity()
 	for _, b := range bodies {
 		s.Insert(b)
 	}
@@ -247,6 +619,7 @@ func (w *PhysicalWorld) GetSpatialStructure() space.SpatialStructure {
 // SetBounds sets the world boundaries
 func (w *PhysicalWorld) SetBounds(bounds *space.AABB) {
 	w.bounds = bounds
+	w.configurePeriodicity()
 }
 
 // GetBounds returns the world boundaries
@@ -254,27 +627,174 @@ func (w *PhysicalWorld) GetBounds() *space.AABB {
 	return w.bounds
 }
 
+// SetThermalModel sets the heat transfer model (nil disables heat transfer)
+func (w *PhysicalWorld) SetThermalModel(m *thermal.Model) {
+	w.thermalModel = m
+}
+
+// GetThermalModel returns the heat transfer model
+func (w *PhysicalWorld) GetThermalModel() *thermal.Model {
+	return w.thermalModel
+}
+
+// GetPhaseChanges returns the phase changes that occurred during the last step.
+// Bodies that fully vaporized have already been removed from the world.
+func (w *PhysicalWorld) GetPhaseChanges() []thermal.PhaseChange {
+	return w.phaseChanges
+}
+
+// SetSOITracker sets the sphere of influence tracker (nil disables it).
+// In patched-conic mode the tracked bodies only feel the gravity of their dominant body.
+func (w *PhysicalWorld) SetSOITracker(t *orbit.SOITracker) {
+	w.soiTracker = t
+	w.soiTransitions = nil
+}
+
+// GetSOITracker returns the sphere of influence tracker
+func (w *PhysicalWorld) GetSOITracker() *orbit.SOITracker {
+	return w.soiTracker
+}
+
+// GetSOITransitions returns the transitions between spheres of influence that occurred during the last step
+func (w *PhysicalWorld) GetSOITransitions() []orbit.Transition {
+	return w.soiTransitions
+}
+
+// SetPeriodicity sets which axes of the world boundaries wrap around.
+// Bodies leaving the world through a periodic axis re-enter from the opposite side,
+// and gravity, collisions and spatial queries use the minimum image convention on that axis.
+func (w *PhysicalWorld) SetPeriodicity(periodic space.Periodicity) {
+	w.periodic = periodic
+	w.configurePeriodicity()
+}
+
+// GetPeriodicity returns which axes of the world boundaries wrap around
+func (w *PhysicalWorld) GetPeriodicity() space.Periodicity {
+	return w.periodic
+}
+
+// configurePeriodicity propagates the periodic boundaries to the spatial structure and the collider
+func (w *PhysicalWorld) configurePeriodicity() {
+	if octree, ok := w.spatialStructure.(*space.Octree); ok {
+		octree.SetPeriodicity(w.periodic)
+	}
+	if collider, ok := w.collider.(collision.PeriodicCollider); ok {
+		collider.SetSeparation(w.separation())
+	}
+}
+
+// separation returns the displacement function for the periodic boundaries (nil if there are none)
+func (w *PhysicalWorld) separation() func(from, to vector.Vector3) vector.Vector3 {
+	if w.bounds == nil {
+		return nil
+	}
+	return w.bounds.MinimumImageFunc(w.periodic)
+}
+
+// AddObserver adds an observer notified at the end of each step
+func (w *PhysicalWorld) AddObserver(o StepObserver) {
+	w.observers = append(w.observers, o)
+}
+
+// RemoveObserver removes an observer
+func (w *PhysicalWorld) RemoveObserver(o StepObserver) {
+	for i, observer := range w.observers {
+		if observer == o {
+			w.observers = append(w.observers[:i], w.observers[i+1:]...)
+			break
+		}
+	}
+}
+
+// GetObservers returns all observers of the world
+func (w *PhysicalWorld) GetObservers() []StepObserver {
+	return w.observers
+}
+
 // Step advances the simulation by one time step
 func (w *PhysicalWorld) Step(dt float64) {
+	// Record the orbits of the bodies on rails before the forces and the collisions act on them
+	rails := w.railsOrbits()
+
+	// Find the sphere of influence of the tracked bodies before the forces depend on it
+	w.soiTransitions = nil
+	if w.soiTracker != nil {
+		w.soiTransitions = w.soiTracker.Update(w.time)
+	}
+
 	// Apply forces
 	w.applyForces()
 
 	// Detect and resolve collisions
 	w.handleCollisions()
 
-	// Integrate the equations of motion in parallel
+	// Transfer heat between bodies and remove the bodies that fully vaporized
 	bodies := w.GetBodies()
-	w.integrator.IntegrateAll(bodies, dt, w.workerPool)
+	w.phaseChanges = nil
+	if w.thermalModel != nil {
+		w.phaseChanges = w.thermalModel.Step(bodies, w.spatialStructure, w.separation(), dt, w.workerPool)
+		for _, change := range w.phaseChanges {
+			if change.RemainingMass <= 0 {
+				w.RemoveBody(change.Body.ID())
+			}
+		}
+		if len(w.bodies) != len(bodies) {
+			bodies = w.GetBodies()
+		}
+	}
+
+	// Integrate the equations of motion in parallel.
+	// The bodies joined by constraints are advanced by the constraint solver,
+	// the bodies on rails along their analytic orbits.
+	free := bodies
+	if len(w.constraintSolver.GetConstraints()) > 0 || len(rails) > 0 {
+		free = make([]body.Body, 0, len(bodies))
+		for _, b := range bodies {
+			if _, railed := rails[b.ID()]; !railed && !w.constraintSolver.IsJoined(b.ID()) {
+				free = append(free, b)
+			}
+		}
+	}
+	w.integrator.IntegrateAll(free, dt, w.workerPool)
+	w.integrateRotations(free, dt)
+	w.constraintSolver.Step(dt)
+	w.propagateRails(rails, dt)
+
+	// Bring the bodies that crossed a periodic boundary back into the world
+	w.wrapPeriodicBodies(bodies)
+
+	// Advance the coordinate time and the proper time of each body
+	w.time += dt
+	for _, b := range bodies {
+		b.AdvanceProperTime(dt)
+	}
 
 	// Update the spatial structure
 	w.updateSpatialStructure()
+
+	// Notify the observers of the new state
+	for _, o := range w.observers {
+		o.ObserveStep(w, dt)
+	}
 }
 
-// Clear removes all bodies and forces from the world
+// GetTime returns the coordinate time elapsed in the world
+func (w *PhysicalWorld) GetTime() float64 {
+	return w.time
+}
+
+// Clear removes all bodies, forces and joints from the world and resets the time
 func (w *PhysicalWorld) Clear() {
 	w.bodies = make(map[uuid.UUID]body.Body)
 	w.forces = make([]force.Force, 0)
+	w.forceTargets = make(map[force.Force]map[uuid.UUID]bool)
+	w.forceTags = make(map[force.Force]map[string]bool)
+	w.constraintSolver.Clear()
+	w.rails = make(map[uuid.UUID]uuid.UUID)
 	w.spatialStructure.Clear()
+	w.phaseChanges = nil
+	w.soiTransitions = nil
+	w.time = 0
 }
 
 // applyForces applies all forces to all bodies
@@ -290,29 +810,32 @@ func (w *PhysicalWorld) applyForces() {
 		}
 	}
 
-	// Apply global forces to all bodies in parallel
+	// Apply global forces to their bodies in parallel
+	var patched map[uuid.UUID]body.Body
 	for _, f := range w.forces {
 		if f.IsGlobal() {
-			// If it's a gravitational force and we have an octree, use the Barnes-Hut algorithm
+			targets := w.forceBodies(f, bodies)
+
+			// If it's a gravitational force, use the solver selected on the force.
+			// In patched-conic mode the tracked bodies are attracted by their dominant body only, once the
+			// other forces have accelerated it: the gravity of all the bodies is still evaluated at once,
+			// but not applied to them.
 			if gravityForce != nil && f == gravityForce {
-				octree, ok := w.spatialStructure.(*space.Octree)
-				if ok {
-					// Use the octree to calculate gravity in parallel
-					for _, b := range bodies {
-						b := b // Capture the variable for the goroutine
-						w.workerPool.Submit(func() {
-							// Calculate the gravitational force using the Barnes-Hut algorithm
-							force := octree.CalculateGravity(b, gravityForce.GetTheta())
-							b.ApplyForce(force)
-						})
-					}
-					w.workerPool.Wait()
-					continue
+				if w.soiTracker != nil && w.soiTracker.IsPatched() {
+					patched = w.patchedBodies(targets)
 				}
+				w.applyGravity(gravityForce, targets, patched)
+				continue
+			}
+
+			// The Coulomb force is evaluated on all charged bodies at once, like gravity
+			if cf, ok := f.(*force.CoulombForce); ok {
+				w.applyCoulomb(cf, targets)
+				continue
 			}
 
 			// For other global forces, apply normally in parallel
-			for _, b := range bodies {
+			for _, b := range targets {
 				b := b // Capture the variable for the goroutine
 				f := f // Capture the variable for the goroutine
 				w.workerPool.Submit(func() {
@@ -324,24 +847,144 @@ func (w *PhysicalWorld) applyForces() {
 		}
 	}
 
-	// Apply forces between pairs of bodies in parallel
-	for i := 0; i < len(bodies); i++ {
-		for j := i + 1; j < len(bodies); j++ {
-			i, j := i, j // Capture the variables for the goroutine
-			w.workerPool.Submit(func() {
-				for _, f := range w.forces {
-					if !f.IsGlobal() {
-						forceA, forceB := f.ApplyBetween(bodies[i], bodies[j])
-						bodies[i].ApplyForce(forceA)
-						bodies[j].ApplyForce(forceB)
-					}
-				}
-			})
+	// Apply forces between pairs of their bodies in parallel
+	for _, f := range w.forces {
+		if f.IsGlobal() {
+			continue
+		}
+
+		targets := w.forceBodies(f, bodies)
+		for i := 0; i < len(targets); i++ {
+			for j := i + 1; j < len(targets); j++ {
+				i, j := i, j // Capture the variables for the goroutine
+				f := f       // Capture the variable for the goroutine
+				w.workerPool.Submit(func() {
+					forceA, forceB := f.ApplyBetween(targets[i], targets[j])
+					targets[i].ApplyForce(forceA)
+					targets[j].ApplyForce(forceB)
+				})
+			}
+		}
+		w.workerPool.Wait()
+	}
+
+	for _, b := range patched {
+		b.ApplyForce(w.soiTracker.Gravity(b, gravityForce.G))
+	}
+}
+
+// patchedBodies returns the bodies tracked in patched-conic mode that are inside the sphere of influence of a
+// dominant body. A tracked body without a dominant body keeps the gravity of all the bodies.
+func (w *PhysicalWorld) patchedBodies(bodies []body.Body) map[uuid.UUID]body.Body {
+	patched := make(map[uuid.UUID]body.Body)
+	for _, b := range bodies {
+		if w.soiTracker.IsTracked(b.ID()) && w.soiTracker.Dominant(b.ID()) != nil {
+			patched[b.ID()] = b
+		}
+	}
+	return patched
+}
+
+// applyGravity applies the gravitational force to all bodies using the solver selected on the force.
+// The bodies in skip attract the others but are not accelerated (it can be nil).
+func (w *PhysicalWorld) applyGravity(gf *force.GravitationalForce, bodies []body.Body, skip map[uuid.UUID]body.Body) {
+	switch gf.GetSolver() {
+	case force.DirectSolver:
+		w.applyForceList(bodies, force.DirectGravity(bodies, gf.G, gf.IsCompensated(), w.separation()), skip)
+		return
+	case force.FMMSolver:
+		w.applyForceList(bodies, force.FMMGravity(bodies, gf.G, gf.GetTheta(), w.separation()), skip)
+		return
+	case force.PMSolver:
+		w.applyForceList(bodies, force.PMGravity(bodies, gf.G, w.bounds, gf.GetGridSize(), gf.GetMassAssignment(), 0), skip)
+		return
+	case force.TreePMSolver:
+		w.applyTreePMGravity(gf, bodies, skip)
+		return
+	}
+
+	// Barnes-Hut requires an octree, fall back to the exact solver otherwise.
+	// The octree contains all the bodies of the world: gravity attached to some of them uses the exact solver too.
+	octree, ok := w.spatialStructure.(*space.Octree)
+	if !ok || len(bodies) != len(w.bodies) {
+		w.applyForceList(bodies, force.DirectGravity(bodies, gf.G, gf.IsCompensated(), w.separation()), skip)
+		return
+	}
+
+	// Use the octree to calculate gravity in parallel
+	for _, b := range bodies {
+		if _, skipped := skip[b.ID()]; skipped {
+			continue
 		}
+		b := b // Capture the variable for the goroutine
+		w.workerPool.Submit(func() {
+			// Calculate the gravitational force using the Barnes-Hut algorithm
+			force := octree.CalculateGravity(b, gf.GetTheta())
+			b.ApplyForce(force)
+		})
 	}
 	w.workerPool.Wait()
 }
 
+// applyCoulomb applies the electrostatic force to the charged bodies using the Barnes-Hut algorithm
+func (w *PhysicalWorld) applyCoulomb(cf *force.CoulombForce, bodies []body.Body) {
+	// The octree contains the charges of all the bodies of the world (uncharged bodies do not contribute),
+	// a force attached to some of them uses the exact pair sum instead
+	octree, ok := w.spatialStructure.(*space.Octree)
+	if _, attached := w.forceTargets[cf]; !ok || attached {
+		w.applyForceList(bodies, force.DirectCoulomb(bodies, cf.K, w.separation()), nil)
+		return
+	}
+
+	for _, b := range bodies {
+		b := b // Capture the variable for the goroutine
+		w.workerPool.Submit(func() {
+			b.ApplyForce(octree.CalculateCoulomb(b, cf.GetTheta(), cf.K))
+		})
+	}
+	w.workerPool.Wait()
+}
+
+// applyTreePMGravity applies the gravitational force using the PM solver for the long-range part
+// and the octree for the short-range part. The bodies in skip are not accelerated.
+func (w *PhysicalWorld) applyTreePMGravity(gf *force.GravitationalForce, bodies []body.Body, skip map[uuid.UUID]body.Body) {
+	// The short-range part requires an octree with exactly these bodies, fall back to the plain PM solver otherwise
+	octree, ok := w.spatialStructure.(*space.Octree)
+	if !ok || len(bodies) != len(w.bodies) {
+		w.applyForceList(bodies, force.PMGravity(bodies, gf.G, w.bounds, gf.GetGridSize(), gf.GetMassAssignment(), 0), skip)
+		return
+	}
+
+	splitRadius := force.PMSplitRadius(w.bounds, gf.GetGridSize(), gf.GetSplitScale())
+	w.applyForceList(bodies, force.PMGravity(bodies, gf.G, w.bounds, gf.GetGridSize(), gf.GetMassAssignment(), splitRadius), skip)
+
+	// Add the short-range correction in parallel
+	cutoff := force.ShortRangeCutoff(splitRadius)
+	kernel := func(distance float64) float64 {
+		return force.ShortRangeFactor(distance, splitRadius)
+	}
+	for _, b := range bodies {
+		if _, skipped := skip[b.ID()]; skipped {
+			continue
+		}
+		b := b // Capture the variable for the goroutine
+		w.workerPool.Submit(func() {
+			b.ApplyForce(octree.CalculateShortRangeGravity(b, gf.GetTheta(), gf.G, cutoff, kernel))
+		})
+	}
+	w.workerPool.Wait()
+}
+
+// applyForceList applies precomputed forces to the bodies (forces[i] acts on bodies[i]), except those in skip
+func (w *PhysicalWorld) applyForceList(bodies []body.Body, forces []vector.Vector3, skip map[uuid.UUID]body.Body) {
+	for i, b := range bodies {
+		if _, skipped := skip[b.ID()]; skipped {
+			continue
+		}
+		b.ApplyForce(forces[i])
+	}
+}
+
 // handleCollisions detects and resolves collisions
 func (w *PhysicalWorld) handleCollisions() {
 	bodies := w.GetBodies()
@@ -355,8 +998,10 @@ func (w *PhysicalWorld) handleCollisions() {
 			nearbyBodies := w.spatialStructure.QuerySphere(bodies[i].Position(), radius*2)
 
 			for _, b := range nearbyBodies {
-				// Avoid checking collision with itself
-				if b.ID() == bodies[i].ID() {
+				// Avoid checking collision with itself, with bodies filtered out by the collision masks
+				// and with bodies joined to it
+				if b.ID() == bodies[i].ID() || !bodies[i].CollisionFilter().CanCollide(b.CollisionFilter()) ||
+					w.constraintSolver.Connected(bodies[i].ID(), b.ID()) {
 					continue
 				}
 
@@ -376,7 +1021,42 @@ func (w *PhysicalWorld) handleCollisions() {
 	w.workerPool.Wait()
 }
 
-// handleBoundaryCollisions handles collisions with world boundaries
+// integrateRotations advances the angular velocity of the bodies with the angular acceleration caused by the torques
+// of the step (I⁻¹ τ for the bodies with an inertia tensor), then their orientation with their angular velocity
+func (w *PhysicalWorld) integrateRotations(bodies []body.Body, dt float64) {
+	for _, b := range bodies {
+		if b.IsStatic() {
+			continue
+		}
+		if angularAcceleration := b.AngularAcceleration(); angularAcceleration.LengthSquared() > 0 {
+			b.SetAngularVelocity(b.AngularVelocity().Add(angularAcceleration.Scale(dt)))
+			b.SetAngularAcceleration(vector.Zero3())
+		}
+		if angularVelocity := b.AngularVelocity(); angularVelocity.LengthSquared() > 0 {
+			b.SetRotation(body.ComposeRotations(b.Rotation(), angularVelocity.Scale(dt)))
+		}
+	}
+}
+
+// wrapPeriodicBodies maps the bodies back into the world along the periodic axes
+func (w *PhysicalWorld) wrapPeriodicBodies(bodies []body.Body) {
+	if !w.periodic.Any() {
+		return
+	}
+
+	for _, b := range bodies {
+		if b.IsStatic() {
+			continue
+		}
+		position := b.Position()
+		wrapped := w.bounds.Wrap(position, w.periodic)
+		if wrapped.ToArray() != position.ToArray() {
+			b.SetPosition(wrapped)
+		}
+	}
+}
+
+// handleBoundaryCollisions handles collisions with world boundaries (periodic axes are skipped)
 func (w *PhysicalWorld) handleBoundaryCollisions(b body.Body) {
 	// If the body is static, do nothing
 	if b.IsStatic() {
@@ -397,7 +1077,7 @@ func (w *PhysicalWorld) handleBoundaryCollisions(b body.Body) {
 	newVelocity := velocity
 
 	// Collision with the lower X boundary
-	if position.X()-radius < bounds.Min.X() {
+	if !w.periodic[0] && position.X()-radius < bounds.Min.X() {
 		// Correct the position
 		newPosition = vector.NewVector3(bounds.Min.X()+radius, position.Y(), position.Z())
 		positionChanged = true
@@ -408,7 +1088,7 @@ func (w *PhysicalWorld) handleBoundaryCollisions(b body.Body) {
 	}
 
 	// Collision with the upper X boundary
-	if position.X()+radius > bounds.Max.X() {
+	if !w.periodic[0] && position.X()+radius > bounds.Max.X() {
 		// Correct the position
 		newPosition = vector.NewVector3(bounds.Max.X()-radius, position.Y(), position.Z())
 		positionChanged = true
@@ -419,7 +1099,7 @@ func (w *PhysicalWorld) handleBoundaryCollisions(b body.Body) {
 	}
 
 	// Collision with the lower Y boundary
-	if position.Y()-radius < bounds.Min.Y() {
+	if !w.periodic[1] && position.Y()-radius < bounds.Min.Y() {
 		// Correct the position
 		newPosition = vector.NewVector3(newPosition.X(), bounds.Min.Y()+radius, position.Z())
 		positionChanged = true
@@ -430,7 +1110,7 @@ func (w *PhysicalWorld) handleBoundaryCollisions(b body.Body) {
 	}
 
 	// Collision with the upper Y boundary
-	if position.Y()+radius > bounds.Max.Y() {
+	if !w.periodic[1] && position.Y()+radius > bounds.Max.Y() {
 		// Correct the position
 		newPosition = vector.NewVector3(newPosition.X(), bounds.Max.Y()-radius, position.Z())
 		positionChanged = true
@@ -441,7 +1121,7 @@ func (w *PhysicalWorld) handleBoundaryCollisions(b body.Body) {
 	}
 
 	// Collision with the lower Z boundary
-	if position.Z()-radius < bounds.Min.Z() {
+	if !w.periodic[2] && position.Z()-radius < bounds.Min.Z() {
 		// Correct the position
 		newPosition = vector.NewVector3(newPosition.X(), newPosition.Y(), bounds.Min.Z()+radius)
 		positionChanged = true
@@ -452,7 +1132,7 @@ func (w *PhysicalWorld) handleBoundaryCollisions(b body.Body) {
 	}
 
 	// Collision with the upper Z boundary
-	if position.Z()+radius > bounds.Max.Z() {
+	if !w.periodic[2] && position.Z()+radius > bounds.Max.Z() {
 		// Correct the position
 		newPosition = vector.NewVector3(newPosition.X(), newPosition.Y(), bounds.Max.Z()-radius)
 		positionChanged = true
@@ -0,0 +1,232 @@
+package tests
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/analysis"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// TestOrbitRecorder verifies the apsis passages, the period and the elements recorded for an eccentric orbit
+func TestOrbitRecorder(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e8, -1e8, -1e8), vector.NewVector3(1e8, 1e8, 1e8)))
+	w.AddForce(force.NewGravitationalForce())
+	earth := createEarth()
+	w.AddBody(earth)
+	elements := orbit.Elements{SemiMajorAxis: 1.2e7, Eccentricity: 0.3, Inclination: 0.5, LongitudeOfAscendingNode: 1, ArgumentOfPeriapsis: 2}
+	satellite := orbit.NewBodyOnOrbit(earth, elements, units.NewQuantity(1000, units.Kilogram), units.NewQuantity(1, units.Meter), material.Iron)
+	w.AddBody(satellite)
+
+	recorder := analysis.NewOrbitRecorder(earth)
+	recorder.Track(satellite)
+	recorder.SetSampleInterval(100)
+	w.AddObserver(recorder)
+
+	// Three orbits and three quarters, starting at periapsis
+	mu := orbit.GravitationalParameter(earth, satellite)
+	period := elements.Period(mu)
+	const dt = 5.0
+	steps := int(3.75 * period / dt)
+	for i := 0; i < steps; i++ {
+		w.Step(dt)
+	}
+
+	passages := recorder.GetPassages(satellite.ID())
+	if len(passages) != 7 {
+		t.Fatalf("Expected 7 apsis passages, got %d", len(passages))
+	}
+	for i, p := range passages {
+		expected, distance := analysis.Apoapsis, elements.Apoapsis()
+		if i%2 == 1 {
+			expected, distance = analysis.Periapsis, elements.Periapsis()
+		}
+		if p.Apsis != expected || math.Abs(p.Distance()-distance) > 1e-4*distance {
+			t.Errorf("Passage %d: %v at %v m, expected %v at %v m", i, p.Apsis, p.Distance(), expected, distance)
+		}
+		if math.Abs(p.Time-(float64(i)+1)*period/2) > 2*dt {
+			t.Errorf("Passage %d at %v s, expected at %v s", i, p.Time, (float64(i)+1)*period/2)
+		}
+	}
+
+	if periods := recorder.Periods(satellite.ID()); len(periods) != 2 {
+		t.Errorf("Expected 2 complete orbits, got %v", periods)
+	}
+	if measured := recorder.Period(satellite.ID()); math.Abs(measured-period) > dt {
+		t.Errorf("Period %v s, expected %v s", measured, period)
+	}
+	if rate := recorder.PrecessionRate(satellite.ID()); math.Abs(rate*period) > 1e-3 {
+		t.Errorf("Precession of %v rad per orbit of a Keplerian orbit", rate*period)
+	}
+
+	// Elements sampled every 100 steps, exported as CSV
+	// (the osculating elements oscillate with the velocity of the Verlet integrator, which lags one step behind)
+	samples := recorder.GetElements(satellite.ID())
+	if len(samples) != (steps+99)/100 {
+		t.Errorf("Expected %d samples of the elements, got %d", (steps+99)/100, len(samples))
+	}
+	for _, sample := range samples {
+		if math.Abs(sample.Elements.SemiMajorAxis-elements.SemiMajorAxis) > 1e-2*elements.SemiMajorAxis ||
+			math.Abs(sample.Elements.Eccentricity-elements.Eccentricity) > 1e-2 ||
+			math.Abs(sample.Elements.Inclination-elements.Inclination) > 1e-6 {
+			t.Errorf("Elements at %v s: %+v", sample.Time, sample.Elements)
+		}
+	}
+	var csv bytes.Buffer
+	if err := recorder.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != len(samples)+1 || !strings.HasPrefix(lines[0], "body,time_s,semi_major_axis_m,eccentricity") {
+		t.Errorf("Unexpected CSV with %d lines, header %q", len(lines), lines[0])
+	}
+	if fields := strings.Split(lines[1], ","); len(fields) != 8 || fields[0] != satellite.ID().String() {
+		t.Errorf("Unexpected CSV row %q", lines[1])
+	}
+
+	// Removing the observer stops the recording
+	w.RemoveObserver(recorder)
+	w.Step(dt)
+	if len(recorder.GetElements(satellite.ID())) != len(samples) || len(w.GetObservers()) != 0 {
+		t.Error("The recorder kept observing the world after its removal")
+	}
+}
+
+// TestConservationMonitor verifies the diagnostics of a cluster and the monitor of their drift
+func TestConservationMonitor(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e4, -1e4, -1e4), vector.NewVector3(1e4, 1e4, 1e4)))
+	gravity := force.NewGravitationalForce()
+	gravity.SetSolver(force.DirectSolver)
+	w.AddForce(gravity)
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 30; i++ {
+		position := vector.NewVector3(random.Float64()*200-100, random.Float64()*200-100, random.Float64()*200-100)
+		velocity := vector.NewVector3(random.Float64()-0.5, random.Float64()-0.5, random.Float64()-0.5).Scale(0.1)
+		w.AddBody(body.NewRigidBody(units.NewQuantity(1e9, units.Kilogram), units.NewQuantity(0.1, units.Meter), position, velocity, material.Rock))
+	}
+
+	// The tree approximates the exact potential energy
+	direct := w.ComputeDiagnostics(world.DirectPotential)
+	tree := w.ComputeDiagnostics(world.TreePotential)
+	if math.Abs(direct.Mass-30e9) > 1 || math.Abs(direct.KineticEnergy-tree.KineticEnergy) > 1e-9*direct.KineticEnergy || direct.PotentialEnergy >= 0 {
+		t.Errorf("Unexpected diagnostics %+v", direct)
+	}
+	if math.Abs(tree.PotentialEnergy-direct.PotentialEnergy) > 0.05*math.Abs(direct.PotentialEnergy) {
+		t.Errorf("Tree potential energy %v J, direct %v J", tree.PotentialEnergy, direct.PotentialEnergy)
+	}
+
+	// The exact gravity conserves all the quantities of the isolated cluster
+	// (the angular momentum up to the velocity of the Verlet integrator, which lags one step behind the position)
+	monitor := analysis.NewConservationMonitor(w, world.DirectPotential, 10)
+	monitor.SetLimits(analysis.DriftLimits{Energy: 1e-3, Momentum: 1e-9, AngularMomentum: 1e-5, CenterOfMass: 1e-9})
+	w.AddObserver(monitor)
+	for i := 0; i < 200; i++ {
+		w.Step(1)
+	}
+	if err := monitor.Err(); err != nil {
+		t.Errorf("Unexpected violation: %v (largest drift %+v)", err, monitor.MaxDrift())
+	}
+	if records := monitor.GetRecords(); len(records) != 20 || records[19].Time != 200 {
+		t.Errorf("Expected 20 records up to 200 s, got %d", len(records))
+	}
+
+	// An external force breaks the conservation of the momentum
+	push := force.NewConstantForce(vector.NewVector3(1e3, 0, 0))
+	w.AddForce(push)
+	for i := 0; i < 10; i++ {
+		w.Step(1)
+	}
+	if err := monitor.Err(); err == nil || !strings.Contains(err.Error(), "momentum") {
+		t.Errorf("Expected a momentum violation, got %v", err)
+	}
+
+	// A monitor can panic on the first violation instead
+	strict := analysis.NewConservationMonitor(w, world.TreePotential, 1)
+	strict.SetLimits(analysis.DriftLimits{CenterOfMass: 1e-9})
+	strict.SetPanicOnViolation(true)
+	w.AddObserver(strict)
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic of the monitor")
+		}
+	}()
+	w.Step(1)
+}
+
+// TestOrbitRecorderPrecession verifies the apsidal precession measured for an orbit with the 1PN correction,
+// with a reduced speed of light to make it large: 6πGM / (c² a (1 - e²)) per orbit
+func TestOrbitRecorderPrecession(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e8, -1e8, -1e8), vector.NewVector3(1e8, 1e8, 1e8)))
+	gravity := force.NewGravitationalForce()
+	gravity.SetSolver(force.DirectSolver)
+	w.AddForce(gravity)
+	postNewtonian := force.NewPostNewtonianForce()
+	postNewtonian.C = 1e5
+	w.AddForce(postNewtonian)
+	earth := createEarth()
+	w.AddBody(earth)
+	elements := orbit.Elements{SemiMajorAxis: 1.2e7, Eccentricity: 0.3}
+	satellite := orbit.NewBodyOnOrbit(earth, elements, units.NewQuantity(1000, units.Kilogram), units.NewQuantity(1, units.Meter), material.Iron)
+	w.AddBody(satellite)
+
+	recorder := analysis.NewOrbitRecorder(earth)
+	recorder.Track(satellite)
+	w.AddObserver(recorder)
+
+	mu := orbit.GravitationalParameter(earth, satellite)
+	period := elements.Period(mu)
+	const dt = 5.0
+	for i := 0; i < int(10*period/dt) && len(recorder.GetPassages(satellite.ID())) < 12; i++ {
+		w.Step(dt)
+	}
+
+	// Six orbits of alternating apsides, from the first apoapsis
+	passages := recorder.GetPassages(satellite.ID())
+	if len(passages) != 12 {
+		t.Fatalf("Expected 12 apsis passages, got %d", len(passages))
+	}
+	for i, p := range passages {
+		if (i%2 == 0) != (p.Apsis == analysis.Apoapsis) {
+			t.Errorf("Passage %d: unexpected %v", i, p.Apsis)
+		}
+	}
+
+	expected := 6 * math.Pi * mu / (postNewtonian.C * postNewtonian.C * elements.SemiMajorAxis * (1 - elements.Eccentricity*elements.Eccentricity))
+	measured := recorder.PrecessionRate(satellite.ID()) * recorder.Period(satellite.ID())
+	if math.Abs(measured-expected) > 0.05*expected {
+		t.Errorf("Precession of %v rad per orbit, expected %v rad", measured, expected)
+	}
+}
+
+// TestTreePotentialAtCenterOfMass verifies the tree potential energy of a star at the center of mass of a
+// symmetric system, which must be computed from the nodes around it rather than skipped
+func TestTreePotentialAtCenterOfMass(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e4, -1e4, -1e4), vector.NewVector3(1e4, 1e4, 1e4)))
+	w.AddForce(force.NewGravitationalForce())
+	center := vector.NewVector3(3000, 3000, 3000)
+	w.AddBody(body.NewRigidBody(units.NewQuantity(1e15, units.Kilogram), units.NewQuantity(1, units.Meter), center, vector.Zero3(), material.Rock))
+	for _, distance := range []float64{100, 200} {
+		for _, direction := range []vector.Vector3{vector.NewVector3(1, 0, 0), vector.NewVector3(0, 1, 0), vector.NewVector3(0, 0, 1)} {
+			for _, sign := range []float64{-1, 1} {
+				position := center.Add(direction.Scale(sign * distance))
+				w.AddBody(body.NewRigidBody(units.NewQuantity(1e9, units.Kilogram), units.NewQuantity(1, units.Meter), position, vector.Zero3(), material.Rock))
+			}
+		}
+	}
+
+	direct := w.ComputeDiagnostics(world.DirectPotential).PotentialEnergy
+	tree := w.ComputeDiagnostics(world.TreePotential).PotentialEnergy
+	if math.Abs(tree-direct) > 0.01*math.Abs(direct) {
+		t.Errorf("Tree potential energy %v J, direct %v J", tree, direct)
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// createEarth creates a static Earth at the origin
+func createEarth() body.Body {
+	earth := body.NewRigidBody(
+		units.NewQuantity(constants.EarthMass, units.Kilogram),
+		units.NewQuantity(constants.EarthRadius, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Rock,
+	)
+	earth.SetStatic(true)
+	return earth
+}
+
+// TestAtmosphereDensityProfiles verifies the exponential and tabulated density profiles
+func TestAtmosphereDensityProfiles(t *testing.T) {
+	exponential := force.NewEarthExponentialProfile()
+	if math.Abs(exponential.Density(0)-1.225) > 1e-3 {
+		t.Errorf("Sea level density %v kg/m³, expected 1.225 kg/m³", exponential.Density(0))
+	}
+	if math.Abs(exponential.ScaleHeight-8434) > 10 {
+		t.Errorf("Scale height %v m, expected about 8434 m", exponential.ScaleHeight)
+	}
+	if ratio := exponential.Density(exponential.ScaleHeight) / exponential.Density(0); math.Abs(ratio-1/math.E) > 1e-12 {
+		t.Errorf("Density ratio over one scale height %v, expected 1/e", ratio)
+	}
+
+	// The tabulated profile is exact at the table altitudes and exponential in between
+	tabulated := force.NewTabulatedProfile([]float64{0, 1000, 2000}, []float64{1.0, 0.25, 0.01})
+	if tabulated.Density(1000) != 0.25 {
+		t.Errorf("Density at a table altitude %v, expected 0.25", tabulated.Density(1000))
+	}
+	if math.Abs(tabulated.Density(500)-0.5) > 1e-12 {
+		t.Errorf("Density between 1 and 0.25 %v, expected their geometric mean 0.5", tabulated.Density(500))
+	}
+	if tabulated.Density(-10) != 1.0 || tabulated.Density(2001) != 0 {
+		t.Errorf("Unexpected density outside the table: %v, %v", tabulated.Density(-10), tabulated.Density(2001))
+	}
+
+	earth := force.NewEarthTabulatedProfile()
+	if math.Abs(earth.Density(400e3)-3.725e-12) > 1e-15 {
+		t.Errorf("Density at 400 km %v kg/m³, expected 3.725e-12 kg/m³", earth.Density(400e3))
+	}
+}
+
+// TestCoRotatingAtmosphere verifies that the drag depends on the velocity relative to the rotating atmosphere
+func TestCoRotatingAtmosphere(t *testing.T) {
+	earth := createEarth()
+	drag := force.NewAtmosphericDrag(earth, force.NewEarthTabulatedProfile())
+	drag.SetRotation(vector.NewVector3(0, 0, constants.EarthAngularVelocity))
+
+	// A balloon at 30 km moving with the atmosphere feels no drag
+	distance := constants.EarthRadius + 30e3
+	balloon := body.NewRigidBody(
+		units.NewQuantity(10.0, units.Kilogram),
+		units.NewQuantity(2.0, units.Meter),
+		vector.NewVector3(distance, 0, 0),
+		vector.NewVector3(0, constants.EarthAngularVelocity*distance, 0),
+		material.Rock,
+	)
+	if f := drag.Apply(balloon); f.Length() > 1e-12 {
+		t.Errorf("A body co-rotating with the atmosphere should feel no drag: %v", f)
+	}
+
+	// At rest in the inertial frame the wind pushes it along the rotation
+	balloon.SetVelocity(vector.Zero3())
+	wind := constants.EarthAngularVelocity * distance
+	expected := 0.5 * drag.GetProfile().Density(30e3) * 2.2 * math.Pi * 4 * wind * wind
+	f := drag.Apply(balloon)
+	if math.Abs(f.Y()-expected) > 1e-9*expected || math.Abs(f.X()) > 1e-9*expected {
+		t.Errorf("Drag %v, expected %v N along +Y", f, expected)
+	}
+
+	// The planet does not drag itself
+	if f := drag.Apply(earth); f.Length() != 0 {
+		t.Errorf("The planet should not be dragged by its atmosphere: %v", f)
+	}
+}
+
+// TestOrbitDecay verifies that the energy lost by a satellite in a low circular orbit matches
+// the drag power -1/2 ρ Cd A v³ integrated over one orbit
+func TestOrbitDecay(t *testing.T) {
+	const altitude = 300e3
+	const satelliteMass = 1000.0
+	radius := constants.EarthRadius + altitude
+	speed := math.Sqrt(constants.G * constants.EarthMass / radius)
+	period := 2 * math.Pi * radius / speed
+	dt := 1.0
+	steps := int(period / dt)
+	profile := force.NewEarthTabulatedProfile()
+
+	// Returns the specific orbital energy lost over one orbit
+	energyLoss := func(withDrag bool) float64 {
+		bound := 2 * radius
+		w := world.NewPhysicalWorld(space.NewAABB(
+			vector.NewVector3(-bound, -bound, -bound),
+			vector.NewVector3(bound, bound, bound),
+		))
+		gravityForce := force.NewGravitationalForce()
+		gravityForce.SetSolver(force.DirectSolver)
+		w.AddForce(gravityForce)
+
+		earth := createEarth()
+		satellite := body.NewRigidBody(
+			units.NewQuantity(satelliteMass, units.Kilogram),
+			units.NewQuantity(1.0, units.Meter),
+			vector.NewVector3(radius, 0, 0),
+			vector.NewVector3(0, speed, 0),
+			material.Iron,
+		)
+		w.AddBody(earth)
+		w.AddBody(satellite)
+		if withDrag {
+			w.AddForce(force.NewAtmosphericDrag(earth, profile))
+		}
+
+		energy := func() float64 {
+			return 0.5*satellite.Velocity().LengthSquared() -
+				constants.G*constants.EarthMass/satellite.Position().Length()
+		}
+		// Skip the first step, whose velocity is not yet synchronized with the position
+		w.Step(dt)
+		initial := energy()
+		for i := 0; i < steps; i++ {
+			w.Step(dt)
+		}
+		return initial - energy()
+	}
+
+	// Remove the numerical energy error of the integrator by comparing with a run without drag
+	loss := energyLoss(true) - energyLoss(false)
+	expected := 0.5 * profile.Density(altitude) * 2.2 * math.Pi / satelliteMass * speed * speed * speed * float64(steps) * dt
+
+	t.Logf("Specific energy lost in one orbit: %.1f J/kg (expected %.1f)", loss, expected)
+	if math.Abs(loss-expected) > 0.05*expected {
+		t.Errorf("Energy lost %v J/kg differs from the expected %v J/kg", loss, expected)
+	}
+}
@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/collision"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+)
+
+// createRocket creates a compound body with a 3 kg booster and a 1 kg capsule, 2 m apart along X
+// around the given position
+func createRocket(position, velocity vector.Vector3) *body.CompoundBody {
+	return body.NewCompoundBody(position, velocity,
+		body.Part{
+			Name:     "booster",
+			Mass:     units.NewQuantity(3, units.Kilogram),
+			Radius:   units.NewQuantity(0.5, units.Meter),
+			Offset:   vector.NewVector3(-1, 0, 0),
+			Material: material.Iron,
+		},
+		body.Part{
+			Name:     "capsule",
+			Mass:     units.NewQuantity(1, units.Kilogram),
+			Radius:   units.NewQuantity(0.5, units.Meter),
+			Offset:   vector.NewVector3(1, 0, 0),
+			Material: material.Copper,
+		},
+	)
+}
+
+// TestCompoundMassProperties verifies the mass, center of mass, bounding radius and inertia tensor of a compound body
+func TestCompoundMassProperties(t *testing.T) {
+	rocket := createRocket(vector.Zero3(), vector.Zero3())
+
+	if mass := rocket.Mass().Value(); mass != 4 {
+		t.Errorf("Mass %v kg, expected 4 kg", mass)
+	}
+	if !vectorsAlmostEqual(rocket.Position(), vector.NewVector3(-0.5, 0, 0), 1e-9) {
+		t.Errorf("Center of mass %v, expected (-0.5, 0, 0)", rocket.Position())
+	}
+	if radius := rocket.Radius().Value(); math.Abs(radius-2) > 1e-12 {
+		t.Errorf("Bounding radius %v m, expected 2 m", radius)
+	}
+	if rocket.Material() != material.Iron {
+		t.Errorf("The material should be the one of the heaviest part, got %v", rocket.Material().Name())
+	}
+
+	// Ixx = Σ 2/5 m r², Iyy = Izz = Σ (2/5 m r² + m d²)
+	inertia := rocket.InertiaTensor()
+	expected := [3][3]float64{{0.4, 0, 0}, {0, 3.4, 0}, {0, 0, 3.4}}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if math.Abs(inertia[i][j]-expected[i][j]) > 1e-12 {
+				t.Fatalf("Inertia tensor %v, expected %v", inertia, expected)
+			}
+		}
+	}
+}
+
+// TestCompoundCollidesPerPart verifies that compound bodies collide with their parts, not with their bounding sphere
+func TestCompoundCollidesPerPart(t *testing.T) {
+	rocket := createRocket(vector.Zero3(), vector.Zero3())
+	collider := collision.NewSphereCollider()
+	probe := func(position vector.Vector3) collision.CollisionInfo {
+		grain := body.NewRigidBody(units.NewQuantity(1, units.Kilogram), units.NewQuantity(0.5, units.Meter), position, vector.Zero3(), material.Rock)
+		return collider.CheckCollision(rocket, grain)
+	}
+
+	// Between the parts, inside the bounding sphere
+	if info := probe(vector.NewVector3(0, 0.9, 0)); info.HasCollided {
+		t.Errorf("The gap between the parts collided")
+	}
+
+	// Next to the capsule
+	info := probe(vector.NewVector3(1, 0.9, 0))
+	if !info.HasCollided || math.Abs(info.Depth-0.1) > 1e-9 || !vectorsAlmostEqual(info.Normal, vector.NewVector3(0, 1, 0), 1e-9) {
+		t.Errorf("Unexpected contact with the capsule: %+v", info)
+	}
+
+	// Rotated by 90° around Z the capsule moves away
+	rocket.SetRotation(vector.NewVector3(0, 0, math.Pi/2))
+	if info := probe(vector.NewVector3(1, 0.9, 0)); info.HasCollided {
+		t.Errorf("The rotated rocket still collided at the old capsule position")
+	}
+}
+
+// TestStageSeparation verifies that a detached part becomes an independent body and that the momentum is conserved
+func TestStageSeparation(t *testing.T) {
+	w, _ := createTestWorld()
+	rocket := createRocket(vector.Zero3(), vector.NewVector3(0, 10, 0))
+	rocket.SetAngularVelocity(vector.NewVector3(0, 0, 1))
+	w.AddBody(rocket)
+
+	booster, _ := rocket.Part("booster")
+	capsule, _ := rocket.Part("capsule")
+	boosterPosition := rocket.PartPosition(booster)
+	capsulePosition := rocket.PartPosition(capsule)
+
+	stage := w.DetachPart(rocket.ID(), "booster")
+	if stage == nil || w.GetBodyCount() != 2 {
+		t.Fatalf("The booster was not added to the world")
+	}
+	if !vectorsAlmostEqual(stage.Position(), boosterPosition, 1e-9) || stage.Mass().Value() != 3 {
+		t.Errorf("Detached booster at %v with %v kg", stage.Position(), stage.Mass().Value())
+	}
+	if !vectorsAlmostEqual(rocket.Position(), capsulePosition, 1e-9) || rocket.Mass().Value() != 1 || len(rocket.Parts()) != 1 {
+		t.Errorf("Remaining rocket at %v with %v kg", rocket.Position(), rocket.Mass().Value())
+	}
+
+	// Each part keeps the velocity it had on the spinning rocket: v + ω × r
+	if !vectorsAlmostEqual(stage.Velocity(), vector.NewVector3(0, 9.5, 0), 1e-9) || !vectorsAlmostEqual(rocket.Velocity(), vector.NewVector3(0, 11.5, 0), 1e-9) {
+		t.Errorf("Velocities after separation: %v, %v", stage.Velocity(), rocket.Velocity())
+	}
+	momentum := stage.Velocity().Scale(3).Add(rocket.Velocity())
+	if math.Abs(momentum.Y()-40) > 1e-9 {
+		t.Errorf("Momentum %v, expected 40 kg⋅m/s along Y", momentum)
+	}
+
+	// The last part cannot be detached
+	if w.DetachPart(rocket.ID(), "capsule") != nil {
+		t.Errorf("The last part was detached")
+	}
+}
+
+// TestCompoundTorque verifies that a torque spins a compound body according to its inertia tensor
+func TestCompoundTorque(t *testing.T) {
+	w, _ := createTestWorld()
+	rocket := createRocket(vector.Zero3(), vector.Zero3())
+	spinner := createRocket(vector.NewVector3(0, 10, 0), vector.Zero3())
+	w.AddBody(rocket)
+	w.AddBody(spinner)
+
+	// The same torque for 1 s around the long axis (Ixx = 0.4 kg⋅m²) and across it (Izz = 3.4 kg⋅m²)
+	for i := 0; i < 10; i++ {
+		spinner.ApplyTorque(vector.NewVector3(0.4, 0, 0))
+		rocket.ApplyTorque(vector.NewVector3(0, 0, 0.4))
+		w.Step(0.1)
+	}
+	if !vectorsAlmostEqual(spinner.AngularVelocity(), vector.NewVector3(1, 0, 0), 1e-9) {
+		t.Errorf("Angular velocity %v around the long axis, expected 1 rad/s", spinner.AngularVelocity())
+	}
+	if !vectorsAlmostEqual(rocket.AngularVelocity(), vector.NewVector3(0, 0, 0.4/3.4), 1e-9) {
+		t.Errorf("Angular velocity %v across the long axis, expected %v rad/s", rocket.AngularVelocity(), 0.4/3.4)
+	}
+	if rocket.Rotation().Z() <= 0 || rocket.AngularAcceleration().Length() != 0 {
+		t.Errorf("The rocket did not turn (%v) or kept its torque (%v)", rocket.Rotation(), rocket.AngularAcceleration())
+	}
+
+	// Without torque the angular velocity stays constant
+	w.Step(0.1)
+	if !vectorsAlmostEqual(rocket.AngularVelocity(), vector.NewVector3(0, 0, 0.4/3.4), 1e-9) {
+		t.Errorf("Angular velocity %v after the torque stopped", rocket.AngularVelocity())
+	}
+}
+
+// TestCompoundCollisionSpin verifies that an off-center impact spins a compound body and conserves the momentum
+// and the angular momentum
+func TestCompoundCollisionSpin(t *testing.T) {
+	rocket := createRocket(vector.Zero3(), vector.Zero3())
+	grain := body.NewRigidBody(units.NewQuantity(1, units.Kilogram), units.NewQuantity(0.5, units.Meter), vector.NewVector3(1, 0.9, 0), vector.NewVector3(0, -10, 0), material.Rock)
+
+	// Angular momentum about the origin: Σ r × m v, plus I ω for the rocket around its center of mass
+	angularMomentum := func() vector.Vector3 {
+		spin := rocket.AngularVelocity().Scale(3.4) // Izz, the rocket only spins around Z
+		return rocket.Position().Cross(rocket.Velocity().Scale(4)).Add(grain.Position().Cross(grain.Velocity())).Add(spin)
+	}
+	momentum := func() vector.Vector3 {
+		return rocket.Velocity().Scale(4).Add(grain.Velocity())
+	}
+	initialMomentum, initialAngularMomentum := momentum(), angularMomentum()
+
+	info := collision.NewSphereCollider().CheckCollision(rocket, grain)
+	if !info.HasCollided {
+		t.Fatal("The grain should hit the capsule")
+	}
+	collision.NewImpulseResolver(0.5).ResolveCollision(info)
+
+	// The capsule is 1.5 m from the center of mass: the rocket turns clockwise around Z
+	if rocket.AngularVelocity().Z() >= 0 {
+		t.Errorf("Unexpected angular velocity %v", rocket.AngularVelocity())
+	}
+	if !vectorsAlmostEqual(momentum(), initialMomentum, 1e-9) || !vectorsAlmostEqual(angularMomentum(), initialAngularMomentum, 1e-9) {
+		t.Errorf("Momentum %v -> %v, angular momentum %v -> %v", initialMomentum, momentum(), initialAngularMomentum, angularMomentum())
+	}
+}
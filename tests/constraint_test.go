@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/constraint"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+)
+
+// TestPendulum verifies that a distance joint keeps a bob at a fixed distance from its pivot
+// and that it swings with the period of a simple pendulum
+func TestPendulum(t *testing.T) {
+	const length = 10.0
+	const g = 9.81
+	w, bodies := createTestWorld(vector.Zero3(), vector.NewVector3(length*math.Sin(0.1), -length*math.Cos(0.1), 0))
+	pivot, bob := bodies[0], bodies[1]
+	pivot.SetStatic(true)
+
+	rod := constraint.NewDistanceJoint(pivot, bob, pivot.Position(), bob.Position())
+	w.AddConstraint(rod)
+	w.AttachForce(force.NewConstantForce(vector.NewVector3(0, -2*g, 0)), bob.ID())
+
+	// Measure the time between two crossings of the vertical in the same direction
+	dt := 0.001
+	var crossings []float64
+	previous := bob.Position().X()
+	for i := 0; i < 10000 && len(crossings) < 2; i++ {
+		w.Step(dt)
+		if x := bob.Position().X(); previous > 0 && x <= 0 {
+			crossings = append(crossings, w.GetTime())
+		}
+		previous = bob.Position().X()
+
+		if math.Abs(rod.Distance()-length) > 1e-3 {
+			t.Fatalf("The rod length drifted to %v m", rod.Distance())
+		}
+	}
+	if len(crossings) < 2 {
+		t.Fatalf("The pendulum did not swing")
+	}
+
+	// T = 2π √(L / g) for small amplitudes (≈ 0.06% longer at 0.1 rad)
+	period := crossings[1] - crossings[0]
+	expected := 2 * math.Pi * math.Sqrt(length/g)
+	if math.Abs(period-expected) > 0.01*expected {
+		t.Errorf("Period %v s, expected %v s", period, expected)
+	}
+}
+
+// TestRopeOnlyPullsWhenTaut verifies that a rope does not push the bodies and stops them at its maximum length
+func TestRopeOnlyPullsWhenTaut(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(-2, 0, 0), vector.NewVector3(2, 0, 0))
+	a, b := bodies[0], bodies[1]
+	rope := constraint.NewRopeJoint(a, b, a.Position(), b.Position(), 10)
+	w.AddConstraint(rope)
+
+	// The bodies approaching each other are not affected by the slack rope
+	a.SetVelocity(vector.NewVector3(0.5, 0, 0))
+	b.SetVelocity(vector.NewVector3(-0.5, 0, 0))
+	w.Step(0.1)
+	if math.Abs(a.Velocity().X()-0.5) > 1e-9 || math.Abs(b.Velocity().X()+0.5) > 1e-9 || rope.IsTaut() {
+		t.Errorf("The slack rope acted on the bodies: %v, %v", a.Velocity(), b.Velocity())
+	}
+
+	// Moving apart they stop when the rope becomes taut
+	a.SetVelocity(vector.NewVector3(-1, 0, 0))
+	b.SetVelocity(vector.NewVector3(1, 0, 0))
+	for i := 0; i < 100; i++ {
+		w.Step(0.1)
+	}
+	if math.Abs(rope.Distance()-10) > 1e-6 || !rope.IsTaut() {
+		t.Errorf("Rope length %v m, expected 10 m", rope.Distance())
+	}
+	if math.Abs(a.Velocity().X()) > 1e-6 || math.Abs(b.Velocity().X()) > 1e-6 {
+		t.Errorf("The taut rope did not stop the bodies: %v, %v", a.Velocity(), b.Velocity())
+	}
+}
+
+// TestFixedJointMovesTogether verifies that welded bodies move as one rigid body and do not collide with each other
+func TestFixedJointMovesTogether(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(0, 0, 0), vector.NewVector3(0.9, 0, 0))
+	a, b := bodies[0], bodies[1]
+	w.AddConstraint(constraint.NewFixedJoint(a, b))
+
+	// Pushed sideways at one end, the pair accelerates and spins as a whole
+	w.AttachForce(force.NewThrusterForce(vector.NewVector3(0, 4, 0)), a.ID())
+	for i := 0; i < 20; i++ {
+		w.Step(0.01)
+	}
+
+	// The overlapping bodies are not pushed apart
+	if separation := b.Position().Sub(a.Position()).Length(); math.Abs(separation-0.9) > 1e-3 {
+		t.Errorf("The welded bodies moved relative to each other: %v m apart", separation)
+	}
+	if a.AngularVelocity().Sub(b.AngularVelocity()).Length() > 1e-6 || a.AngularVelocity().Z() >= 0 {
+		t.Errorf("The welded bodies should spin together: %v, %v", a.AngularVelocity(), b.AngularVelocity())
+	}
+
+	// The center of mass follows the thrust: a = 4 N / 4 kg
+	centerVelocity := a.Velocity().Add(b.Velocity()).Scale(0.5)
+	if math.Abs(centerVelocity.Y()-0.2) > 1e-6 || math.Abs(centerVelocity.X()) > 1e-6 {
+		t.Errorf("Center of mass velocity %v, expected 0.2 m/s along Y", centerVelocity)
+	}
+}
+
+// TestFixedJointComposesRotations verifies that a fixed joint keeps the relative orientation of bodies rotated
+// around different axes, which rotation vectors do not compose by addition
+func TestFixedJointComposesRotations(t *testing.T) {
+	_, bodies := createTestWorld(vector.Zero3(), vector.NewVector3(2, 0, 0))
+	a, b := bodies[0], bodies[1]
+	a.SetRotation(vector.NewVector3(0, 0, math.Pi/2))
+	b.SetRotation(vector.NewVector3(math.Pi/2, 0, 0))
+	joint := constraint.NewFixedJoint(a, b)
+
+	// Composing rotations applies them in order
+	turn := vector.NewVector3(0, 0.7, 0.3)
+	v := vector.NewVector3(1, 2, 3)
+	if !vectorsAlmostEqual(body.Rotate(v, body.ComposeRotations(a.Rotation(), turn)), body.Rotate(body.Rotate(v, a.Rotation()), turn), 1e-12) {
+		t.Fatalf("The composed rotation does not apply the rotations in order")
+	}
+
+	// Turned as a whole around the pivot, the pair still satisfies the joint and is left unchanged
+	pivot := vector.NewVector3(1, 0, 0)
+	for _, current := range []body.Body{a, b} {
+		current.SetPosition(pivot.Add(body.Rotate(current.Position().Sub(pivot), turn)))
+		current.SetRotation(body.ComposeRotations(current.Rotation(), turn))
+	}
+	positionA, rotationA, rotationB := a.Position(), a.Rotation(), b.Rotation()
+	joint.Project()
+	if !vectorsAlmostEqual(a.Position(), positionA, 1e-9) || !vectorsAlmostEqual(a.Rotation(), rotationA, 1e-9) || !vectorsAlmostEqual(b.Rotation(), rotationB, 1e-9) {
+		t.Errorf("The joint moved the turned pair: rotations %v -> %v and %v -> %v", rotationA, a.Rotation(), rotationB, b.Rotation())
+	}
+
+	// A twist of b is undone, split between the bodies of equal inertia
+	b.SetRotation(body.ComposeRotations(rotationB, vector.NewVector3(0.1, 0, 0)))
+	for i := 0; i < 20; i++ {
+		joint.Project()
+	}
+	relative := body.ComposeRotations(b.Rotation(), a.Rotation().Scale(-1))
+	expected := body.ComposeRotations(rotationB, rotationA.Scale(-1))
+	if !vectorsAlmostEqual(relative, expected, 1e-6) || joint.Separation().Length() > 1e-6 {
+		t.Errorf("Relative orientation %v, expected %v", relative, expected)
+	}
+}
+
+// TestHingeRotatesAroundItsAxis verifies that a hinge only lets a body rotate around its axis
+func TestHingeRotatesAroundItsAxis(t *testing.T) {
+	w, bodies := createTestWorld(vector.Zero3(), vector.NewVector3(1, 0, 0))
+	base, panel := bodies[0], bodies[1]
+	base.SetStatic(true)
+
+	hinge := constraint.NewHingeJoint(base, panel, vector.NewVector3(0.5, 0, 0), vector.NewVector3(0, 0, 1))
+	w.AddConstraint(hinge)
+	w.GetConstraintSolver().SetIterations(20)
+
+	panel.SetAngularVelocity(vector.NewVector3(1, 1, 1))
+	for i := 0; i < 50; i++ {
+		w.Step(0.01)
+	}
+
+	// The rotation around X and Y is removed, the one around the hinge axis is kept
+	omega := panel.AngularVelocity()
+	if math.Abs(omega.X()) > 1e-6 || math.Abs(omega.Y()) > 1e-6 || omega.Z() <= 0 {
+		t.Errorf("Unexpected angular velocity %v", omega)
+	}
+	if hinge.Separation().Length() > 1e-3 {
+		t.Errorf("The pivot drifted by %v m", hinge.Separation().Length())
+	}
+
+	// Removing the bodies removes the joint
+	w.RemoveBody(panel.ID())
+	if len(w.GetConstraints()) != 0 {
+		t.Errorf("The joint of a removed body is still in the world")
+	}
+}
+
+// TestJointsUseTheInertiaTensor verifies that the joints turn a compound body with its torques and according to
+// its inertia tensor
+func TestJointsUseTheInertiaTensor(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(0, -3, 0))
+	base := bodies[0]
+	base.SetStatic(true)
+	rocket := createRocket(vector.Zero3(), vector.Zero3())
+	w.AddBody(rocket)
+	w.AddConstraint(constraint.NewHingeJoint(base, rocket, rocket.Position(), vector.NewVector3(0, 0, 1)))
+
+	// The torque across the long axis (Izz = 3.4 kg⋅m²) turns the rocket hinged at its center of mass
+	for i := 0; i < 10; i++ {
+		rocket.ApplyTorque(vector.NewVector3(0, 0, 0.4))
+		w.Step(0.1)
+	}
+	if !vectorsAlmostEqual(rocket.AngularVelocity(), vector.NewVector3(0, 0, 0.4/3.4), 1e-6) || rocket.AngularAcceleration().Length() != 0 {
+		t.Errorf("Angular velocity %v of the hinged rocket, expected %v rad/s", rocket.AngularVelocity(), 0.4/3.4)
+	}
+
+	// A twist around the long axis (Ixx = 0.4 kg⋅m²) of a body welded to the rocket (I = 0.2 kg⋅m²)
+	// is undone by the rocket for a third
+	_, bodies = createTestWorld(vector.NewVector3(3, 0, 0))
+	welded := bodies[0]
+	rocket = createRocket(vector.Zero3(), vector.Zero3())
+	joint := constraint.NewFixedJoint(rocket, welded)
+	welded.SetRotation(vector.NewVector3(0.1, 0, 0))
+	joint.Project()
+	if !vectorsAlmostEqual(rocket.Rotation(), vector.NewVector3(0.1/3, 0, 0), 1e-9) || !vectorsAlmostEqual(welded.Rotation(), vector.NewVector3(0.1/3, 0, 0), 1e-9) {
+		t.Errorf("Rotations %v and %v, expected both %v", rocket.Rotation(), welded.Rotation(), 0.1/3)
+	}
+}
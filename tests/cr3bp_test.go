@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/cr3bp"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/frame"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// earthMoonMassRatio is the mass ratio of the Earth-Moon system
+const earthMoonMassRatio = 0.012150585
+
+// TestLagrangePoints verifies the Lagrange points and the periodic orbits of the Earth-Moon system
+func TestLagrangePoints(t *testing.T) {
+	mu := earthMoonMassRatio
+	points := cr3bp.LagrangePoints(mu)
+	for i, expected := range []float64{0.836915, 1.155682, -1.005063} {
+		if math.Abs(points[i].X()-expected) > 1e-5 {
+			t.Errorf("L%d at %v, expected at x = %v", i+1, points[i], expected)
+		}
+	}
+
+	// The Lagrange points are equilibria of the rotating frame
+	for i, point := range points {
+		position, velocity := cr3bp.Propagate(mu, point, vector.Zero3(), 1)
+		if !vectorsAlmostEqual(position, point, 1e-9) || velocity.Length() > 1e-9 {
+			t.Errorf("L%d moved from %v to %v", i+1, point, position)
+		}
+	}
+
+	// Periodic orbits return to their initial state after a period, with the same Jacobi constant
+	orbits := map[string]func() (cr3bp.PeriodicOrbit, error){
+		"L1 Lyapunov":      func() (cr3bp.PeriodicOrbit, error) { return cr3bp.Lyapunov(mu, cr3bp.L1, 0.01) },
+		"L2 Lyapunov":      func() (cr3bp.PeriodicOrbit, error) { return cr3bp.Lyapunov(mu, cr3bp.L2, 0.01) },
+		"L1 northern halo": func() (cr3bp.PeriodicOrbit, error) { return cr3bp.Halo(mu, cr3bp.L1, 0.02, true) },
+		"L2 southern halo": func() (cr3bp.PeriodicOrbit, error) { return cr3bp.Halo(mu, cr3bp.L2, 0.02, false) },
+	}
+	for name, compute := range orbits {
+		o, err := compute()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		position, velocity := cr3bp.Propagate(mu, o.Position, o.Velocity, o.Period)
+		if !vectorsAlmostEqual(position, o.Position, 1e-6) || !vectorsAlmostEqual(velocity, o.Velocity, 1e-6) {
+			t.Errorf("%s: started at %v, %v and ended at %v, %v after a period of %v", name, o.Position, o.Velocity, position, velocity, o.Period)
+		}
+		if c := cr3bp.Jacobi(mu, position, velocity); math.Abs(c-o.Jacobi(mu)) > 1e-10 {
+			t.Errorf("%s: Jacobi constant changed from %v to %v", name, o.Jacobi(mu), c)
+		}
+	}
+
+	if _, err := cr3bp.Halo(mu, cr3bp.L4, 0.02, true); err == nil {
+		t.Error("Expected an error for a halo orbit around L4")
+	}
+}
+
+// TestLagrangeStability verifies that a particle stays at the L4 and L5 points of the Sun-Jupiter system
+// while it drifts away from L1
+func TestLagrangeStability(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e13, -1e13, -1e13), vector.NewVector3(1e13, 1e13, 1e13)))
+	w.AddForce(force.NewGravitationalForce())
+	sun := body.NewRigidBody(units.NewQuantity(constants.SolarMass, units.Kilogram), units.NewQuantity(6.9634e8, units.Meter), vector.Zero3(), vector.Zero3(), material.Rock)
+	w.AddBody(sun)
+	jupiter := orbit.NewBodyOnOrbit(sun, orbit.Circular(5.2*constants.AstronomicalUnit, 0, 0, 0), units.NewQuantity(1.898e27, units.Kilogram), units.NewQuantity(6.9911e7, units.Meter), material.Rock)
+	w.AddBody(jupiter)
+	frame.ShiftToBarycenter(w.GetBodies())
+
+	system := cr3bp.NewSystem(sun, jupiter)
+	mu := system.MassRatio()
+	points := cr3bp.LagrangePoints(mu)
+	particles := make(map[int]body.Body)
+	displacement := vector.NewVector3(1e-3, 1e-3, 0)
+	for _, point := range []int{cr3bp.L1, cr3bp.L4, cr3bp.L5} {
+		// Slightly off the point, at rest in the rotating frame
+		particle := body.NewRigidBody(units.NewQuantity(1, units.Kilogram), units.NewQuantity(1, units.Meter), vector.Zero3(), vector.Zero3(), material.Rock)
+		position, velocity := system.ToWorld(points[point-1].Add(displacement), vector.Zero3())
+		particle.SetPosition(position)
+		particle.SetVelocity(velocity)
+		w.AddBody(particle)
+		particles[point] = particle
+	}
+	jacobi := system.JacobiConstant(particles[cr3bp.L4])
+
+	// Twenty orbits of Jupiter, longer than a libration period around L4 and L5
+	period := 2 * math.Pi / system.MeanMotion()
+	drift := make(map[int]float64)
+	for i := 0; i < 20*500; i++ {
+		w.Step(period / 500)
+		for point, particle := range particles {
+			position, _ := system.FromWorld(particle.Position(), particle.Velocity())
+			drift[point] = math.Max(drift[point], position.Sub(points[point-1]).Length())
+		}
+	}
+
+	for _, point := range []int{cr3bp.L4, cr3bp.L5} {
+		if drift[point] > 0.3 {
+			t.Errorf("The particle at L%d drifted by %v times the Sun-Jupiter distance", point, drift[point])
+		}
+	}
+	if c := system.JacobiConstant(particles[cr3bp.L4]); math.Abs(c-jacobi) > 1e-3*math.Abs(jacobi) {
+		t.Errorf("Jacobi constant of the particle at L4 changed from %v to %v", jacobi, c)
+	}
+	if drift[cr3bp.L1] < 0.5 {
+		t.Errorf("The particle at L1 stayed within %v times the Sun-Jupiter distance of the point", drift[cr3bp.L1])
+	}
+}
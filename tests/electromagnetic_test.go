@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+)
+
+// TestCoulombPairForce verifies Coulomb's law between two charged bodies
+func TestCoulombPairForce(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(-1, 0, 0), vector.NewVector3(1, 0, 0))
+	a, b := bodies[0], bodies[1]
+	a.SetCharge(units.NewQuantity(1e-6, units.Coulomb))
+	b.SetCharge(units.NewQuantity(2e-6, units.Coulomb))
+
+	coulomb := force.NewCoulombForce()
+	forceOnA, forceOnB := coulomb.ApplyBetween(a, b)
+
+	// F = k q1 q2 / r², repulsive
+	expected := constants.CoulombConstant * 1e-6 * 2e-6 / 4
+	if math.Abs(forceOnA.X()+expected) > 1e-9*expected || math.Abs(forceOnB.X()-expected) > 1e-9*expected {
+		t.Errorf("Forces %v and %v, expected ∓%v N along X", forceOnA, forceOnB, expected)
+	}
+
+	// Opposite charges attract each other
+	b.SetCharge(units.NewQuantity(-2e-6, units.Coulomb))
+	if forceOnA, _ := coulomb.ApplyBetween(a, b); forceOnA.X() <= 0 {
+		t.Errorf("Opposite charges should attract each other: %v", forceOnA)
+	}
+
+	// In the world the bodies accelerate toward each other
+	w.AddForce(coulomb)
+	for i := 0; i < 5; i++ {
+		w.Step(0.01)
+	}
+	if a.Velocity().X() <= 0 || b.Velocity().X() >= 0 {
+		t.Errorf("The charged bodies did not attract each other: %v, %v", a.Velocity(), b.Velocity())
+	}
+}
+
+// TestCoulombBarnesHut verifies that the octree approximation matches the exact pair sum.
+// The error is measured relative to the RMS force, since the forces on the bodies in the middle of the cloud almost cancel.
+func TestCoulombBarnesHut(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	octree := space.NewOctree(space.NewAABB(
+		vector.NewVector3(-100, -100, -100),
+		vector.NewVector3(100, 100, 100),
+	), 8, 10)
+
+	bodies := make([]body.Body, 200)
+	for i := range bodies {
+		// Small grains, so that (almost) none straddles two octree cells and is counted twice
+		bodies[i] = createDustGrain(
+			vector.NewVector3(rng.Float64()*180-90, rng.Float64()*180-90, rng.Float64()*180-90),
+			vector.Zero3(),
+		)
+		bodies[i].SetCharge(units.NewQuantity(1+rng.Float64(), units.ElementaryCharge))
+		octree.Insert(bodies[i])
+	}
+
+	exact := force.DirectCoulomb(bodies, constants.CoulombConstant, nil)
+	errorSquared, forceSquared := 0.0, 0.0
+	for i, b := range bodies {
+		approximated := octree.CalculateCoulomb(b, 0.5, constants.CoulombConstant)
+		errorSquared += approximated.Sub(exact[i]).LengthSquared()
+		forceSquared += exact[i].LengthSquared()
+	}
+	relative := math.Sqrt(errorSquared / forceSquared)
+	t.Logf("RMS relative error of the Barnes-Hut Coulomb force: %.4f", relative)
+	if relative > 0.01 {
+		t.Errorf("Barnes-Hut Coulomb force differs from the exact one by %v", relative)
+	}
+}
+
+// TestGyration verifies that a charge in a uniform magnetic field moves on a circle with the Larmor radius
+// and the cyclotron period
+func TestGyration(t *testing.T) {
+	w, bodies := createTestWorld(vector.Zero3())
+	particle := bodies[0]
+	const charge = 0.5
+	const speed = 1.0
+	const field = 2.0
+	particle.SetCharge(units.NewQuantity(charge, units.Coulomb))
+	particle.SetVelocity(vector.NewVector3(speed, 0, 0))
+	w.AddForce(force.NewLorentzForce(force.NewUniformField(vector.NewVector3(0, 0, field))))
+
+	// r = m v / (q B) = 2 m, T = 2π m / (q B) = 2π s
+	radius := 2.0 * speed / (charge * field)
+	period := 2 * math.Pi * 2.0 / (charge * field)
+	dt := period / 2000
+	maxDistance := 0.0
+	for i := 0; i < 1000; i++ {
+		w.Step(dt)
+		maxDistance = math.Max(maxDistance, particle.Position().Length())
+	}
+
+	// After half a period the particle is on the opposite side of the circle, one diameter away
+	if math.Abs(maxDistance-2*radius) > 0.01*radius {
+		t.Errorf("Orbit diameter %v m, expected %v m", maxDistance, 2*radius)
+	}
+	if math.Abs(particle.Position().Z()) > 1e-12 {
+		t.Errorf("The particle left the plane perpendicular to the field: %v", particle.Position())
+	}
+
+	// The magnetic field does no work
+	if math.Abs(particle.Velocity().Length()-speed) > 0.01*speed {
+		t.Errorf("Speed %v m/s, expected %v m/s", particle.Velocity().Length(), speed)
+	}
+}
+
+// TestDipoleField verifies the field of a magnetic dipole and that uncharged bodies ignore it
+func TestDipoleField(t *testing.T) {
+	earth := createEarth()
+	dipole := force.NewDipoleField(earth, vector.NewVector3(0, 0, constants.EarthMagneticMoment))
+
+	// On the equator the field is antiparallel to the moment: B = -μ0 m / (4π r³)
+	r := constants.EarthRadius
+	expected := -constants.VacuumPermeability * constants.EarthMagneticMoment / (4 * math.Pi * r * r * r)
+	field := dipole.FieldAt(vector.NewVector3(r, 0, 0))
+	if math.Abs(field.Z()-expected) > 1e-9*math.Abs(expected) || math.Abs(field.X()) > 1e-20 {
+		t.Errorf("Equatorial field %v T, expected %v T along Z", field, expected)
+	}
+	t.Logf("Equatorial surface field: %.1f μT", field.Z()*1e6)
+
+	// On the axis the field is twice as strong and parallel to the moment
+	if axial := dipole.FieldAt(vector.NewVector3(0, 0, r)); math.Abs(axial.Z()+2*expected) > 1e-9*math.Abs(expected) {
+		t.Errorf("Polar field %v T, expected %v T", axial.Z(), -2*expected)
+	}
+
+	lorentz := force.NewLorentzForce(dipole)
+	dust := createDustGrain(vector.NewVector3(2*r, 0, 0), vector.NewVector3(0, 1000, 0))
+	if f := lorentz.Apply(dust); f.Length() != 0 || lorentz.AppliesTo(dust) {
+		t.Errorf("An uncharged body should not feel the Lorentz force: %v", f)
+	}
+
+	// A charged grain moving through the field is deflected perpendicular to its velocity
+	dust.SetCharge(units.NewQuantity(1e-12, units.Coulomb))
+	f := lorentz.Apply(dust)
+	if f.Length() == 0 || math.Abs(f.Dot(dust.Velocity())) > 1e-9*f.Length() {
+		t.Errorf("Unexpected Lorentz force %v", f)
+	}
+
+	// The field moves with the planet: a grain at rest relative to it feels no magnetic force
+	earth.SetStatic(false)
+	earth.SetVelocity(dust.Velocity())
+	if f := lorentz.Apply(dust); f.Length() != 0 {
+		t.Errorf("A grain comoving with the dipole should not be deflected: %v", f)
+	}
+}
+
+// createDustGrain creates a 1 g dust grain with the given position and velocity
+func createDustGrain(position, velocity vector.Vector3) body.Body {
+	return body.NewRigidBody(
+		units.NewQuantity(1e-3, units.Kilogram),
+		units.NewQuantity(1e-3, units.Meter),
+		position,
+		velocity,
+		material.Rock,
+	)
+}
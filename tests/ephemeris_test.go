@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/ephemeris"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// horizonsCSV is a Horizons vector table of the Earth around the Sun in CSV format with AU-D units
+const horizonsCSV = `*******************************************************************************
+ Revised: April 12, 2021                 Earth                              399
+ Vol. Mean Radius (km)    = 6371.01+-0.02   Mass x10^24 (kg)= 5.97219+-0.0006
+*******************************************************************************
+Ephemeris / WWW_USER Sat Oct 17 12:00:00 2026 Pasadena, USA      / Horizons
+*******************************************************************************
+Target body name: Earth (399)                     {source: DE441}
+Center body name: Sun (10)                        {source: DE441}
+*******************************************************************************
+Output units    : AU-D
+Output type     : GEOMETRIC cartesian states
+*******************************************************************************
+            JDTDB,            Calendar Date (TDB),                      X,                      Y,                      Z,                     VX,                     VY,                     VZ,
+**************************************************************************************************************************************************************************************
+$$SOE
+2460001.500000000, A.D. 2023-Feb-25 00:00:00.0000,  1.000000000000000E+00,  0.000000000000000E+00,  0.000000000000000E+00,  0.000000000000000E+00,  1.720000000000000E-02,  0.000000000000000E+00,
+2460000.500000000, A.D. 2023-Feb-24 00:00:00.0000,  9.000000000000000E-01,  2.000000000000000E-01, -1.000000000000000E-01,  1.000000000000000E-03,  1.700000000000000E-02,  0.000000000000000E+00,
+$$EOE
+**************************************************************************************************************************************************************************************
+`
+
+// horizonsText formats state vectors relative to the Sun as a Horizons vector table in text format with KM-S units
+func horizonsText(target string, id int, epochs []float64, positions, velocities []vector.Vector3) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Target body name: %s (%d)                     {source: DE441}\n", target, id)
+	sb.WriteString("Center body name: Sun (10)                        {source: DE441}\n")
+	sb.WriteString("Output units    : KM-S\n")
+	sb.WriteString("$$SOE\n")
+	for i, epoch := range epochs {
+		p, v := positions[i].Scale(1e-3), velocities[i].Scale(1e-3)
+		fmt.Fprintf(&sb, "%.9f = A.D. 2023-Feb-24 00:00:00.0000 TDB \n", epoch)
+		fmt.Fprintf(&sb, " X =%22.15E Y =%22.15E Z =%22.15E\n", p.X(), p.Y(), p.Z())
+		fmt.Fprintf(&sb, " VX=%22.15E VY=%22.15E VZ=%22.15E\n", v.X(), v.Y(), v.Z())
+		fmt.Fprintf(&sb, " LT= 4.990000000000000E+02 RG= 1.490000000000000E+08 RR= 1.000000000000000E-01\n")
+	}
+	sb.WriteString("$$EOE\n")
+	return sb.String()
+}
+
+// TestHorizonsCSV verifies the parsing of a CSV table, the conversion of its units and the interpolation of its states
+func TestHorizonsCSV(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "earth.csv")
+	if err := os.WriteFile(filename, []byte(horizonsCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	table, err := ephemeris.LoadFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if table.Target != "Earth" || table.TargetID != 399 || table.Center != "Sun" || table.CenterID != 10 {
+		t.Errorf("Target %s (%d), center %s (%d)", table.Target, table.TargetID, table.Center, table.CenterID)
+	}
+	if math.Abs(table.Mass-5.97219e24) > 1e18 || math.Abs(table.Radius-6371010) > 1e-3 {
+		t.Errorf("Mass %v kg and radius %v m, expected 5.97219e24 kg and 6371010 m", table.Mass, table.Radius)
+	}
+
+	// The states are sorted by epoch and converted to SI units
+	if len(table.States) != 2 || table.States[0].Epoch != 2460000.5 {
+		t.Fatalf("States %+v", table.States)
+	}
+	last := table.States[1]
+	if !vectorsAlmostEqual(last.Position, vector.NewVector3(constants.AstronomicalUnit, 0, 0), 1e-3) {
+		t.Errorf("Position %v, expected 1 AU on the X axis", last.Position)
+	}
+	if expected := 0.0172 * constants.AstronomicalUnit / 86400; math.Abs(last.Velocity.Y()-expected) > 1e-9 {
+		t.Errorf("Velocity %v m/s, expected %v m/s", last.Velocity.Y(), expected)
+	}
+
+	// The interpolation matches the states at the ends and stays between them
+	if state, ok := table.StateAt(2460001.5); !ok || !vectorsAlmostEqual(state.Position, last.Position, 1e-3) {
+		t.Errorf("State at the last epoch %+v", state)
+	}
+	middle, ok := table.StateAt(2460001)
+	if !ok || middle.Position.X() < 0.9*constants.AstronomicalUnit || middle.Position.X() > constants.AstronomicalUnit {
+		t.Errorf("State in the middle %+v", middle)
+	}
+	if _, ok := table.StateAt(2460002); ok {
+		t.Errorf("A state was returned outside the table")
+	}
+
+	if _, err := ephemeris.Parse(strings.NewReader("Output units : KM-S\n$$SOE\n$$EOE\n")); err == nil {
+		t.Errorf("An empty table was parsed without errors")
+	}
+}
+
+// TestEphemerisDrift verifies that the Earth loaded from a text table follows its ephemeris in the simulation
+func TestEphemerisDrift(t *testing.T) {
+	// Table generated from a Keplerian orbit around the Sun, one state per day
+	data := ephemeris.MajorBodies[399]
+	mu := constants.G * (ephemeris.MajorBodies[10].Mass + data.Mass)
+	elements := orbit.Elements{SemiMajorAxis: constants.AstronomicalUnit, Eccentricity: 0.0167, Inclination: 0.1, TrueAnomaly: 1}
+	position, velocity := elements.StateVectors(mu)
+	epochs := []float64{2460000.5, 2460001.5, 2460002.5}
+	var positions, velocities []vector.Vector3
+	for i := range epochs {
+		p, v := orbit.Propagate(position, velocity, mu, float64(i)*86400)
+		positions = append(positions, p)
+		velocities = append(velocities, v)
+	}
+	table, err := ephemeris.Parse(strings.NewReader(horizonsText("Earth", 399, epochs, positions, velocities)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Mass != data.Mass || table.Radius != data.Radius {
+		t.Errorf("Mass %v kg and radius %v m, expected the data of the Earth", table.Mass, table.Radius)
+	}
+	if !vectorsAlmostEqual(table.States[2].Velocity, velocities[2], 1e-9) {
+		t.Errorf("Velocity %v, expected %v", table.States[2].Velocity, velocities[2])
+	}
+
+	// Between two states the interpolation follows the orbit
+	halfway, _ := orbit.Propagate(position, velocity, mu, 43200)
+	if state, _ := table.StateAt(2460001); state.Position.Sub(halfway).Length() > 1e3 {
+		t.Errorf("Interpolated position %v m off the orbit", state.Position.Sub(halfway).Length())
+	}
+
+	system, err := ephemeris.NewSystem(2460000.5, material.Rock, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e12, -1e12, -1e12), vector.NewVector3(1e12, 1e12, 1e12)))
+	w.AddForce(force.NewGravitationalForce())
+	for _, b := range system.GetBodies() {
+		w.AddBody(b)
+	}
+	if len(system.GetBodies()) != 2 {
+		t.Fatalf("Expected the Earth and the Sun, got %d bodies", len(system.GetBodies()))
+	}
+	if earth, ok := system.GetBody("Earth"); !ok || !earth.HasTag("Earth") {
+		t.Errorf("The Earth is missing from the system")
+	}
+
+	dt := 60.0
+	for i := 0; i < 2*1440; i++ {
+		w.Step(dt)
+	}
+	drift := system.Drift(2 * 86400)
+	if d, ok := drift["Earth"]; !ok || d > 100 {
+		t.Errorf("The Earth drifted by %v m from its ephemeris in two days", d)
+	}
+	if len(system.Drift(3*86400)) != 0 {
+		t.Errorf("Drift measured outside the table")
+	}
+}
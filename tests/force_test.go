@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// createTestWorld creates a world without forces and with the given bodies at rest
+func createTestWorld(positions ...vector.Vector3) (*world.PhysicalWorld, []body.Body) {
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-100, -100, -100),
+		vector.NewVector3(100, 100, 100),
+	))
+
+	bodies := make([]body.Body, len(positions))
+	for i, position := range positions {
+		bodies[i] = body.NewRigidBody(
+			units.NewQuantity(2.0, units.Kilogram),
+			units.NewQuantity(0.5, units.Meter),
+			position,
+			vector.Zero3(),
+			material.Iron,
+		)
+		w.AddBody(bodies[i])
+	}
+	return w, bodies
+}
+
+// heavyOnly is a test force that only acts on bodies heavier than a threshold
+type heavyOnly struct {
+	*force.ConstantForce
+	threshold float64
+}
+
+// AppliesTo returns true if the body is heavier than the threshold
+func (h *heavyOnly) AppliesTo(b body.Body) bool {
+	return b.Mass().Value() > h.threshold
+}
+
+// TestThrusterAttachedToOneBody verifies that an attached force only pushes its target
+func TestThrusterAttachedToOneBody(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(-10, 0, 0), vector.NewVector3(10, 0, 0))
+	spacecraft, other := bodies[0], bodies[1]
+
+	thruster := force.NewThrusterForce(vector.NewVector3(4, 0, 0))
+	thruster.SetThrottle(0.5)
+	w.AttachForce(thruster, spacecraft.ID())
+
+	if len(w.GetForces()) != 1 {
+		t.Fatalf("Attaching a force should add it to the world, got %d forces", len(w.GetForces()))
+	}
+	if targets := w.GetForceTargets(thruster); len(targets) != 1 || targets[0] != spacecraft.ID() {
+		t.Fatalf("Unexpected force targets: %v", targets)
+	}
+
+	// a = 0.5 * 4 N / 2 kg = 1 m/s²
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+	}
+	if math.Abs(spacecraft.Velocity().X()-1.0) > 0.11 {
+		t.Errorf("Spacecraft velocity %v, expected about 1 m/s", spacecraft.Velocity().X())
+	}
+	if other.Velocity().Length() != 0 || other.Position().X() != 10 {
+		t.Errorf("The thruster pushed a body it is not attached to: %v", other.Velocity())
+	}
+
+	// Once detached the thruster does not push anything
+	w.DetachForce(thruster, spacecraft.ID())
+	w.Step(0.1) // The Verlet velocity lags one step behind the forces
+	velocity := spacecraft.Velocity().X()
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+	}
+	if math.Abs(spacecraft.Velocity().X()-velocity) > 1e-9 {
+		t.Errorf("The detached thruster still pushes: %v -> %v", velocity, spacecraft.Velocity().X())
+	}
+}
+
+// TestSpringConnectsOnlyAttachedBodies verifies that an attached pair force only acts between its targets
+func TestSpringConnectsOnlyAttachedBodies(t *testing.T) {
+	w, bodies := createTestWorld(
+		vector.NewVector3(-2, 0, 0),
+		vector.NewVector3(2, 0, 0),
+		vector.NewVector3(0, 5, 0),
+	)
+	a, b, c := bodies[0], bodies[1], bodies[2]
+
+	spring := force.NewSpringForce(10, 2, 0)
+	w.AttachForce(spring, a.ID(), b.ID())
+
+	for i := 0; i < 10; i++ {
+		w.Step(0.01)
+	}
+
+	// The stretched spring pulls the connected bodies toward each other
+	if a.Velocity().X() <= 0 || b.Velocity().X() >= 0 {
+		t.Errorf("The spring did not pull the connected bodies: %v, %v", a.Velocity(), b.Velocity())
+	}
+	if c.Velocity().Length() != 0 {
+		t.Errorf("The spring acted on a body it is not attached to: %v", c.Velocity())
+	}
+}
+
+// TestConstantForceActsOnEachBody verifies that a constant force pushes every body, including a lone one
+func TestConstantForceActsOnEachBody(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(0, 0, 0))
+	w.AddForce(force.NewConstantForce(vector.NewVector3(0, -2, 0)))
+
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+	}
+
+	// a = -2 N / 2 kg = -1 m/s²
+	if math.Abs(bodies[0].Velocity().Y()+1.0) > 0.11 {
+		t.Errorf("Velocity %v, expected about -1 m/s", bodies[0].Velocity().Y())
+	}
+}
+
+// TestForceBodyFilter verifies that forces implementing BodyFilter skip the bodies they do not apply to
+func TestForceBodyFilter(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(-10, 0, 0), vector.NewVector3(10, 0, 0))
+	heavy, light := bodies[0], bodies[1]
+	heavy.SetMass(units.NewQuantity(5.0, units.Kilogram))
+
+	w.AddForce(&heavyOnly{ConstantForce: force.NewConstantForce(vector.NewVector3(5, 0, 0)), threshold: 3})
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+	}
+
+	if heavy.Velocity().X() <= 0 {
+		t.Errorf("The filtered force did not act on the heavy body")
+	}
+	if light.Velocity().Length() != 0 {
+		t.Errorf("The filtered force acted on the light body: %v", light.Velocity())
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/frame"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// TestBarycentricFrame verifies the barycenter of a system and the shift to the barycentric frame
+func TestBarycentricFrame(t *testing.T) {
+	heavy := body.NewRigidBody(units.NewQuantity(3, units.Kilogram), units.NewQuantity(1, units.Meter), vector.NewVector3(10, 0, 0), vector.NewVector3(0, 1, 0), material.Iron)
+	light := body.NewRigidBody(units.NewQuantity(1, units.Kilogram), units.NewQuantity(1, units.Meter), vector.NewVector3(50, 20, 0), vector.NewVector3(4, 0, 0), material.Iron)
+	bodies := []body.Body{heavy, light}
+
+	position, velocity := frame.Barycenter(bodies)
+	if !vectorsAlmostEqual(position, vector.NewVector3(20, 5, 0), 1e-12) || !vectorsAlmostEqual(velocity, vector.NewVector3(1, 0.75, 0), 1e-12) {
+		t.Errorf("Barycenter at %v moving at %v", position, velocity)
+	}
+
+	// States relative to the barycenter and to a body
+	barycentric := frame.NewBarycentricFrame(bodies...)
+	if p, v := frame.StateIn(barycentric, light); !vectorsAlmostEqual(p, vector.NewVector3(30, 15, 0), 1e-12) || !vectorsAlmostEqual(v, vector.NewVector3(3, -0.75, 0), 1e-12) {
+		t.Errorf("Barycentric state %v, %v", p, v)
+	}
+	if p, v := frame.StateIn(frame.NewBodyFrame(heavy), light); !vectorsAlmostEqual(p, vector.NewVector3(40, 20, 0), 1e-12) || !vectorsAlmostEqual(v, vector.NewVector3(4, -1, 0), 1e-12) {
+		t.Errorf("State relative to the heavy body %v, %v", p, v)
+	}
+
+	frame.ShiftToBarycenter(bodies)
+	position, velocity = frame.Barycenter(bodies)
+	if position.Length() > 1e-12 || velocity.Length() > 1e-12 {
+		t.Errorf("Barycenter at %v moving at %v after the shift", position, velocity)
+	}
+	if separation := light.Position().Sub(heavy.Position()); !vectorsAlmostEqual(separation, vector.NewVector3(40, 20, 0), 1e-12) {
+		t.Errorf("The shift changed the separation of the bodies to %v", separation)
+	}
+}
+
+// TestRotatingFrame verifies that the primary and the secondary of a circular orbit are at rest in the synodic frame
+func TestRotatingFrame(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e12, -1e12, -1e12), vector.NewVector3(1e12, 1e12, 1e12)))
+	w.AddForce(force.NewGravitationalForce())
+	sun := body.NewRigidBody(units.NewQuantity(constants.SolarMass, units.Kilogram), units.NewQuantity(6.9634e8, units.Meter), vector.Zero3(), vector.Zero3(), material.Rock)
+	w.AddBody(sun)
+	earth := orbit.NewBodyOnOrbit(sun, orbit.Circular(constants.AstronomicalUnit, 0.4, 1, 2), units.NewQuantity(constants.EarthMass, units.Kilogram), units.NewQuantity(6.371e6, units.Meter), material.Rock)
+	w.AddBody(earth)
+	frame.ShiftToBarycenter(w.GetBodies())
+
+	synodic := frame.NewRotatingFrame(sun, earth)
+	mu := constants.EarthMass / (constants.SolarMass + constants.EarthMass)
+	expected := vector.NewVector3((1-mu)*constants.AstronomicalUnit, 0, 0)
+
+	// A quarter of a year later the Earth is still on the X axis, at rest
+	// (up to the velocity of the Verlet integrator, which lags one step behind the position)
+	for i := 0; i < 2000; i++ {
+		w.Step(3944)
+	}
+	position, velocity := frame.StateIn(synodic, earth)
+	if !vectorsAlmostEqual(position, expected, 1e-6*constants.AstronomicalUnit) || velocity.Length() > 1e-3*earth.Velocity().Length() {
+		t.Errorf("Earth at %v moving at %v in the synodic frame, expected at rest at %v", position, velocity, expected)
+	}
+	if p, _ := frame.StateIn(synodic, sun); math.Abs(p.X()+mu*constants.AstronomicalUnit) > 1 || math.Abs(p.Y()) > 1 {
+		t.Errorf("Sun at %v in the synodic frame", p)
+	}
+
+	// The angular velocity is the mean motion, the conversions are inverse of each other
+	if n := synodic.AngularVelocity().Length(); math.Abs(n-2*math.Pi/orbit.Circular(constants.AstronomicalUnit, 0, 0, 0).Period(orbit.GravitationalParameter(sun, earth))) > 1e-12 {
+		t.Errorf("Angular velocity %v rad/s", n)
+	}
+	p, v := vector.NewVector3(1e9, -2e10, 3e8), vector.NewVector3(100, 2000, -30)
+	backPosition, backVelocity := synodic.FromFrame(synodic.ToFrame(p, v))
+	if !vectorsAlmostEqual(backPosition, p, 1e-3) || !vectorsAlmostEqual(backVelocity, v, 1e-9) {
+		t.Errorf("Round trip gave %v, %v, expected %v, %v", backPosition, backVelocity, p, v)
+	}
+}
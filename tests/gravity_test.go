@@ -158,7 +158,7 @@ func TestEnergyConservation(t *testing.T) {
 	w.AddBody(body2)
 
 	// Calculate the initial energy of the system
-	initialEnergy := calculateTotalEnergy(w)
+	initialEnergy := w.ComputeDiagnostics(world.DirectPotential).TotalEnergy()
 
 	// Run the simulation for 100 steps
 	dt := 0.01
@@ -167,7 +167,7 @@ func TestEnergyConservation(t *testing.T) {
 	}
 
 	// Calculate the final energy of the system
-	finalEnergy := calculateTotalEnergy(w)
+	finalEnergy := w.ComputeDiagnostics(world.DirectPotential).TotalEnergy()
 
 	// Verify that energy is conserved (with a 1% tolerance)
 	energyDifference := math.Abs(finalEnergy-initialEnergy) / math.Abs(initialEnergy)
@@ -176,38 +176,6 @@ func TestEnergyConservation(t *testing.T) {
 	}
 }
 
-// calculateTotalEnergy calculates the total energy (kinetic + potential) of a system
-func calculateTotalEnergy(w world.World) float64 {
-	bodies := w.GetBodies()
-	totalEnergy := 0.0
-
-	// Calculate the kinetic energy of each body
-	for _, b := range bodies {
-		if b.IsStatic() {
-			continue
-		}
-		mass := b.Mass().Value()
-		velocity := b.Velocity()
-		kineticEnergy := 0.5 * mass * velocity.LengthSquared()
-		totalEnergy += kineticEnergy
-	}
-
-	// Calculate the gravitational potential energy of each pair of bodies
-	for i := 0; i < len(bodies); i++ {
-		for j := i + 1; j < len(bodies); j++ {
-			body1 := bodies[i]
-			body2 := bodies[j]
-			mass1 := body1.Mass().Value()
-			mass2 := body2.Mass().Value()
-			distance := body1.Position().Distance(body2.Position())
-			potentialEnergy := -constants.G * mass1 * mass2 / distance
-			totalEnergy += potentialEnergy
-		}
-	}
-
-	return totalEnergy
-}
-
 // TestBarnesHutAccuracy verifies the accuracy of the Barnes-Hut algorithm
 func TestBarnesHutAccuracy(t *testing.T) {
 	// Create an octree
@@ -343,3 +311,126 @@ func TestMultithreadingPerformance(t *testing.T) {
 	t.Logf("Time with multithreading: %v", duration1)
 	t.Logf("Time without multithreading: %v", duration2)
 }
+
+// createRandomBodies creates bodies with random positions and masses inside a cube of the given half size
+func createRandomBodies(count int, halfSize float64) []body.Body {
+	bodies := make([]body.Body, count)
+	for i := range bodies {
+		position := vector.NewVector3(
+			(rand.Float64()*2-1)*halfSize,
+			(rand.Float64()*2-1)*halfSize,
+			(rand.Float64()*2-1)*halfSize,
+		)
+		bodies[i] = body.NewRigidBody(
+			units.NewQuantity(rand.Float64()*1000+1, units.Kilogram),
+			units.NewQuantity(1.0, units.Meter),
+			position,
+			vector.Zero3(),
+			material.Rock,
+		)
+	}
+	return bodies
+}
+
+// TestDirectGravitySolver verifies the direct solver against the pairwise force and momentum conservation
+func TestDirectGravitySolver(t *testing.T) {
+	bodies := createRandomBodies(50, 100)
+	gravityForce := force.NewGravitationalForce()
+
+	for _, compensated := range []bool{false, true} {
+		forces := force.DirectGravity(bodies, gravityForce.G, compensated, nil)
+
+		// Compare each force with the sum of the pairwise forces
+		for i, a := range bodies {
+			expected := vector.Zero3()
+			for j, b := range bodies {
+				if i == j {
+					continue
+				}
+				forceOnA, _ := gravityForce.ApplyBetween(a, b)
+				expected = expected.Add(forceOnA)
+			}
+
+			relativeError := forces[i].Sub(expected).Length() / expected.Length()
+			if relativeError > 1e-12 {
+				t.Errorf("Direct solver (compensated=%v) differs from pairwise sum for body %d: relative error %v", compensated, i, relativeError)
+			}
+		}
+
+		// Pair symmetry guarantees that the total force vanishes
+		total := vector.Zero3()
+		maxForce := 0.0
+		for _, f := range forces {
+			total = total.Add(f)
+			maxForce = math.Max(maxForce, f.Length())
+		}
+		if total.Length()/maxForce > 1e-12 {
+			t.Errorf("Direct solver (compensated=%v) does not conserve momentum: total force %v", compensated, total)
+		}
+	}
+}
+
+// TestFMMGravityAccuracy verifies the accuracy of the FMM solver against the direct solver
+func TestFMMGravityAccuracy(t *testing.T) {
+	bodies := createRandomBodies(500, 100)
+	exact := force.DirectGravity(bodies, constants.G, true, nil)
+
+	for _, theta := range []float64{0.3, 0.5} {
+		approximate := force.FMMGravity(bodies, constants.G, theta, nil)
+
+		sumError := 0.0
+		for i := range bodies {
+			sumError += approximate[i].Sub(exact[i]).Length() / exact[i].Length()
+		}
+		meanError := sumError / float64(len(bodies))
+		t.Logf("Theta = %v, mean relative error = %v%%", theta, meanError*100)
+
+		if meanError > 0.05 {
+			t.Errorf("FMM error too large for theta = %v: %v%%", theta, meanError*100)
+		}
+	}
+}
+
+// TestGravitySolverSelection verifies that the world honors the solver selected on the force
+func TestGravitySolverSelection(t *testing.T) {
+	bounds := space.NewAABB(
+		vector.NewVector3(-1000, -1000, -1000),
+		vector.NewVector3(1000, 1000, 1000),
+	)
+
+	for _, solver := range []force.GravitySolver{force.BarnesHutSolver, force.DirectSolver, force.FMMSolver} {
+		w := world.NewPhysicalWorld(bounds)
+		gravityForce := force.NewGravitationalForce()
+		gravityForce.SetSolver(solver)
+		w.AddForce(gravityForce)
+
+		body1 := body.NewRigidBody(
+			units.NewQuantity(1.0e9, units.Kilogram),
+			units.NewQuantity(1.0, units.Meter),
+			vector.NewVector3(-50, 0, 0),
+			vector.Zero3(),
+			material.Rock,
+		)
+		body2 := body.NewRigidBody(
+			units.NewQuantity(1.0e9, units.Kilogram),
+			units.NewQuantity(1.0, units.Meter),
+			vector.NewVector3(50, 0, 0),
+			vector.Zero3(),
+			material.Rock,
+		)
+		w.AddBody(body1)
+		w.AddBody(body2)
+
+		for i := 0; i < 10; i++ {
+			w.Step(1.0)
+		}
+
+		// The bodies must attract each other
+		if body1.Velocity().X() <= 0 || body2.Velocity().X() >= 0 {
+			t.Errorf("Solver %v: bodies are not attracted: v1 = %v, v2 = %v", solver, body1.Velocity(), body2.Velocity())
+		}
+		if body2.Position().Sub(body1.Position()).Length() >= 100 {
+			t.Errorf("Solver %v: bodies did not get closer", solver)
+		}
+	}
+}
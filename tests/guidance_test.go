@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/guidance"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// createSpacecraft creates a 100 kg controllable body with 100 N of translation thrust and adds it to the world
+func createSpacecraft(w world.World, position, velocity vector.Vector3) *body.ControllableRigidBody {
+	spacecraft := body.NewControllableRigidBody(body.NewRigidBody(
+		units.NewQuantity(100, units.Kilogram),
+		units.NewQuantity(1, units.Meter),
+		position,
+		velocity,
+		material.Iron,
+	), 100, 1)
+	w.AddBody(spacecraft)
+	return spacecraft
+}
+
+// fly steps the world with the autopilot for a duration
+func fly(w world.World, autopilot *guidance.Autopilot, duration, dt float64) {
+	for end := w.GetTime() + duration; w.GetTime() < end-dt/2; {
+		autopilot.Update(w.GetTime(), dt)
+		w.Step(dt)
+	}
+}
+
+// TestHoldAttitude verifies that the attitude PID turns the body toward a direction and stops it there
+func TestHoldAttitude(t *testing.T) {
+	w, _ := createTestWorld()
+	spacecraft := createSpacecraft(w, vector.Zero3(), vector.Zero3())
+	autopilot := guidance.NewAutopilot(spacecraft)
+	autopilot.SetMode(guidance.NewHoldAttitude(vector.NewVector3(1, 1, 0)))
+
+	fly(w, autopilot, 15, 0.01)
+
+	target := vector.NewVector3(1, 1, 0).Normalize()
+	if angle := math.Acos(math.Min(1, spacecraft.Forward().Dot(target))); angle > 1e-3 {
+		t.Errorf("Pointing error %v rad, forward %v", angle, spacecraft.Forward())
+	}
+	if spacecraft.AngularVelocity().Length() > 1e-3 {
+		t.Errorf("The body is still turning: %v rad/s", spacecraft.AngularVelocity())
+	}
+}
+
+// TestPointRelative verifies the directions relative to an orbit and that the body points prograde
+func TestPointRelative(t *testing.T) {
+	w, bodies := createTestWorld(vector.Zero3())
+	planet := bodies[0]
+	planet.SetStatic(true)
+	spacecraft := createSpacecraft(w, vector.NewVector3(10, 0, 0), vector.NewVector3(0, 0.1, 0))
+
+	expected := map[guidance.Direction]vector.Vector3{
+		guidance.Prograde:   vector.NewVector3(0, 1, 0),
+		guidance.Retrograde: vector.NewVector3(0, -1, 0),
+		guidance.Normal:     vector.NewVector3(0, 0, 1),
+		guidance.AntiNormal: vector.NewVector3(0, 0, -1),
+		guidance.RadialOut:  vector.NewVector3(1, 0, 0),
+		guidance.RadialIn:   vector.NewVector3(-1, 0, 0),
+	}
+	for direction, want := range expected {
+		if got := direction.Vector(spacecraft, planet); !vectorsAlmostEqual(got, want, 1e-12) {
+			t.Errorf("%v is %v, expected %v", direction, got, want)
+		}
+	}
+
+	autopilot := guidance.NewAutopilot(spacecraft)
+	autopilot.SetMode(guidance.NewPointRelative(planet, guidance.Prograde))
+	fly(w, autopilot, 15, 0.01)
+	if prograde := guidance.Prograde.Vector(spacecraft, planet); spacecraft.Forward().Dot(prograde) < math.Cos(1e-3) {
+		t.Errorf("Forward %v, prograde %v", spacecraft.Forward(), prograde)
+	}
+}
+
+// TestPlannedBurn verifies that a burn waits for its start time and delivers its delta-v with the engine
+func TestPlannedBurn(t *testing.T) {
+	w, _ := createTestWorld()
+	spacecraft := createSpacecraft(w, vector.Zero3(), vector.Zero3())
+	spacecraft.SetEngine(body.NewEngine(
+		units.NewQuantity(200, units.Newton),
+		units.NewQuantity(300, units.Second),
+		units.NewQuantity(20, units.Kilogram),
+	))
+
+	burn := guidance.NewPlannedBurn(units.NewQuantity(10, units.Second), vector.NewVector3(0, 0, 3))
+	autopilot := guidance.NewAutopilot(spacecraft)
+	autopilot.SetMode(burn)
+
+	// The body turns toward the burn without firing before the start time
+	fly(w, autopilot, 10, 0.01)
+	if spacecraft.Velocity().Length() > 1e-12 || spacecraft.Fuel().Value() != 20 {
+		t.Fatalf("The engine fired before the start time: %v", spacecraft.Velocity())
+	}
+
+	fly(w, autopilot, 10, 0.01)
+	if !burn.IsComplete() {
+		t.Fatalf("The burn did not complete, %v m/s remaining", burn.Remaining())
+	}
+	if !vectorsAlmostEqual(spacecraft.Velocity(), vector.NewVector3(0, 0, 3), 1e-3) {
+		t.Errorf("Velocity after the burn %v, expected (0, 0, 3)", spacecraft.Velocity())
+	}
+	if spacecraft.Fuel().Value() >= 20 {
+		t.Errorf("The burn did not consume propellant")
+	}
+}
+
+// TestStationKeeping verifies that the translation thrusters bring the body back to its station
+func TestStationKeeping(t *testing.T) {
+	w, bodies := createTestWorld(vector.NewVector3(20, 0, 0))
+	station := bodies[0]
+	station.SetVelocity(vector.NewVector3(0, 0.2, 0))
+	spacecraft := createSpacecraft(w, vector.NewVector3(25, 3, 0), vector.NewVector3(0.5, 0, 0))
+
+	keeping := guidance.NewStationKeeping(vector.NewVector3(2, 0, 0), station)
+	autopilot := guidance.NewAutopilot(spacecraft)
+	autopilot.SetMode(keeping)
+	fly(w, autopilot, 40, 0.01)
+
+	if offset := keeping.Error(spacecraft).Length(); offset > 0.01 {
+		t.Errorf("The body is %v m from its station", offset)
+	}
+	if relative := spacecraft.Velocity().Sub(station.Velocity()).Length(); relative > 0.01 {
+		t.Errorf("The body drifts from its station at %v m/s", relative)
+	}
+}
+
+// mainEngineBurn points the body along a direction and fires the main engine along it
+type mainEngineBurn struct {
+	direction vector.Vector3
+}
+
+// Command returns the attitude and thrust along the direction of the burn
+func (m mainEngineBurn) Command(b body.ControllableBody, t, dt float64) guidance.Command {
+	return guidance.Command{Attitude: m.direction, Thrust: m.direction, Throttle: 1}
+}
+
+// TestKeyboardAndAutopilotAgreeOnForward verifies that the keyboard and the autopilot fire a yawed body along
+// the same forward direction
+func TestKeyboardAndAutopilotAgreeOnForward(t *testing.T) {
+	w, _ := createTestWorld()
+	spacecraft := createSpacecraft(w, vector.Zero3(), vector.Zero3())
+	spacecraft.SetRotation(vector.NewVector3(0, 0.6, 0))
+
+	spacecraft.HandleInput(0.01, true, false, false, false, false, false, false, false, false, false)
+	keyboard := spacecraft.Acceleration().Normalize()
+	if !vectorsAlmostEqual(keyboard, spacecraft.Forward(), 1e-12) {
+		t.Fatalf("Keyboard thrust along %v, forward %v", keyboard, spacecraft.Forward())
+	}
+	spacecraft.SetAcceleration(vector.Zero3())
+
+	// Already pointing along the keyboard thrust, the autopilot fires at once along it
+	autopilot := guidance.NewAutopilot(spacecraft)
+	autopilot.SetMode(mainEngineBurn{direction: keyboard})
+	autopilot.Update(w.GetTime(), 0.01)
+	if acceleration := spacecraft.Acceleration(); acceleration.Length() == 0 || !vectorsAlmostEqual(acceleration.Normalize(), keyboard, 1e-12) {
+		t.Errorf("Autopilot thrust %v, keyboard thrust along %v", acceleration, keyboard)
+	}
+}
+
+// TestAttitudeControlUsesAngularAcceleration verifies that the attitude control is applied as an angular
+// acceleration, integrated by the world, rather than as a change of the angular velocity
+func TestAttitudeControlUsesAngularAcceleration(t *testing.T) {
+	w, _ := createTestWorld()
+	spacecraft := createSpacecraft(w, vector.Zero3(), vector.Zero3())
+	autopilot := guidance.NewAutopilot(spacecraft)
+	autopilot.SetMaxAngularAcceleration(0.5)
+	autopilot.SetMode(guidance.NewHoldAttitude(vector.NewVector3(1, 0, 0)))
+
+	autopilot.Update(w.GetTime(), 0.1)
+	acceleration := spacecraft.AngularAcceleration()
+	if spacecraft.AngularVelocity().Length() != 0 || math.Abs(acceleration.Length()-0.5) > 1e-12 {
+		t.Fatalf("Angular velocity %v and acceleration %v before the step", spacecraft.AngularVelocity(), acceleration)
+	}
+	w.Step(0.1)
+	if !vectorsAlmostEqual(spacecraft.AngularVelocity(), acceleration.Scale(0.1), 1e-12) || spacecraft.AngularAcceleration().Length() != 0 {
+		t.Errorf("Angular velocity %v after the step, expected %v", spacecraft.AngularVelocity(), acceleration.Scale(0.1))
+	}
+}
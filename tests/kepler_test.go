@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// TestKeplerPropagator verifies the universal variable propagator against the closed-form solutions
+// of elliptic, hyperbolic and parabolic orbits
+func TestKeplerPropagator(t *testing.T) {
+	mu := constants.G * constants.SolarMass
+	q := constants.AstronomicalUnit
+
+	// Elliptic: from the periapsis to the apoapsis in half a period, and back after a full one
+	ellipse := orbit.Elements{SemiMajorAxis: q / 0.7, Eccentricity: 0.3, Inclination: 0.2, ArgumentOfPeriapsis: 1}
+	position, velocity := ellipse.StateVectors(mu)
+	period := ellipse.Period(mu)
+	half, _ := orbit.Propagate(position, velocity, mu, period/2)
+	if math.Abs(half.Length()-ellipse.Apoapsis()) > 1e-9*ellipse.Apoapsis() {
+		t.Errorf("Distance after half a period %v m, expected the apoapsis %v m", half.Length(), ellipse.Apoapsis())
+	}
+	full, fullVelocity := orbit.Propagate(position, velocity, mu, 3*period)
+	if !vectorsAlmostEqual(full, position, 1e-9*q) || !vectorsAlmostEqual(fullVelocity, velocity, 1e-9*velocity.Length()) {
+		t.Errorf("After three periods: %v, %v, expected %v, %v", full, fullVelocity, position, velocity)
+	}
+
+	// Hyperbolic: r = a (1 - e cosh H), with e sinh H - H = √(μ / -a³) t
+	hyperbola := orbit.Elements{SemiMajorAxis: -q, Eccentricity: 2}
+	position, velocity = hyperbola.StateVectors(mu)
+	h := 1.5
+	dt := (hyperbola.Eccentricity*math.Sinh(h) - h) / math.Sqrt(mu/(q*q*q))
+	later, laterVelocity := orbit.Propagate(position, velocity, mu, dt)
+	if expected := -q * (1 - 2*math.Cosh(h)); math.Abs(later.Length()-expected) > 1e-9*expected {
+		t.Errorf("Hyperbolic distance %v m, expected %v m", later.Length(), expected)
+	}
+	back, _ := orbit.Propagate(later, laterVelocity, mu, -dt)
+	if !vectorsAlmostEqual(back, position, 1e-9*q) {
+		t.Errorf("Propagating back gave %v, expected %v", back, position)
+	}
+
+	// Parabolic (Barker's equation): t = √(2 q³ / μ) (D + D³ / 3) with D = tan(ν / 2), r = q (1 + D²)
+	position = vector.NewVector3(q, 0, 0)
+	velocity = vector.NewVector3(0, math.Sqrt(2*mu/q), 0)
+	d := math.Tan(0.5)
+	dt = math.Sqrt(2*q*q*q/mu) * (d + d*d*d/3)
+	later, _ = orbit.Propagate(position, velocity, mu, dt)
+	if expected := q * (1 + d*d); math.Abs(later.Length()-expected) > 1e-9*expected || math.Abs(math.Atan2(later.Y(), later.X())-1) > 1e-9 {
+		t.Errorf("Parabolic position %v, expected %v m at 1 rad", later, expected)
+	}
+}
+
+// TestBodiesOnRails verifies that bodies on rails follow their analytic orbits, nested around each other,
+// regardless of the forces acting on them
+func TestBodiesOnRails(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e12, -1e12, -1e12), vector.NewVector3(1e12, 1e12, 1e12)))
+	w.AddForce(force.NewGravitationalForce())
+	sun := createSun()
+	w.AddBody(sun)
+
+	planetOrbit := orbit.Elements{SemiMajorAxis: constants.AstronomicalUnit, Eccentricity: 0.3, Inclination: 0.1}
+	planet := orbit.NewBodyOnOrbit(sun, planetOrbit, units.NewQuantity(constants.EarthMass, units.Kilogram), units.NewQuantity(6.371e6, units.Meter), material.Rock)
+	w.AddBody(planet)
+	moon := orbit.NewBodyOnOrbit(planet, orbit.Circular(3.844e8, 0, 0, 0), units.NewQuantity(7.342e22, units.Kilogram), units.NewQuantity(1.737e6, units.Meter), material.Rock)
+	w.AddBody(moon)
+
+	w.PutOnRails(planet.ID(), sun.ID())
+	w.PutOnRails(moon.ID(), planet.ID())
+
+	// A force on the planet does not change its orbit
+	w.AttachForce(force.NewConstantForce(vector.NewVector3(1e30, 0, 0)), planet.ID())
+
+	start := planet.Position()
+	startVelocity := planet.Velocity()
+	mu := orbit.GravitationalParameter(sun, planet)
+	dt := 3600.0
+	steps := 24 * 100
+	for i := 0; i < steps; i++ {
+		w.Step(dt)
+	}
+
+	expected, _ := orbit.Propagate(start, startVelocity, mu, dt*float64(steps))
+	if drift := planet.Position().Sub(expected).Length(); drift > 1 {
+		t.Errorf("The planet is %v m off its analytic orbit", drift)
+	}
+	if distance := moon.Position().Sub(planet.Position()).Length(); math.Abs(distance-3.844e8) > 1 {
+		t.Errorf("The moon is %v m from the planet, expected 3.844e8 m", distance)
+	}
+
+	// Removing the planet takes the moon off rails, the planet can be taken off rails too
+	w.TakeOffRails(planet.ID())
+	if w.IsOnRails(planet.ID()) || !w.IsOnRails(moon.ID()) {
+		t.Errorf("Only the planet should be off rails")
+	}
+	w.RemoveBody(planet.ID())
+	if w.IsOnRails(moon.ID()) {
+		t.Errorf("The moon is still on rails around a removed planet")
+	}
+}
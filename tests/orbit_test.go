@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// angleDifference returns the difference between two angles, between -π and π
+func angleDifference(a, b float64) float64 {
+	return math.Remainder(a-b, 2*math.Pi)
+}
+
+// TestOrbitalElementsRoundTrip verifies the conversion of orbital elements to state vectors and back
+func TestOrbitalElementsRoundTrip(t *testing.T) {
+	mu := constants.G * constants.SolarMass
+	cases := map[string]orbit.Elements{
+		"Elliptic inclined":   {SemiMajorAxis: 1.5e11, Eccentricity: 0.3, Inclination: 0.4, LongitudeOfAscendingNode: 1.2, ArgumentOfPeriapsis: 2.5, TrueAnomaly: 4},
+		"Retrograde":          {SemiMajorAxis: 7e10, Eccentricity: 0.1, Inclination: 2.8, LongitudeOfAscendingNode: 5, ArgumentOfPeriapsis: 0.3, TrueAnomaly: 1},
+		"Hyperbolic":          {SemiMajorAxis: -2e11, Eccentricity: 1.5, Inclination: 1, LongitudeOfAscendingNode: 0.5, ArgumentOfPeriapsis: 1, TrueAnomaly: 0.5},
+		"Circular inclined":   {SemiMajorAxis: 1e11, Inclination: 0.7, LongitudeOfAscendingNode: 2, TrueAnomaly: 3},
+		"Equatorial elliptic": {SemiMajorAxis: 1e11, Eccentricity: 0.5, ArgumentOfPeriapsis: 1.5, TrueAnomaly: 2},
+	}
+
+	for name, elements := range cases {
+		position, velocity := elements.StateVectors(mu)
+		got := orbit.FromStateVectors(position, velocity, mu)
+
+		if math.Abs(got.SemiMajorAxis-elements.SemiMajorAxis) > 1e-6*math.Abs(elements.SemiMajorAxis) ||
+			math.Abs(got.Eccentricity-elements.Eccentricity) > 1e-9 ||
+			math.Abs(got.Inclination-elements.Inclination) > 1e-9 ||
+			math.Abs(angleDifference(got.LongitudeOfAscendingNode, elements.LongitudeOfAscendingNode)) > 1e-9 ||
+			math.Abs(angleDifference(got.ArgumentOfPeriapsis, elements.ArgumentOfPeriapsis)) > 1e-6 ||
+			math.Abs(angleDifference(got.TrueAnomaly, elements.TrueAnomaly)) > 1e-6 {
+			t.Errorf("%s: got %+v, expected %+v", name, got, elements)
+		}
+	}
+
+	// The periapsis of an orbit is on the eccentricity vector, at a (1 - e)
+	periapsis := orbit.Elements{SemiMajorAxis: 1e11, Eccentricity: 0.2}
+	position, velocity := periapsis.StateVectors(mu)
+	if !vectorsAlmostEqual(position, vector.NewVector3(0.8e11, 0, 0), 1) || math.Abs(velocity.X()) > 1e-9 || velocity.Y() <= 0 {
+		t.Errorf("Periapsis at %v with velocity %v", position, velocity)
+	}
+}
+
+// TestBodyOnOrbit verifies that a body placed on an eccentric inclined orbit follows it under gravity
+func TestBodyOnOrbit(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e12, -1e12, -1e12), vector.NewVector3(1e12, 1e12, 1e12)))
+	w.AddForce(force.NewGravitationalForce())
+	sun := createSun()
+	w.AddBody(sun)
+
+	elements := orbit.Elements{
+		SemiMajorAxis:            constants.AstronomicalUnit,
+		Eccentricity:             0.2,
+		Inclination:              0.3,
+		LongitudeOfAscendingNode: 1,
+		ArgumentOfPeriapsis:      2,
+	}
+	planet := orbit.NewBodyOnOrbit(sun, elements, units.NewQuantity(constants.EarthMass, units.Kilogram), units.NewQuantity(6.371e6, units.Meter), material.Rock)
+	w.AddBody(planet)
+
+	// Starting at the periapsis
+	if distance := planet.Position().Length(); math.Abs(distance-elements.Periapsis()) > 1 {
+		t.Errorf("Distance %v m, expected the periapsis %v m", distance, elements.Periapsis())
+	}
+
+	// After one period the planet is back at the periapsis, on the same orbit
+	period := elements.Period(orbit.GravitationalParameter(sun, planet))
+	steps := 20000
+	start := planet.Position()
+	for i := 0; i < steps; i++ {
+		w.Step(period / float64(steps))
+	}
+	if drift := planet.Position().Sub(start).Length(); drift > 1e-3*constants.AstronomicalUnit {
+		t.Errorf("The planet drifted by %v m from its periapsis after one period", drift)
+	}
+
+	got := orbit.ElementsOf(planet, sun)
+	if math.Abs(got.SemiMajorAxis-elements.SemiMajorAxis) > 1e-4*constants.AstronomicalUnit ||
+		math.Abs(got.Eccentricity-elements.Eccentricity) > 1e-4 ||
+		math.Abs(got.Inclination-elements.Inclination) > 1e-9 ||
+		math.Abs(angleDifference(got.LongitudeOfAscendingNode, elements.LongitudeOfAscendingNode)) > 1e-9 {
+		t.Errorf("Elements after one period %+v, expected %+v", got, elements)
+	}
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/collision"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// createLattice creates a uniform cubic lattice of n^3 equal bodies filling the bounds
+func createLattice(n int, bounds *space.AABB) []body.Body {
+	size := bounds.Size().X()
+	spacing := size / float64(n)
+	bodies := make([]body.Body, 0, n*n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			for k := 0; k < n; k++ {
+				position := bounds.Min.Add(vector.NewVector3(
+					(float64(i)+0.5)*spacing,
+					(float64(j)+0.5)*spacing,
+					(float64(k)+0.5)*spacing,
+				))
+				bodies = append(bodies, body.NewRigidBody(
+					units.NewQuantity(1e6, units.Kilogram),
+					units.NewQuantity(0.1, units.Meter),
+					position,
+					vector.Zero3(),
+					material.Rock,
+				))
+			}
+		}
+	}
+	return bodies
+}
+
+// TestPeriodicUniformBoxIsForceFree verifies that a uniform lattice feels no net force with periodic boundaries
+func TestPeriodicUniformBoxIsForceFree(t *testing.T) {
+	bounds := space.NewAABB(vector.NewVector3(-10, -10, -10), vector.NewVector3(10, 10, 10))
+	bodies := createLattice(5, bounds)
+	separation := bounds.MinimumImageFunc(space.Periodicity{true, true, true})
+
+	// Reference: force between two nearest neighbours
+	spacing := bounds.Size().X() / 5
+	reference := constants.G * 1e6 * 1e6 / (spacing * spacing)
+
+	// Without periodic boundaries the corner bodies are pulled toward the center
+	open := force.DirectGravity(bodies, constants.G, true, nil)
+	if open[0].Length() < reference {
+		t.Errorf("Expected a net force on the corner body of an open box, got %v", open[0].Length())
+	}
+
+	solvers := map[string][]vector.Vector3{
+		"direct": force.DirectGravity(bodies, constants.G, true, separation),
+		"fmm":    force.FMMGravity(bodies, constants.G, 0.3, separation),
+	}
+	// Tolerances relative to the net force on the corner body of the open box
+	tolerances := map[string]float64{"direct": 1e-9, "fmm": 5e-2}
+
+	for name, forces := range solvers {
+		maxForce := 0.0
+		for _, f := range forces {
+			maxForce = math.Max(maxForce, f.Length())
+		}
+		if maxForce > tolerances[name]*open[0].Length() {
+			t.Errorf("%s solver: uniform periodic box is not force free (max force %v, open box %v)", name, maxForce, open[0].Length())
+		}
+	}
+}
+
+// TestPeriodicWrapAround verifies that bodies leaving through a periodic boundary re-enter on the opposite side
+func TestPeriodicWrapAround(t *testing.T) {
+	bounds := space.NewAABB(vector.NewVector3(-10, -10, -10), vector.NewVector3(10, 10, 10))
+	w := world.NewPhysicalWorld(bounds)
+	w.SetPeriodicity(space.Periodicity{true, false, false})
+
+	b := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.NewVector3(9, 0, 0),
+		vector.NewVector3(10, 0, 0),
+		material.Rock,
+	)
+	w.AddBody(b)
+
+	// After 0.2 s the body has travelled 2 m: from x=9 to x=11, i.e. x=-9 after wrapping
+	for i := 0; i < 20; i++ {
+		w.Step(0.01)
+	}
+
+	if math.Abs(b.Position().X()-(-9)) > 1e-6 {
+		t.Errorf("Body did not wrap around: expected x=-9, got x=%v", b.Position().X())
+	}
+	if math.Abs(b.Velocity().X()-10) > 1e-6 {
+		t.Errorf("Velocity changed while crossing a periodic boundary: %v", b.Velocity())
+	}
+
+	// The body must still be found by spatial queries after wrapping
+	found := w.GetSpatialStructure().QuerySphere(vector.NewVector3(-9, 0, 0), 1)
+	if len(found) != 1 {
+		t.Errorf("Expected the wrapped body to be found by the octree, got %d bodies", len(found))
+	}
+}
+
+// TestPeriodicCollisionAcrossSeam verifies that collisions are detected across a periodic boundary
+func TestPeriodicCollisionAcrossSeam(t *testing.T) {
+	bounds := space.NewAABB(vector.NewVector3(-10, -10, -10), vector.NewVector3(10, 10, 10))
+	w := world.NewPhysicalWorld(bounds)
+	w.SetCollisionResolver(collision.NewImpulseResolver(1.0))
+	w.SetPeriodicity(space.Periodicity{true, true, true})
+
+	// Two bodies on opposite sides of the X boundary, moving toward the seam
+	left := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.NewVector3(-9.6, 0, 0),
+		vector.NewVector3(-1, 0, 0),
+		material.Rock,
+	)
+	right := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.NewVector3(9.6, 0, 0),
+		vector.NewVector3(1, 0, 0),
+		material.Rock,
+	)
+	w.AddBody(left)
+	w.AddBody(right)
+
+	// The octree finds the neighbour across the seam
+	found := w.GetSpatialStructure().QuerySphere(left.Position(), 1.0)
+	if len(found) != 2 {
+		t.Fatalf("Expected 2 bodies near the seam, got %d", len(found))
+	}
+
+	// The collider measures the distance across the seam (0.8 m < 1.0 m)
+	info := w.GetCollider().CheckCollision(left, right)
+	if !info.HasCollided {
+		t.Fatalf("Collision across the periodic boundary was not detected")
+	}
+	if info.Normal.X() > 0 {
+		t.Errorf("Collision normal should point from the left body across the seam, got %v", info.Normal)
+	}
+
+	// After a step the bodies bounce back
+	w.Step(0.01)
+	if left.Velocity().X() <= 0 || right.Velocity().X() >= 0 {
+		t.Errorf("Bodies did not bounce across the seam: left %v, right %v", left.Velocity(), right.Velocity())
+	}
+}
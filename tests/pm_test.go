@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// createPair creates two equal bodies separated along the X axis (slightly off the grid points)
+func createPair(separation float64) (body.Body, body.Body) {
+	a := body.NewRigidBody(
+		units.NewQuantity(1e6, units.Kilogram),
+		units.NewQuantity(0.1, units.Meter),
+		vector.NewVector3(-separation/2+0.3, 0.2, 0.1),
+		vector.Zero3(),
+		material.Rock,
+	)
+	b := body.NewRigidBody(
+		units.NewQuantity(1e6, units.Kilogram),
+		units.NewQuantity(0.1, units.Meter),
+		vector.NewVector3(separation/2+0.3, 0.2, 0.1),
+		vector.Zero3(),
+		material.Rock,
+	)
+	return a, b
+}
+
+// TestPMGravityPairForce verifies the PM force between two bodies a few grid cells apart
+func TestPMGravityPairForce(t *testing.T) {
+	bounds := space.NewAABB(vector.NewVector3(-50, -50, -50), vector.NewVector3(50, 50, 50))
+	a, b := createPair(10)
+	newton := constants.G * 1e6 * 1e6 / 100
+
+	for _, assignment := range []force.MassAssignment{force.CloudInCell, force.TriangularShapedCloud} {
+		forces := force.PMGravity([]body.Body{a, b}, constants.G, bounds, 64, assignment, 0)
+
+		// The force is attractive and close to the Newtonian one (6.4 cells apart)
+		if math.Abs(forces[0].X()/newton-1) > 0.05 {
+			t.Errorf("%s: PM force %v differs from the Newtonian force %v", assignment, forces[0].X(), newton)
+		}
+
+		// The same assignment is used to deposit and interpolate, so momentum is conserved
+		if forces[0].Add(forces[1]).Length() > 1e-10*newton {
+			t.Errorf("%s: PM forces are not equal and opposite: %v, %v", assignment, forces[0], forces[1])
+		}
+	}
+}
+
+// TestTreePMAccuracy verifies that the long-range PM force plus the short-range tree correction matches Newton
+func TestTreePMAccuracy(t *testing.T) {
+	bounds := space.NewAABB(vector.NewVector3(-50, -50, -50), vector.NewVector3(50, 50, 50))
+	splitRadius := force.PMSplitRadius(bounds, 64, 1.25)
+	kernel := func(distance float64) float64 {
+		return force.ShortRangeFactor(distance, splitRadius)
+	}
+
+	// From well below a grid cell (where the PM force alone vanishes) to several cells
+	for _, separation := range []float64{0.5, 1, 2, 5, 10} {
+		a, b := createPair(separation)
+		newton := constants.G * 1e6 * 1e6 / (separation * separation)
+
+		octree := space.NewOctree(bounds, 10, 8)
+		octree.Insert(a)
+		octree.Insert(b)
+
+		longRange := force.PMGravity([]body.Body{a, b}, constants.G, bounds, 64, force.CloudInCell, splitRadius)
+		shortRange := octree.CalculateShortRangeGravity(a, 0.5, constants.G, force.ShortRangeCutoff(splitRadius), kernel)
+		total := longRange[0].Add(shortRange).X()
+
+		if math.Abs(total/newton-1) > 0.02 {
+			t.Errorf("Separation %v: TreePM force %v differs from the Newtonian force %v", separation, total, newton)
+		}
+	}
+}
+
+// TestPMUniformBoxIsForceFree verifies that a uniform lattice feels no net force with the PM solver
+func TestPMUniformBoxIsForceFree(t *testing.T) {
+	bounds := space.NewAABB(vector.NewVector3(-10, -10, -10), vector.NewVector3(10, 10, 10))
+	bodies := createLattice(4, bounds)
+	open := force.DirectGravity(bodies, constants.G, true, nil)
+
+	forces := force.PMGravity(bodies, constants.G, bounds, 32, force.CloudInCell, 0)
+	for i, f := range forces {
+		if f.Length() > 1e-9*open[0].Length() {
+			t.Fatalf("Body %d: uniform periodic box is not force free with PM (force %v)", i, f)
+		}
+	}
+}
+
+// TestPMSolverSelection verifies that the world applies the PM and TreePM solvers
+func TestPMSolverSelection(t *testing.T) {
+	for _, solver := range []force.GravitySolver{force.PMSolver, force.TreePMSolver} {
+		w := world.NewPhysicalWorld(space.NewAABB(
+			vector.NewVector3(-50, -50, -50),
+			vector.NewVector3(50, 50, 50),
+		))
+		gravityForce := force.NewGravitationalForce()
+		gravityForce.SetSolver(solver)
+		gravityForce.SetGridSize(32)
+		w.AddForce(gravityForce)
+
+		a, b := createPair(10)
+		w.AddBody(a)
+		w.AddBody(b)
+		for i := 0; i < 10; i++ {
+			w.Step(1.0)
+		}
+
+		if a.Velocity().X() <= 0 || b.Velocity().X() >= 0 {
+			t.Errorf("%s: bodies do not attract each other: %v, %v", solver, a.Velocity(), b.Velocity())
+		}
+		if b.Position().Sub(a.Position()).Length() >= 10 {
+			t.Errorf("%s: bodies did not get closer", solver)
+		}
+	}
+}
+
+// TestPMGridSize verifies that the PM grid size is rounded up to a power of two instead of panicking during a step
+func TestPMGridSize(t *testing.T) {
+	gravityForce := force.NewGravitationalForce()
+	for _, size := range [][2]int{{0, 2}, {2, 2}, {33, 64}, {64, 64}} {
+		gravityForce.SetGridSize(size[0])
+		if gravityForce.GetGridSize() != size[1] {
+			t.Errorf("Grid size %d set to %d, expected %d", size[0], gravityForce.GetGridSize(), size[1])
+		}
+	}
+
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-50, -50, -50), vector.NewVector3(50, 50, 50)))
+	gravityForce.SetSolver(force.PMSolver)
+	gravityForce.GridSize = 20
+	w.AddForce(gravityForce)
+	a, b := createPair(10)
+	w.AddBody(a)
+	w.AddBody(b)
+	for i := 0; i < 10; i++ {
+		w.Step(1.0)
+	}
+	if a.Velocity().X() <= 0 {
+		t.Errorf("Body not attracted with a grid of %d cells", gravityForce.GridSize)
+	}
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// TestRocketEquation verifies that a full burn consumes the propellant at F / (Isp g0)
+// and gives the delta-v of the Tsiolkovsky rocket equation
+func TestRocketEquation(t *testing.T) {
+	// The rocket travels a few hundred kilometers
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e7, -1e7, -1e7), vector.NewVector3(1e7, 1e7, 1e7)))
+	rocket := body.NewRigidBody(
+		units.NewQuantity(1000, units.Kilogram),
+		units.NewQuantity(1, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Iron,
+	)
+	w.AddBody(rocket)
+	engine := body.NewEngine(
+		units.NewQuantity(1000, units.Newton),
+		units.NewQuantity(300, units.Second),
+		units.NewQuantity(600, units.Kilogram),
+	)
+
+	// Δv = Isp g0 ln(m0 / mf)
+	exhaustVelocity := 300 * constants.DefaultGravity
+	expected := exhaustVelocity * math.Log(1000.0/400.0)
+	if deltaV := engine.DeltaV(rocket.Mass()).Value(); math.Abs(deltaV-expected) > 1e-9 {
+		t.Errorf("Available delta-v %v m/s, expected %v m/s", deltaV, expected)
+	}
+
+	dt := 0.1
+	burnTime := 0.0
+	for !engine.IsEmpty() {
+		if engine.Fire(rocket, vector.NewVector3(1, 0, 0), 1, dt) > 0 {
+			burnTime += dt
+		}
+		w.Step(dt)
+	}
+	w.Step(dt)
+
+	// The engine stops when the tank is empty, leaving the dry mass
+	if mass := rocket.Mass().Value(); math.Abs(mass-400) > 1e-6 {
+		t.Errorf("Dry mass %v kg, expected 400 kg", mass)
+	}
+	if expectedTime := 600 / (1000 / exhaustVelocity); math.Abs(burnTime-expectedTime) > dt {
+		t.Errorf("Burn time %v s, expected %v s", burnTime, expectedTime)
+	}
+	if speed := rocket.Velocity().X(); math.Abs(speed-expected) > 1e-3*expected {
+		t.Errorf("Final speed %v m/s, expected %v m/s", speed, expected)
+	}
+	if engine.Fire(rocket, vector.NewVector3(1, 0, 0), 1, dt) != 0 || engine.DeltaV(rocket.Mass()).Value() != 0 {
+		t.Errorf("The empty engine still fired")
+	}
+}
+
+// TestControllableBodyBurnsFuel verifies that a controllable body with an engine consumes propellant when thrusting
+func TestControllableBodyBurnsFuel(t *testing.T) {
+	rb := body.NewRigidBody(
+		units.NewQuantity(500, units.Kilogram),
+		units.NewQuantity(1, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Iron,
+	)
+	spacecraft := body.NewControllableRigidBody(rb, 1000, 1)
+	if spacecraft.Fuel().Value() != 0 || spacecraft.DeltaV().Value() != 0 {
+		t.Errorf("A body without engine has no propellant")
+	}
+
+	spacecraft.SetEngine(body.NewEngine(
+		units.NewQuantity(2000, units.Newton),
+		units.NewQuantity(200, units.Second),
+		units.NewQuantity(100, units.Kilogram),
+	))
+	deltaV := spacecraft.DeltaV().Value()
+
+	// Thrusting burns propellant and lowers the mass, coasting does not
+	spacecraft.HandleInput(1, false, false, false, false, false, false, false, false, false, false)
+	if spacecraft.Fuel().Value() != 100 {
+		t.Errorf("Propellant burned without thrust: %v kg left", spacecraft.Fuel().Value())
+	}
+	spacecraft.HandleInput(1, true, false, false, false, false, false, false, false, false, false)
+	burned := 2000 / (200 * constants.DefaultGravity)
+	if math.Abs(spacecraft.Fuel().Value()-(100-burned)) > 1e-9 || math.Abs(spacecraft.Mass().Value()-(500-burned)) > 1e-9 {
+		t.Errorf("Fuel %v kg, mass %v kg after one second of thrust", spacecraft.Fuel().Value(), spacecraft.Mass().Value())
+	}
+	if spacecraft.DeltaV().Value() >= deltaV || spacecraft.Acceleration().Length() == 0 {
+		t.Errorf("The burn did not use delta-v or accelerate the body")
+	}
+}
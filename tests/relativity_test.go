@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/integrator"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// Orbital parameters of Mercury
+const (
+	mercurySemiMajorAxis = 5.7909e10       // m
+	mercuryEccentricity  = 0.2056          // dimensionless
+	mercuryMass          = 3.3011e23       // kg
+	mercuryPeriod        = 87.9691 * 86400 // s
+)
+
+// perihelionAngles simulates Mercury around a static Sun and returns the direction (angle in the
+// orbital plane) of each perihelion passage, interpolated between the steps that bracket it
+func perihelionAngles(relativistic bool, passages int, dt float64) []float64 {
+	bound := 1e11
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-bound, -bound, -bound),
+		vector.NewVector3(bound, bound, bound),
+	))
+
+	gravityForce := force.NewGravitationalForce()
+	gravityForce.SetSolver(force.DirectSolver)
+	w.AddForce(gravityForce)
+	if relativistic {
+		w.AddForce(force.NewPostNewtonianForce())
+	}
+
+	sun := body.NewRigidBody(
+		units.NewQuantity(constants.SolarMass, units.Kilogram),
+		units.NewQuantity(6.9634e8, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Rock,
+	)
+	sun.SetStatic(true)
+
+	// Start at perihelion on the X axis
+	perihelion := mercurySemiMajorAxis * (1 - mercuryEccentricity)
+	speed := math.Sqrt(constants.G * constants.SolarMass * (1 + mercuryEccentricity) / perihelion)
+	mercury := body.NewRigidBody(
+		units.NewQuantity(mercuryMass, units.Kilogram),
+		units.NewQuantity(2.4397e6, units.Meter),
+		vector.NewVector3(perihelion, 0, 0),
+		vector.NewVector3(0, speed, 0),
+		material.Rock,
+	)
+
+	w.AddBody(sun)
+	w.AddBody(mercury)
+
+	angles := make([]float64, 0, passages)
+	previousRadialVelocity := 0.0
+	previousAngle := 0.0
+	for len(angles) < passages {
+		w.Step(dt)
+
+		position := mercury.Position()
+		radialVelocity := position.Dot(mercury.Velocity())
+		angle := math.Atan2(position.Y(), position.X())
+
+		// Perihelion: the radial velocity changes sign from negative to positive
+		if previousRadialVelocity < 0 && radialVelocity >= 0 {
+			fraction := previousRadialVelocity / (previousRadialVelocity - radialVelocity)
+			step := math.Remainder(angle-previousAngle, 2*math.Pi)
+			angles = append(angles, previousAngle+fraction*step)
+		}
+
+		previousRadialVelocity = radialVelocity
+		previousAngle = angle
+	}
+	return angles
+}
+
+// TestMercuryPerihelionPrecession verifies that the 1PN correction reproduces the ~43 arcsec/century
+// relativistic precession of Mercury's perihelion
+func TestMercuryPerihelionPrecession(t *testing.T) {
+	const passages = 2
+	const dt = 500.0
+
+	newtonian := perihelionAngles(false, passages, dt)
+	relativistic := perihelionAngles(true, passages, dt)
+
+	// Remove the numerical precession of the integrator by comparing with the Newtonian run
+	shift := math.Remainder(relativistic[passages-1]-newtonian[passages-1], 2*math.Pi)
+	perOrbit := shift / passages
+
+	arcsecondsPerRadian := 180.0 / math.Pi * 3600.0
+	orbitsPerCentury := 36525.0 * 86400.0 / mercuryPeriod
+	perCentury := perOrbit * arcsecondsPerRadian * orbitsPerCentury
+
+	// Analytical value: 6πGM / (c² a (1 - e²)) per orbit
+	expected := 6 * math.Pi * constants.G * constants.SolarMass /
+		(constants.SpeedOfLight * constants.SpeedOfLight * mercurySemiMajorAxis * (1 - mercuryEccentricity*mercuryEccentricity)) *
+		arcsecondsPerRadian * orbitsPerCentury
+
+	t.Logf("Perihelion precession: %.2f arcsec/century (expected %.2f)", perCentury, expected)
+
+	if math.Abs(expected-43) > 0.5 {
+		t.Errorf("Unexpected analytical precession: %v arcsec/century", expected)
+	}
+	if math.Abs(perCentury-expected) > 0.1*expected {
+		t.Errorf("Perihelion precession %v arcsec/century differs from the expected %v", perCentury, expected)
+	}
+}
+
+// TestRelativisticSpeedLimit verifies that a constant force increases the momentum linearly
+// while the speed stays below the speed of light
+func TestRelativisticSpeedLimit(t *testing.T) {
+	b := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(1.0, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Rock,
+	)
+	relativistic := integrator.NewRelativisticIntegrator()
+
+	// A force of c newtons gives the body a momentum of 10mc after 10 s
+	thrust := vector.NewVector3(constants.SpeedOfLight, 0, 0)
+	dt := 0.01
+	for i := 0; i < 1000; i++ {
+		b.ApplyForce(thrust)
+		relativistic.Integrate(b, dt)
+
+		if b.Velocity().Length() >= constants.SpeedOfLight {
+			t.Fatalf("Speed of light exceeded: %v", b.Velocity().Length())
+		}
+	}
+
+	momentum := body.RelativisticMomentum(b).X()
+	expected := 10 * constants.SpeedOfLight
+	if math.Abs(momentum-expected) > 1e-9*expected {
+		t.Errorf("Momentum %v differs from the impulse %v", momentum, expected)
+	}
+
+	// v = p / sqrt(m² + p²/c²) = c * 10 / sqrt(101)
+	expectedSpeed := constants.SpeedOfLight * 10 / math.Sqrt(101)
+	if math.Abs(b.Velocity().X()-expectedSpeed) > 1e-9*expectedSpeed {
+		t.Errorf("Speed %v differs from the expected %v", b.Velocity().X(), expectedSpeed)
+	}
+}
+
+// TestProperTimeDilation verifies that moving bodies accumulate less proper time than the world coordinate time
+func TestProperTimeDilation(t *testing.T) {
+	bound := 1e10
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-bound, -bound, -bound),
+		vector.NewVector3(bound, bound, bound),
+	))
+	w.SetIntegrator(integrator.NewRelativisticIntegrator())
+
+	// A body at rest and a body moving at 0.8c (γ = 5/3)
+	rest := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(1.0, units.Meter),
+		vector.NewVector3(0, 1e6, 0),
+		vector.Zero3(),
+		material.Rock,
+	)
+	moving := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(1.0, units.Meter),
+		vector.Zero3(),
+		vector.NewVector3(0.8*constants.SpeedOfLight, 0, 0),
+		material.Rock,
+	)
+	w.AddBody(rest)
+	w.AddBody(moving)
+
+	for i := 0; i < 100; i++ {
+		w.Step(0.1)
+	}
+
+	if math.Abs(w.GetTime()-10) > 1e-9 {
+		t.Errorf("Unexpected world time: %v", w.GetTime())
+	}
+	if math.Abs(rest.ProperTime().Value()-10) > 1e-9 {
+		t.Errorf("Proper time of the body at rest %v differs from the coordinate time", rest.ProperTime().Value())
+	}
+	if math.Abs(moving.ProperTime().Value()-6) > 1e-9 {
+		t.Errorf("Proper time of the moving body %v, expected 6", moving.ProperTime().Value())
+	}
+	if math.Abs(moving.Position().X()-0.8*constants.SpeedOfLight*10) > 1e-3 {
+		t.Errorf("Moving body position %v, expected %v", moving.Position().X(), 0.8*constants.SpeedOfLight*10)
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// TestPatchedConics verifies the spheres of influence, the transitions between them and the patched-conic motion
+// of a probe escaping from the Earth
+func TestPatchedConics(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e12, -1e12, -1e12), vector.NewVector3(1e12, 1e12, 1e12)))
+	w.AddForce(force.NewGravitationalForce())
+	sun := createSun()
+	w.AddBody(sun)
+	earth := orbit.NewBodyOnOrbit(sun, orbit.Circular(constants.AstronomicalUnit, 0, 0, 0), units.NewQuantity(constants.EarthMass, units.Kilogram), units.NewQuantity(6.371e6, units.Meter), material.Rock)
+	w.AddBody(earth)
+
+	// The Laplace radius of the Earth is about 925000 km
+	if radius := orbit.SphereOfInfluence(earth, sun); math.Abs(radius-9.25e8) > 0.01*9.25e8 {
+		t.Errorf("Sphere of influence of the Earth %v m, expected about 9.25e8 m", radius)
+	}
+
+	// A probe on a hyperbolic escape trajectory from a low orbit
+	mu := constants.G * constants.EarthMass
+	periapsis := 7e6
+	speed := 1.2 * math.Sqrt(2*mu/periapsis)
+	probe := body.NewRigidBody(
+		units.NewQuantity(1000, units.Kilogram),
+		units.NewQuantity(2, units.Meter),
+		earth.Position().Add(vector.NewVector3(0, 0, periapsis)),
+		earth.Velocity().Add(vector.NewVector3(0, speed, 0)),
+		material.Iron,
+	)
+	w.AddBody(probe)
+
+	tracker := orbit.NewSOITracker()
+	tracker.AddBody(sun, nil)
+	tracker.AddBody(earth, sun)
+	tracker.Track(probe)
+	tracker.SetPatched(true)
+	w.SetSOITracker(tracker)
+
+	dt := 5.0
+	w.Step(dt)
+	if tracker.Dominant(probe.ID()) != earth || len(w.GetSOITransitions()) != 0 {
+		t.Fatalf("The probe should start in the sphere of influence of the Earth")
+	}
+	if !math.IsInf(tracker.Radius(sun.ID()), 1) {
+		t.Errorf("The sphere of influence of the root should be infinite")
+	}
+
+	// Inside the sphere of influence the probe follows a conic around the Earth from its initial state
+	steps := int(86400 / dt)
+	for i := 0; i < steps; i++ {
+		w.Step(dt)
+	}
+	expected, _ := orbit.Propagate(vector.NewVector3(0, 0, periapsis), vector.NewVector3(0, speed, 0), mu, float64(steps+1)*dt)
+	position, _ := tracker.RelativeState(probe.ID())
+	if drift := position.Sub(expected).Length(); drift > 1e-4*expected.Length() {
+		t.Errorf("The probe is %v m off its conic around the Earth", drift)
+	}
+
+	// Leaving the sphere of influence switches to the frame of the Sun, at the start of a step
+	var transition *orbit.Transition
+	var before vector.Vector3
+	for i := 0; i < 5*steps && transition == nil; i++ {
+		before = probe.Position().Sub(sun.Position())
+		w.Step(dt)
+		if transitions := w.GetSOITransitions(); len(transitions) > 0 {
+			transition = &transitions[0]
+		}
+	}
+	if transition == nil {
+		t.Fatalf("No transition after %v m from the Earth", probe.Position().Sub(earth.Position()).Length())
+	}
+	if transition.Body != probe || transition.From != earth || transition.To != sun || tracker.Dominant(probe.ID()) != sun {
+		t.Errorf("Unexpected transition %+v", transition)
+	}
+	if distance := probe.Position().Sub(earth.Position()).Length(); math.Abs(distance-tracker.Radius(earth.ID())) > 1e-3*distance {
+		t.Errorf("Transition at %v m from the Earth, expected %v m", distance, tracker.Radius(earth.ID()))
+	}
+	if !vectorsAlmostEqual(transition.Position, before, 1e-6) {
+		t.Errorf("Transition position %v, expected the position relative to the Sun", transition.Position)
+	}
+
+	// Removing the probe from the world stops tracking it
+	w.RemoveBody(probe.ID())
+	if tracker.IsTracked(probe.ID()) {
+		t.Errorf("The removed probe is still tracked")
+	}
+}
+
+// TestPatchedConicsWithoutDominantBody verifies that a tracked body outside any sphere of influence keeps the
+// gravity of all the bodies
+func TestPatchedConicsWithoutDominantBody(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e8, -1e8, -1e8), vector.NewVector3(1e8, 1e8, 1e8)))
+	w.AddForce(force.NewGravitationalForce())
+	earth := createEarth()
+	w.AddBody(earth)
+	probe := body.NewRigidBody(units.NewQuantity(1000, units.Kilogram), units.NewQuantity(2, units.Meter), vector.NewVector3(1e7, 0, 0), vector.Zero3(), material.Iron)
+	w.AddBody(probe)
+
+	// No hierarchy: the probe has no dominant body
+	tracker := orbit.NewSOITracker()
+	tracker.Track(probe)
+	tracker.SetPatched(true)
+	w.SetSOITracker(tracker)
+
+	for i := 0; i < 10; i++ {
+		w.Step(1)
+	}
+	if tracker.Dominant(probe.ID()) != nil || probe.Velocity().X() >= 0 {
+		t.Errorf("The probe should fall toward the Earth, velocity %v", probe.Velocity())
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/force"
+)
+
+// debrisCategory is the collision category of the debris in the tests
+const debrisCategory uint32 = 2
+
+// debrisFilter makes debris ignore each other while still colliding with everything else
+var debrisFilter = body.CollisionFilter{
+	Category: debrisCategory,
+	Mask:     body.AllCollisionCategories &^ debrisCategory,
+}
+
+// TestDebrisIgnoreEachOther verifies that the collision masks are honored by the world
+func TestDebrisIgnoreEachOther(t *testing.T) {
+	w, bodies := createTestWorld(
+		vector.NewVector3(-20, 0, 0),
+		vector.NewVector3(-19.2, 0, 0),
+		vector.NewVector3(20, 0, 0),
+		vector.NewVector3(20.8, 0, 0),
+	)
+	debrisA, debrisB, debrisC, planet := bodies[0], bodies[1], bodies[2], bodies[3]
+	for _, debris := range []body.Body{debrisA, debrisB, debrisC} {
+		debris.AddTag("debris")
+		debris.SetCollisionFilter(debrisFilter)
+	}
+	planet.AddTag("planet")
+	planet.SetStatic(true)
+
+	// Two overlapping debris moving toward each other, and a debris moving toward the planet
+	debrisA.SetVelocity(vector.NewVector3(1, 0, 0))
+	debrisB.SetVelocity(vector.NewVector3(-1, 0, 0))
+	debrisC.SetVelocity(vector.NewVector3(1, 0, 0))
+	w.Step(0.01)
+
+	if math.Abs(debrisA.Velocity().X()-1) > 1e-9 || math.Abs(debrisB.Velocity().X()+1) > 1e-9 {
+		t.Errorf("Debris collided with each other: %v, %v", debrisA.Velocity(), debrisB.Velocity())
+	}
+	if debrisC.Velocity().X() >= 0 {
+		t.Errorf("Debris did not bounce off the planet: %v", debrisC.Velocity())
+	}
+}
+
+// TestBodiesByTagAndFilteredQuery verifies the tag enumeration and the spatial queries with a collision filter
+func TestBodiesByTagAndFilteredQuery(t *testing.T) {
+	w, bodies := createTestWorld(
+		vector.NewVector3(0, 0, 0),
+		vector.NewVector3(3, 0, 0),
+		vector.NewVector3(0, 3, 0),
+		vector.NewVector3(50, 0, 0),
+	)
+	planet := bodies[0]
+	planet.AddTag("planet")
+	for _, debris := range bodies[1:] {
+		debris.AddTag("debris")
+		debris.SetCollisionFilter(debrisFilter)
+	}
+
+	if n := len(w.GetBodiesByTag("debris")); n != 3 {
+		t.Errorf("Expected 3 debris, got %d", n)
+	}
+	if planets := w.GetBodiesByTag("planet"); len(planets) != 1 || planets[0] != planet {
+		t.Errorf("Expected the planet only, got %v", planets)
+	}
+
+	// A debris looking around only sees what it can collide with
+	nearby := w.QuerySphere(vector.Zero3(), 10, debrisFilter)
+	if len(nearby) != 1 || nearby[0] != planet {
+		t.Errorf("Expected the planet only, got %d bodies", len(nearby))
+	}
+	all := body.CollisionFilter{Category: body.AllCollisionCategories, Mask: body.AllCollisionCategories}
+	if n := len(w.QuerySphere(vector.Zero3(), 10, all)); n != 3 {
+		t.Errorf("Expected 3 bodies within 10 m, got %d", n)
+	}
+
+	planet.RemoveTag("planet")
+	if planet.HasTag("planet") || len(w.GetBodiesByTag("planet")) != 0 {
+		t.Errorf("The tag was not removed: %v", planet.Tags())
+	}
+}
+
+// TestAttachForceToTag verifies that a force attached to a tag acts on the bodies with the tag, including tagged later
+func TestAttachForceToTag(t *testing.T) {
+	w, bodies := createTestWorld(
+		vector.NewVector3(-20, 0, 0),
+		vector.NewVector3(0, 0, 0),
+		vector.NewVector3(20, 0, 0),
+	)
+	bodies[0].AddTag("spacecraft")
+
+	w.AttachForceToTag(force.NewConstantForce(vector.NewVector3(0, 2, 0)), "spacecraft")
+	w.Step(0.1)
+	bodies[1].AddTag("spacecraft")
+	for i := 0; i < 5; i++ {
+		w.Step(0.1)
+	}
+
+	for i, expected := range []bool{true, true, false} {
+		if moved := bodies[i].Velocity().Y() > 0; moved != expected {
+			t.Errorf("Body %d: moved = %v, expected %v", i, moved, expected)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/collision"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/physics/thermal"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// earthEquilibrium is the equilibrium temperature of a fast rotating body at 1 AU: (L / (16πσd²))^(1/4)
+const earthEquilibrium = 278.6
+
+// createSun creates a static Sun at the origin
+func createSun() body.Body {
+	sun := body.NewRigidBody(
+		units.NewQuantity(constants.SolarMass, units.Kilogram),
+		units.NewQuantity(6.9634e8, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Rock,
+	)
+	sun.SetStatic(true)
+	return sun
+}
+
+// TestEarthEquilibriumTemperature verifies the radiative equilibrium temperature of an Earth-like body at 1 AU
+func TestEarthEquilibriumTemperature(t *testing.T) {
+	sun := createSun()
+	earth := body.NewRigidBody(
+		units.NewQuantity(constants.EarthMass, units.Kilogram),
+		units.NewQuantity(constants.EarthRadius, units.Meter),
+		vector.NewVector3(constants.AstronomicalUnit, 0, 0),
+		vector.Zero3(),
+		material.Rock,
+	)
+
+	absorbed := thermal.AbsorbedPower(earth, sun, constants.SolarLuminosity)
+	equilibrium := thermal.EquilibriumTemperature(absorbed, earth, constants.CosmicBackgroundTemperature)
+
+	if math.Abs(equilibrium-earthEquilibrium) > 0.5 {
+		t.Errorf("Equilibrium temperature at 1 AU: expected %v K, got %v K", earthEquilibrium, equilibrium)
+	}
+
+	// At the equilibrium temperature the emitted power balances the absorbed power
+	earth.SetTemperature(units.NewQuantity(equilibrium, units.Kelvin))
+	emitted := thermal.EmittedPower(earth, constants.CosmicBackgroundTemperature)
+	if math.Abs(emitted-absorbed) > 1e-9*absorbed {
+		t.Errorf("Emitted power %v W does not balance the absorbed power %v W", emitted, absorbed)
+	}
+}
+
+// TestWorldConvergesToEquilibrium verifies that bodies at 1 AU warm up or cool down to the equilibrium temperature
+func TestWorldConvergesToEquilibrium(t *testing.T) {
+	bound := 2 * constants.AstronomicalUnit
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-bound, -bound, -bound),
+		vector.NewVector3(bound, bound, bound),
+	))
+	model := thermal.NewModel()
+	w.SetThermalModel(model)
+
+	sun := createSun()
+	w.AddBody(sun)
+	model.AddLuminousBody(sun, units.NewQuantity(constants.SolarLuminosity, units.Watt))
+
+	// A warm and a cold rock at 1 AU
+	warm := body.NewRigidBody(
+		units.NewQuantity(11310, units.Kilogram),
+		units.NewQuantity(1.0, units.Meter),
+		vector.NewVector3(constants.AstronomicalUnit, 0, 0),
+		vector.Zero3(),
+		material.Rock,
+	)
+	warm.SetStatic(true)
+	cold := body.NewRigidBody(
+		units.NewQuantity(11310, units.Kilogram),
+		units.NewQuantity(1.0, units.Meter),
+		vector.NewVector3(0, constants.AstronomicalUnit, 0),
+		vector.Zero3(),
+		material.Rock,
+	)
+	cold.SetStatic(true)
+	cold.SetTemperature(units.NewQuantity(100, units.Kelvin))
+	w.AddBody(warm)
+	w.AddBody(cold)
+
+	sunTemperature := sun.Temperature().Value()
+	for i := 0; i < 3000; i++ {
+		w.Step(1000)
+	}
+
+	for name, b := range map[string]body.Body{"warm": warm, "cold": cold} {
+		if math.Abs(b.Temperature().Value()-earthEquilibrium) > 0.5 {
+			t.Errorf("%s body did not reach the equilibrium temperature: %v K", name, b.Temperature().Value())
+		}
+	}
+
+	// Luminous bodies are not cooled
+	if sun.Temperature().Value() != sunTemperature {
+		t.Errorf("The temperature of the luminous body changed: %v K", sun.Temperature().Value())
+	}
+}
+
+// TestRadiativeCoolingStopsAtAmbient verifies that a body far from any star cools toward the ambient temperature
+// without overshooting, even with very large time steps
+func TestRadiativeCoolingStopsAtAmbient(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-10, -10, -10),
+		vector.NewVector3(10, 10, 10),
+	))
+	model := thermal.NewModel()
+	w.SetThermalModel(model)
+
+	b := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Rock,
+	)
+	w.AddBody(b)
+
+	previous := b.Temperature().Value()
+	for i := 0; i < 100; i++ {
+		w.Step(1e6)
+		temperature := b.Temperature().Value()
+		if temperature > previous || temperature < constants.CosmicBackgroundTemperature {
+			t.Fatalf("Step %d: temperature went from %v K to %v K", i, previous, temperature)
+		}
+		previous = temperature
+	}
+
+	if previous > 3 {
+		t.Errorf("Body did not cool down to the ambient temperature: %v K", previous)
+	}
+}
+
+// TestConductionBetweenTouchingBodies verifies that touching bodies exchange heat and conserve energy
+func TestConductionBetweenTouchingBodies(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-10, -10, -10),
+		vector.NewVector3(10, 10, 10),
+	))
+	w.SetThermalModel(thermal.NewModel())
+
+	// A non-radiating conductor, so that only conduction is active
+	conductor := material.NewBasicMaterial(
+		"Conductor",
+		units.NewQuantity(8960.0, units.Kilogram),
+		units.NewQuantity(386.0, units.Joule),
+		units.NewQuantity(401.0, units.Watt),
+		0.0,
+		0.0,
+		[3]float64{0.85, 0.45, 0.2},
+	)
+
+	hot := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.NewVector3(-0.49, 0, 0),
+		vector.Zero3(),
+		conductor,
+	)
+	hot.SetStatic(true)
+	hot.SetTemperature(units.NewQuantity(400, units.Kelvin))
+	cold := body.NewRigidBody(
+		units.NewQuantity(3.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.NewVector3(0.49, 0, 0),
+		vector.Zero3(),
+		conductor,
+	)
+	cold.SetStatic(true)
+	cold.SetTemperature(units.NewQuantity(300, units.Kelvin))
+	w.AddBody(hot)
+	w.AddBody(cold)
+
+	initialEnergy := 1.0*hot.Temperature().Value() + 3.0*cold.Temperature().Value()
+	w.Step(0.001)
+	if hot.Temperature().Value() >= 400 || cold.Temperature().Value() <= 300 {
+		t.Fatalf("No heat was conducted: %v K, %v K", hot.Temperature().Value(), cold.Temperature().Value())
+	}
+
+	for i := 0; i < 1000; i++ {
+		w.Step(0.1)
+	}
+
+	// Both bodies reach the common temperature (1*400 + 3*300) / 4 = 325 K
+	for name, b := range map[string]body.Body{"hot": hot, "cold": cold} {
+		if math.Abs(b.Temperature().Value()-325) > 1e-6 {
+			t.Errorf("%s body did not reach the common temperature: %v K", name, b.Temperature().Value())
+		}
+	}
+
+	finalEnergy := 1.0*hot.Temperature().Value() + 3.0*cold.Temperature().Value()
+	if math.Abs(finalEnergy-initialEnergy) > 1e-9*initialEnergy {
+		t.Errorf("Thermal energy not conserved: %v -> %v", initialEnergy, finalEnergy)
+	}
+}
+
+// TestConductionAcrossPeriodicBoundary verifies that bodies touching across a periodic boundary exchange heat
+func TestConductionAcrossPeriodicBoundary(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-10, -10, -10),
+		vector.NewVector3(10, 10, 10),
+	))
+	w.SetPeriodicity(space.Periodicity{true, false, false})
+	w.SetThermalModel(thermal.NewModel())
+
+	// 0.98 m apart through the boundary, 19.02 m apart inside the box
+	hot := body.NewRigidBody(units.NewQuantity(1.0, units.Kilogram), units.NewQuantity(0.5, units.Meter), vector.NewVector3(-9.51, 0, 0), vector.Zero3(), material.Iron)
+	hot.SetStatic(true)
+	hot.SetTemperature(units.NewQuantity(400, units.Kelvin))
+	cold := body.NewRigidBody(units.NewQuantity(1.0, units.Kilogram), units.NewQuantity(0.5, units.Meter), vector.NewVector3(9.51, 0, 0), vector.Zero3(), material.Iron)
+	cold.SetStatic(true)
+	cold.SetTemperature(units.NewQuantity(300, units.Kelvin))
+	w.AddBody(hot)
+	w.AddBody(cold)
+
+	w.Step(0.001)
+	if cold.Temperature().Value() <= 300 {
+		t.Errorf("No heat was conducted across the boundary: %v K, %v K", hot.Temperature().Value(), cold.Temperature().Value())
+	}
+}
+
+// TestImpactHeatingConservesEnergy verifies that the kinetic energy lost in an inelastic collision becomes heat
+func TestImpactHeatingConservesEnergy(t *testing.T) {
+	a := body.NewRigidBody(
+		units.NewQuantity(2.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.NewVector3(-0.45, 0, 0),
+		vector.NewVector3(30, 0, 0),
+		material.Rock,
+	)
+	b := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(0.5, units.Meter),
+		vector.NewVector3(0.45, 0, 0),
+		vector.NewVector3(-10, 0, 0),
+		material.Iron,
+	)
+
+	energy := func() (kinetic, thermal float64) {
+		for _, current := range []body.Body{a, b} {
+			mass := current.Mass().Value()
+			kinetic += 0.5 * mass * current.Velocity().LengthSquared()
+			thermal += mass * current.Material().SpecificHeat().Value() * current.Temperature().Value()
+		}
+		return kinetic, thermal
+	}
+
+	initialKinetic, initialThermal := energy()
+	info := collision.NewSphereCollider().CheckCollision(a, b)
+	if !info.HasCollided {
+		t.Fatal("The bodies should collide")
+	}
+	collision.NewImpulseResolver(0.5).ResolveCollision(info)
+	finalKinetic, finalThermal := energy()
+
+	if finalKinetic >= initialKinetic {
+		t.Fatalf("The collision did not dissipate kinetic energy: %v J -> %v J", initialKinetic, finalKinetic)
+	}
+	if a.Temperature().Value() <= 293.15 || b.Temperature().Value() <= 293.15 {
+		t.Errorf("Both bodies should be heated: %v K, %v K", a.Temperature().Value(), b.Temperature().Value())
+	}
+
+	initialEnergy := initialKinetic + initialThermal
+	finalEnergy := finalKinetic + finalThermal
+	if math.Abs(finalEnergy-initialEnergy) > 1e-9*initialEnergy {
+		t.Errorf("Energy not conserved: %v J -> %v J", initialEnergy, finalEnergy)
+	}
+
+	// The heat is split by heat capacity, so both bodies warm up by the same amount
+	if math.Abs(a.Temperature().Value()-b.Temperature().Value()) > 1e-9 {
+		t.Errorf("Bodies heated unevenly: %v K, %v K", a.Temperature().Value(), b.Temperature().Value())
+	}
+
+	// A static body takes no heat: all of it goes to the moving body
+	wall := body.NewRigidBody(units.NewQuantity(1.0, units.Kilogram), units.NewQuantity(0.5, units.Meter), vector.NewVector3(1.4, 0, 0), vector.Zero3(), material.Iron)
+	wall.SetStatic(true)
+	before := a.Temperature().Value()
+	a.SetPosition(vector.NewVector3(0.5, 0, 0))
+	a.SetVelocity(vector.NewVector3(30, 0, 0))
+	info = collision.NewSphereCollider().CheckCollision(a, wall)
+	if !info.HasCollided {
+		t.Fatal("The body should hit the wall")
+	}
+	collision.NewImpulseResolver(0.5).ResolveCollision(info)
+	lost := 0.5 * 2.0 * (30*30 - a.Velocity().LengthSquared())
+	heated := 2.0 * a.Material().SpecificHeat().Value() * (a.Temperature().Value() - before)
+	if wall.Temperature().Value() != 293.15 || math.Abs(heated-lost) > 1e-9*lost {
+		t.Errorf("Heat %v J of the moving body for %v J lost, wall at %v K", heated, lost, wall.Temperature().Value())
+	}
+}
+
+// TestIceMeltsIntoWater verifies that ice absorbs the latent heat of fusion at the melting point before turning into water
+func TestIceMeltsIntoWater(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-10, -10, -10),
+		vector.NewVector3(10, 10, 10),
+	))
+	model := thermal.NewModel()
+	w.SetThermalModel(model)
+
+	ice := body.NewRigidBody(
+		units.NewQuantity(1.0, units.Kilogram),
+		units.NewQuantity(0.1, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Ice,
+	)
+	ice.SetStatic(true)
+	ice.SetTemperature(units.NewQuantity(263.15, units.Kelvin))
+	w.AddBody(ice)
+
+	// Warm the ice to the melting point and melt half of it
+	latentHeat := material.Ice.LatentHeatOfFusion().Value()
+	ice.AddHeat(units.NewQuantity(10*material.Ice.SpecificHeat().Value()+0.5*latentHeat, units.Joule))
+	w.Step(0.001)
+
+	if ice.Material() != material.Ice || len(w.GetPhaseChanges()) != 0 {
+		t.Fatalf("The ice melted before absorbing the latent heat: %s", ice.Material().Name())
+	}
+	if math.Abs(ice.Temperature().Value()-273.15) > 1e-6 {
+		t.Errorf("The melting ice should stay at the melting point: %v K", ice.Temperature().Value())
+	}
+	if math.Abs(model.LatentHeat(ice.ID()).Value()-0.5*latentHeat) > 1e-3*latentHeat {
+		t.Errorf("Stored latent heat %v J, expected %v J", model.LatentHeat(ice.ID()).Value(), 0.5*latentHeat)
+	}
+
+	// Melt the rest and warm the water by 10 K
+	ice.AddHeat(units.NewQuantity(0.5*latentHeat+10*material.Water.SpecificHeat().Value(), units.Joule))
+	w.Step(0.001)
+
+	changes := w.GetPhaseChanges()
+	if len(changes) != 1 || changes[0].Transition != thermal.Melting || changes[0].To != material.Water {
+		t.Fatalf("Expected a single melting into water, got %v", changes)
+	}
+	if ice.Material() != material.Water {
+		t.Fatalf("The ice did not turn into water: %s", ice.Material().Name())
+	}
+	if math.Abs(ice.Temperature().Value()-283.15) > 1e-3 {
+		t.Errorf("Water temperature %v K, expected 283.15 K", ice.Temperature().Value())
+	}
+
+	// The volume follows the density of the liquid
+	expectedRadius := 0.1 * math.Cbrt(917.0/997.0)
+	if math.Abs(ice.Radius().Value()-expectedRadius) > 1e-12 {
+		t.Errorf("Water radius %v m, expected %v m", ice.Radius().Value(), expectedRadius)
+	}
+}
+
+// TestVaporizationRemovesMass verifies that heat beyond the boiling point vaporizes mass
+// and that fully vaporized bodies are removed from the world
+func TestVaporizationRemovesMass(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(
+		vector.NewVector3(-10, -10, -10),
+		vector.NewVector3(10, 10, 10),
+	))
+	w.SetThermalModel(thermal.NewModel())
+
+	water := body.NewRigidBody(
+		units.NewQuantity(2.0, units.Kilogram),
+		units.NewQuantity(0.1, units.Meter),
+		vector.Zero3(),
+		vector.Zero3(),
+		material.Water,
+	)
+	water.SetStatic(true)
+	water.SetTemperature(units.NewQuantity(373.15, units.Kelvin))
+	w.AddBody(water)
+
+	// Vaporize half of the water
+	latentHeat := material.Water.LatentHeatOfVaporization().Value()
+	water.AddHeat(units.NewQuantity(latentHeat, units.Joule))
+	w.Step(0.001)
+
+	changes := w.GetPhaseChanges()
+	if len(changes) != 1 || changes[0].Transition != thermal.Vaporization {
+		t.Fatalf("Expected a single vaporization, got %v", changes)
+	}
+	if math.Abs(water.Mass().Value()-1.0) > 1e-6 || math.Abs(changes[0].Mass-1.0) > 1e-6 {
+		t.Errorf("Remaining mass %v kg, vaporized %v kg, expected 1 kg each", water.Mass().Value(), changes[0].Mass)
+	}
+	if math.Abs(water.Radius().Value()-0.1*math.Cbrt(0.5)) > 1e-6 {
+		t.Errorf("Radius %v m, expected %v m", water.Radius().Value(), 0.1*math.Cbrt(0.5))
+	}
+	if water.Temperature().Value() != 373.15 {
+		t.Errorf("The boiling water should stay at the boiling point: %v K", water.Temperature().Value())
+	}
+
+	// Vaporize the rest
+	water.AddHeat(units.NewQuantity(2*latentHeat, units.Joule))
+	w.Step(0.001)
+
+	changes = w.GetPhaseChanges()
+	if len(changes) != 1 || changes[0].RemainingMass != 0 {
+		t.Fatalf("Expected the body to fully vaporize, got %v", changes)
+	}
+	if w.GetBodyCount() != 0 {
+		t.Errorf("The vaporized body was not removed from the world")
+	}
+}
+
+// centerQuery is a spatial structure that only returns the bodies whose center is inside the queried sphere
+type centerQuery struct {
+	space.SpatialStructure
+	bodies []body.Body
+}
+
+// QuerySphere returns the bodies whose center is inside the sphere
+func (q centerQuery) QuerySphere(center vector.Vector3, radius float64) []body.Body {
+	result := make([]body.Body, 0)
+	for _, b := range q.bodies {
+		if b.Position().Sub(center).Length() <= radius {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// serialTasks runs the tasks as they are submitted
+type serialTasks struct{}
+
+// Submit runs a task
+func (serialTasks) Submit(task func()) { task() }
+
+// Wait returns at once, as the tasks are already done
+func (serialTasks) Wait() {}
+
+// TestConductionWithLargerNeighbor verifies that a small body exchanges heat with a much larger body it touches
+func TestConductionWithLargerNeighbor(t *testing.T) {
+	large := body.NewRigidBody(units.NewQuantity(1000.0, units.Kilogram), units.NewQuantity(5, units.Meter), vector.NewVector3(5.09, 0, 0), vector.Zero3(), material.Iron)
+	large.SetTemperature(units.NewQuantity(400, units.Kelvin))
+
+	// The pair is handled from the side of the small body, whose neighborhood does not contain the large center
+	var small body.Body
+	for small == nil || small.ID().String() >= large.ID().String() {
+		small = body.NewRigidBody(units.NewQuantity(0.01, units.Kilogram), units.NewQuantity(0.1, units.Meter), vector.Zero3(), vector.Zero3(), material.Iron)
+	}
+	small.SetTemperature(units.NewQuantity(300, units.Kelvin))
+
+	bodies := []body.Body{small, large}
+	thermal.NewModel().Step(bodies, centerQuery{bodies: bodies}, nil, 0.001, serialTasks{})
+	if small.Temperature().Value() <= 300 {
+		t.Errorf("No heat was conducted from the larger body: %v K, %v K", small.Temperature().Value(), large.Temperature().Value())
+	}
+}
+
+// TestLuminosityInOtherUnits verifies that a luminosity given in other units than watts is converted
+func TestLuminosityInOtherUnits(t *testing.T) {
+	kilowatt := units.NewBaseUnit(units.Power, "kilowatt", "kW", 1000, 0)
+	model := thermal.NewModel()
+	lamp := createSun()
+	model.AddLuminousBody(lamp, units.NewQuantity(2, kilowatt))
+
+	if luminosity := model.Luminosity(lamp.ID()); luminosity.Value() != 2000 || luminosity.Unit() != units.Watt {
+		t.Errorf("Luminosity %v, expected 2000 W", luminosity)
+	}
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/core/vector"
+	"github.com/alexanderi96/go-space-engine/physics/body"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/space"
+	"github.com/alexanderi96/go-space-engine/simulation/trajectory"
+	"github.com/alexanderi96/go-space-engine/simulation/world"
+)
+
+// TestTrajectoryRecorder verifies the CSV, binary and in-memory recordings of two bodies in uniform motion
+func TestTrajectoryRecorder(t *testing.T) {
+	w := world.NewPhysicalWorld(space.NewAABB(vector.NewVector3(-1e5, -1e5, -1e5), vector.NewVector3(1e5, 1e5, 1e5)))
+	probe := body.NewRigidBody(units.NewQuantity(1, units.Kilogram), units.NewQuantity(1, units.Meter), vector.Zero3(), vector.NewVector3(100, 0, 0), material.Iron)
+	probe.SetTemperature(units.NewQuantity(400, units.Kelvin))
+	w.AddBody(probe)
+	rock := body.NewRigidBody(units.NewQuantity(1, units.Kilogram), units.NewQuantity(1, units.Meter), vector.NewVector3(0, 1000, 0), vector.NewVector3(0, -10, 0), material.Rock)
+	w.AddBody(rock)
+
+	// All the bodies every 5 steps, from the initial state
+	recorder := trajectory.NewRecorder(5)
+	var csv, binary bytes.Buffer
+	csvWriter := trajectory.NewCSVWriter(&csv)
+	kilometers := trajectory.Units{Time: units.Minute, Length: units.Kilometer, Velocity: units.KilometerPerSecond, Temperature: units.Celsius}
+	if err := csvWriter.SetUnits(kilometers); err != nil {
+		t.Fatal(err)
+	}
+	if err := csvWriter.SetUnits(trajectory.Units{Time: units.Meter}); err == nil {
+		t.Error("Expected an error for a length as the unit of time")
+	}
+	binaryWriter := trajectory.NewBinaryWriter(&binary, 3)
+	ring := trajectory.NewRingBuffer(4)
+	recorder.AddSink(csvWriter)
+	recorder.AddSink(binaryWriter)
+	recorder.AddSink(ring)
+	recorder.Record(w)
+	w.AddObserver(recorder)
+
+	// Only the rock every step
+	selected := trajectory.NewRecorder(1)
+	selected.Select(rock)
+	rockRing := trajectory.NewRingBuffer(100)
+	selected.AddSink(rockRing)
+	w.AddObserver(selected)
+
+	for i := 0; i < 60; i++ {
+		w.Step(1)
+	}
+	if err := binaryWriter.Close(); err != nil || recorder.Err() != nil {
+		t.Fatal(err, recorder.Err())
+	}
+
+	// CSV in the chosen units
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if lines[0] != "time [min],body,x [km],y [km],z [km],vx [km/s],vy [km/s],vz [km/s],temperature [°C]" {
+		t.Errorf("Unexpected CSV header %q", lines[0])
+	}
+	if len(lines) != 1+13*2 {
+		t.Errorf("Expected %d CSV rows, got %d", 13*2, len(lines)-1)
+	}
+	expected := "1," + probe.ID().String() + ",6,0,0,0.1,0,0,126.85"
+	if !strings.Contains(csv.String(), expected) {
+		t.Errorf("Missing CSV row %q", expected)
+	}
+
+	// Binary file read back in SI units, with a partial last chunk
+	reader, err := trajectory.NewBinaryReader(&binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if symbols := reader.Units(); symbols != (trajectory.UnitSymbols{Time: "s", Length: "m", Velocity: "m/s", Temperature: "K"}) {
+		t.Errorf("Unexpected units %+v", symbols)
+	}
+	snapshots, err := reader.ReadAll()
+	if err != nil || len(snapshots) != 13 {
+		t.Fatalf("Read %d snapshots: %v", len(snapshots), err)
+	}
+	for i, snapshot := range snapshots {
+		state, ok := snapshot.State(probe.ID())
+		if snapshot.Time != float64(5*i) || !ok || !vectorsAlmostEqual(state.Position, vector.NewVector3(100*snapshot.Time, 0, 0), 1e-9) ||
+			!vectorsAlmostEqual(state.Velocity, vector.NewVector3(100, 0, 0), 1e-9) || state.Temperature != 400 {
+			t.Errorf("Snapshot %d at %v s: %+v", i, snapshot.Time, state)
+		}
+	}
+	if _, err := trajectory.NewBinaryReader(strings.NewReader("not a trajectory")); err == nil {
+		t.Error("Expected an error for a file that is not a trajectory")
+	}
+
+	// The ring buffer keeps the last 4 snapshots, interpolated between them
+	if ring.Len() != 4 || ring.Snapshots()[0].Time != 45 {
+		t.Errorf("Ring buffer with %d snapshots from %v s", ring.Len(), ring.Snapshots()[0].Time)
+	}
+	if _, ok := ring.At(40); ok {
+		t.Error("Expected no state before the oldest snapshot of the ring buffer")
+	}
+	if state, ok := ring.StateAt(rock.ID(), 52.5); !ok || math.Abs(state.Position.Y()-(1000-525)) > 1e-9 {
+		t.Errorf("Rock at %+v at 52.5 s", state)
+	}
+	if snapshots := ring.Range(50, 60); len(snapshots) != 3 {
+		t.Errorf("Expected 3 snapshots between 50 and 60 s, got %d", len(snapshots))
+	}
+
+	// The selection only records the rock
+	if rockRing.Len() != 60 || len(rockRing.Snapshots()[0].States) != 1 || rockRing.Snapshots()[0].States[0].ID != rock.ID() {
+		t.Errorf("Unexpected selected recording of %d snapshots", rockRing.Len())
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/alexanderi96/go-space-engine/core/constants"
+	"github.com/alexanderi96/go-space-engine/core/units"
+	"github.com/alexanderi96/go-space-engine/physics/material"
+	"github.com/alexanderi96/go-space-engine/physics/orbit"
+)
+
+// TestLambert verifies that the Lambert solver recovers the velocities of known Keplerian arcs
+func TestLambert(t *testing.T) {
+	mu := constants.G * constants.SolarMass
+	cases := map[string]struct {
+		elements orbit.Elements
+		tof      float64
+	}{
+		"Short way":  {orbit.Elements{SemiMajorAxis: 1.2e11, Eccentricity: 0.3, Inclination: 0.5, LongitudeOfAscendingNode: 1, TrueAnomaly: 0.5}, 0.2},
+		"Long way":   {orbit.Elements{SemiMajorAxis: 1.2e11, Eccentricity: 0.3, Inclination: 2.5, ArgumentOfPeriapsis: 1}, 0.7},
+		"Hyperbolic": {orbit.Elements{SemiMajorAxis: -1e11, Eccentricity: 1.8, Inclination: 0.2, TrueAnomaly: -0.5}, 0.1},
+	}
+
+	for name, c := range cases {
+		r1, v1 := c.elements.StateVectors(mu)
+		tof := c.tof * 2 * math.Pi * math.Sqrt(math.Pow(math.Abs(c.elements.SemiMajorAxis), 3)/mu)
+		r2, v2 := orbit.Propagate(r1, v1, mu, tof)
+
+		gotV1, gotV2, err := orbit.Lambert(r1, r2, tof, mu, r1.Cross(v1))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !vectorsAlmostEqual(gotV1, v1, 1e-6*v1.Length()) || !vectorsAlmostEqual(gotV2, v2, 1e-6*v2.Length()) {
+			t.Errorf("%s: velocities %v, %v, expected %v, %v", name, gotV1, gotV2, v1, v2)
+		}
+	}
+
+	r1, _ := orbit.Circular(1e11, 0, 0, 0).StateVectors(mu)
+	if _, _, err := orbit.Lambert(r1, r1.Scale(2), -1, mu, nil); err == nil {
+		t.Errorf("A negative time of flight was accepted")
+	}
+}
+
+// TestTransferPorkchop verifies that the best transfer between two circular orbits approaches the Hohmann transfer
+func TestTransferPorkchop(t *testing.T) {
+	sun := createSun()
+	inner, outer := constants.AstronomicalUnit, 1.524*constants.AstronomicalUnit
+	earth := orbit.NewBodyOnOrbit(sun, orbit.Circular(inner, 0, 0, 0), units.NewQuantity(constants.EarthMass, units.Kilogram), units.NewQuantity(6.371e6, units.Meter), material.Rock)
+	mars := orbit.NewBodyOnOrbit(sun, orbit.Circular(outer, 0, 0, 2), units.NewQuantity(6.417e23, units.Kilogram), units.NewQuantity(3.39e6, units.Meter), material.Rock)
+
+	day := 86400.0
+	var departures, arrivals []float64
+	for d := 0.0; d < 800; d += 5 {
+		departures = append(departures, d*day)
+	}
+	for a := 0.0; a < 1100; a += 5 {
+		arrivals = append(arrivals, a*day)
+	}
+	porkchop := orbit.NewPorkchop(sun, earth, mars, departures, arrivals)
+
+	// Arrivals before the departures have no transfer
+	if !math.IsNaN(porkchop.DeltaV[10][0]) || porkchop.Transfers[10][0] != nil {
+		t.Errorf("Transfer found arriving before the departure")
+	}
+
+	mu := constants.G * constants.SolarMass
+	hohmann := math.Sqrt(mu/inner)*(math.Sqrt(2*outer/(inner+outer))-1) + math.Sqrt(mu/outer)*(1-math.Sqrt(2*inner/(inner+outer)))
+	best := porkchop.Best()
+	if best == nil {
+		t.Fatalf("No transfer found")
+	}
+	if best.TotalDeltaV() < 0.99*hohmann || best.TotalDeltaV() > 1.03*hohmann {
+		t.Errorf("Best transfer %v m/s, expected about the Hohmann transfer %v m/s", best.TotalDeltaV(), hohmann)
+	}
+	if tof := best.TimeOfFlight() / day; math.Abs(tof-259) > 30 {
+		t.Errorf("Best time of flight %v days, expected about 259 days", tof)
+	}
+
+	// The transfer reaches Mars
+	transfer, err := orbit.PlanTransfer(sun, earth, mars, best.Departure, best.Arrival)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, _ := orbit.Propagate(transfer.DeparturePosition, transfer.DepartureVelocity, mu, transfer.TimeOfFlight())
+	if miss := end.Sub(transfer.ArrivalPosition).Length(); miss > 1e3 {
+		t.Errorf("The transfer misses Mars by %v m", miss)
+	}
+
+	var buffer bytes.Buffer
+	if err := porkchop.WriteCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if !strings.HasPrefix(lines[0], "departure_s,arrival_s") || len(lines) < 2 || strings.Count(lines[1], ",") != 5 {
+		t.Errorf("Unexpected CSV output: %v", lines[:2])
+	}
+}
+
+// TestTransferAlongOwnOrbit verifies that the transfer of a heavy body to its own later position follows its orbit,
+// as the body and the transfer orbit are propagated with the same gravitational parameter
+func TestTransferAlongOwnOrbit(t *testing.T) {
+	sun := createSun()
+	companion := orbit.NewBodyOnOrbit(sun, orbit.Circular(constants.AstronomicalUnit, 0.1, 0, 0), units.NewQuantity(0.1*constants.SolarMass, units.Kilogram), units.NewQuantity(7e7, units.Meter), material.Rock)
+
+	transfer, err := orbit.PlanTransfer(sun, companion, companion, 0, 100*86400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deltaV := transfer.TotalDeltaV(); deltaV > 1e-6*transfer.DepartureVelocity.Length() {
+		t.Errorf("Delta-v %v m/s to follow the orbit of the body", deltaV)
+	}
+}